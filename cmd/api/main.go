@@ -2,24 +2,42 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
+	"math/rand"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
+	"github.com/mivihan/Pull_Request_service/internal/audit"
+	"github.com/mivihan/Pull_Request_service/internal/auth"
+	"github.com/mivihan/Pull_Request_service/internal/authz"
 	"github.com/mivihan/Pull_Request_service/internal/config"
+	"github.com/mivihan/Pull_Request_service/internal/domain"
 	"github.com/mivihan/Pull_Request_service/internal/handler"
+	"github.com/mivihan/Pull_Request_service/internal/notify"
+	"github.com/mivihan/Pull_Request_service/internal/ratelimit"
 	"github.com/mivihan/Pull_Request_service/internal/repository"
+	"github.com/mivihan/Pull_Request_service/internal/scheduler"
 	"github.com/mivihan/Pull_Request_service/internal/service"
+	"github.com/mivihan/Pull_Request_service/internal/stream"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
 	"github.com/mivihan/Pull_Request_service/pkg/database"
 )
 
 func main() {
-	if err := run(); err != nil {
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = runMigrate(os.Args[2:])
+	} else {
+		err = run()
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -41,13 +59,214 @@ func run() error {
 	defer pool.Close()
 	logger.Info("connected to database")
 
+	if err := checkSchema(ctx, cfg, pool, logger); err != nil {
+		return fmt.Errorf("check database schema: %w", err)
+	}
+
 	repos := repository.NewRepositories(pool)
 
-	teamService := service.NewTeamService(repos)
+	seed := cfg.SelectionSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	selector, err := service.NewSelector(cfg.SelectionMode, rand.NewSource(seed), repos)
+	if err != nil {
+		return fmt.Errorf("create reviewer selector: %w", err)
+	}
+
+	teamService := service.NewTeamService(repos, service.WithTeamSelector(selector))
 	userService := service.NewUserService(repos)
-	prService := service.NewPRService(repos)
+	prService := service.NewPRService(repos,
+		service.WithDeclineQuota(cfg.DeclineQuota, cfg.DeclinePeriod),
+		service.WithSelector(selector),
+		service.WithStaleReassignmentCap(cfg.StaleMaxReassignments),
+	)
+	constraintService := service.NewConstraintService(repos)
+	statsService := service.NewStatsService(repos)
+
+	notifications := notify.NewDispatcher()
+	notifications.Register(domain.ChannelLog, notify.NewLogNotifier(logger))
+	slack := notify.SlackConfig{
+		WebhookURL: cfg.SlackWebhookURL,
+		Token:      cfg.SlackBotToken,
+		APIURL:     cfg.SlackAPIURL,
+		Channel:    cfg.SlackChannel,
+		MaxRetries: cfg.SlackMaxRetries,
+	}
+	if slack.Enabled() {
+		notifications.Register(domain.ChannelSlack, notify.NewSlackNotifier(slack, repos.Notification))
+	}
+	smtp := notify.SMTPConfig{
+		Host:        cfg.SMTPHost,
+		Port:        cfg.SMTPPort,
+		Username:    cfg.SMTPUsername,
+		Password:    cfg.SMTPPassword,
+		From:        cfg.SMTPFrom,
+		StartTLS:    cfg.SMTPStartTLS,
+		TemplateDir: cfg.SMTPTemplateDir,
+	}
+	if smtp.Enabled() {
+		email, err := notify.NewEmailNotifier(smtp)
+		if err != nil {
+			return fmt.Errorf("create email notifier: %w", err)
+		}
+		notifications.Register(domain.ChannelEmail, email)
+	}
+	reminderService := service.NewReminderService(repos, notifications)
+	notificationService := service.NewNotificationService(repos, notifications)
+
+	broker := stream.NewBroker(repos.Event)
+
+	// API calls are audited and role-checked; the scheduled jobs below use the services
+	// directly, acting on behalf of the service.
+	recorder := audit.NewRecorder(repos)
+	authorizer := authz.NewAuthorizer(repos)
+	roleService := service.NewRoleService(repos)
+	apiKeyService := service.NewAPIKeyService(repos, service.WithBootstrapKey(cfg.AuthBootstrapKey))
+	auditService := service.NewAuditService(repos)
+	tenantService := service.NewTenantService(repos)
+	authConfig := handler.AuthConfig{
+		Enabled: cfg.AuthEnabled,
+		APIKeys: authz.NewAPIKeyService(audit.NewAPIKeyService(apiKeyService, recorder), authorizer),
+	}
+	if cfg.JWTJWKSURL != "" {
+		authConfig.Tokens = auth.NewJWTVerifier(auth.JWTConfig{
+			Issuers:     cfg.JWTIssuers,
+			Audience:    cfg.JWTAudience,
+			JWKSURL:     cfg.JWTJWKSURL,
+			UserClaim:   cfg.JWTUserClaim,
+			RolesClaim:  cfg.JWTRolesClaim,
+			TenantClaim: cfg.JWTTenantClaim,
+			CacheTTL:    cfg.JWTJWKSCacheTTL,
+		})
+		authConfig.AdminRole = cfg.JWTAdminRole
+	}
+	if !cfg.AuthEnabled {
+		logger.Warn("AUTH_ENABLED is off, the API is open to anyone who can reach it")
+	}
 
-	router := handler.NewRouter(teamService, userService, prService, logger)
+	// /ws/reviews sits outside the API key check and authenticates its own tokens, so
+	// bare user IDs are only accepted while the whole API is open and there is no SSO:
+	// a token failing verification must not fall through to them.
+	var wsAuthenticators auth.Chain
+	if authConfig.Tokens != nil {
+		wsAuthenticators = append(wsAuthenticators, authConfig.Tokens)
+	}
+	if cfg.WSAuthSecret != "" {
+		wsAuthenticators = append(wsAuthenticators, auth.NewHMACAuthenticator(cfg.WSAuthSecret))
+	} else if !cfg.AuthEnabled && authConfig.Tokens == nil {
+		logger.Warn("WS_AUTH_SECRET is not set, WebSocket clients authenticate with a bare user ID")
+		wsAuthenticators = append(wsAuthenticators, auth.InsecureAuthenticator{})
+	}
+	if len(wsAuthenticators) == 0 {
+		return errors.New("AUTH_ENABLED requires WS_AUTH_SECRET or JWT_JWKS_URL to authenticate /ws/reviews")
+	}
+	var authenticator auth.Authenticator = wsAuthenticators
+
+	rateLimitPolicy, err := ratelimit.ParsePolicy(cfg.RateLimitDefault, cfg.RateLimitRoutes)
+	if err != nil {
+		return fmt.Errorf("parse rate limits: %w", err)
+	}
+	addressPolicy, err := ratelimit.ParsePolicy(cfg.RateLimitAddress, nil)
+	if err != nil {
+		return fmt.Errorf("parse address rate limit: %w", err)
+	}
+	rateLimitConfig := handler.RateLimitConfig{Policy: rateLimitPolicy, AddressPolicy: addressPolicy}
+	if cfg.RateLimitShared {
+		rateLimitConfig.Limiter = ratelimit.NewSharedLimiter(repos.RateLimit)
+	} else {
+		rateLimitConfig.Limiter = ratelimit.NewMemoryLimiter()
+	}
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	// Jobs over tenant data run once per tenant; the event stream and rate limit
+	// buckets span all of them.
+	jobs := scheduler.New(logger)
+	jobs.Add(scheduler.Job{
+		Name:     "stale_reviews",
+		Interval: cfg.StaleCheckInterval,
+		Run: func(ctx context.Context) error {
+			return tenantService.ForEach(ctx, func(ctx context.Context) error {
+				count, err := prService.ReassignStaleReviews(ctx)
+				if count > 0 {
+					logger.Info("stale reviews reassigned", "tenant", tenant.ID(ctx), "count", count)
+				}
+				return err
+			})
+		},
+	})
+	jobs.Add(scheduler.Job{
+		Name:     "review_reminders",
+		Interval: cfg.ReminderCheckInterval,
+		Run: func(ctx context.Context) error {
+			return tenantService.ForEach(ctx, func(ctx context.Context) error {
+				count, err := reminderService.SendReminders(ctx)
+				if count > 0 {
+					logger.Info("review reminders sent", "tenant", tenant.ID(ctx), "count", count)
+				}
+				return err
+			})
+		},
+	})
+	jobs.Add(scheduler.Job{
+		Name:     "event_notifications",
+		Interval: cfg.NotifyCheckInterval,
+		Run: func(ctx context.Context) error {
+			return tenantService.ForEach(ctx, func(ctx context.Context) error {
+				_, err := notificationService.DeliverEvents(ctx)
+				return err
+			})
+		},
+	})
+	jobs.Add(scheduler.Job{
+		Name:     "event_stream",
+		Interval: cfg.StreamPollInterval,
+		Run:      broker.Poll,
+	})
+	if cfg.RateLimitShared && (!rateLimitPolicy.Empty() || !addressPolicy.Empty()) {
+		// A bucket idle for longer than it takes to refill is full, as a new one would be.
+		idle := max(rateLimitPolicy.RefillTime(), addressPolicy.RefillTime())
+		jobs.Add(scheduler.Job{
+			Name:     "rate_limit_cleanup",
+			Interval: max(idle, time.Minute),
+			Run: func(ctx context.Context) error {
+				_, err := repos.RateLimit.DeleteIdle(ctx, idle)
+				return err
+			},
+		})
+	}
+	if cfg.IdempotencyTTL > 0 {
+		jobs.Add(scheduler.Job{
+			Name:     "idempotency_cleanup",
+			Interval: min(cfg.IdempotencyTTL, time.Hour),
+			Run: func(ctx context.Context) error {
+				_, err := repos.Idempotency.DeleteExpired(ctx)
+				return err
+			},
+		})
+	}
+	jobs.Start(jobsCtx)
+
+	router := handler.NewRouter(
+		authz.NewTeamService(audit.NewTeamService(teamService, recorder), authorizer),
+		authz.NewUserService(audit.NewUserService(userService, recorder), authorizer),
+		authz.NewPRService(audit.NewPRService(prService, recorder), authorizer),
+		authz.NewConstraintService(audit.NewConstraintService(constraintService, recorder), authorizer),
+		statsService,
+		authz.NewReminderService(audit.NewReminderService(reminderService, recorder), authorizer),
+		authz.NewNotificationService(audit.NewNotificationService(notificationService, recorder), authorizer),
+		authz.NewRoleService(audit.NewRoleService(roleService, recorder), authorizer),
+		authz.NewAuditService(auditService, authorizer),
+		tenantService,
+		broker,
+		authenticator,
+		authConfig,
+		rateLimitConfig,
+		handler.IdempotencyConfig{Store: repos.Idempotency, TTL: cfg.IdempotencyTTL},
+		logger,
+	)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
@@ -56,6 +275,8 @@ func run() error {
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Open event streams would otherwise keep Shutdown waiting until its deadline.
+	srv.RegisterOnShutdown(broker.Close)
 
 	serverErrors := make(chan error, 1)
 	go func() {
@@ -78,6 +299,9 @@ func run() error {
 			return fmt.Errorf("graceful shutdown failed: %w", err)
 		}
 
+		stopJobs()
+		jobs.Wait()
+
 		logger.Info("server stopped")
 	}
 
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/config"
+	"github.com/mivihan/Pull_Request_service/internal/migrate"
+	"github.com/mivihan/Pull_Request_service/migrations"
+	"github.com/mivihan/Pull_Request_service/pkg/database"
+)
+
+const migrateUsage = "usage: api migrate up | down [steps] | status"
+
+// checkSchema makes sure the service does not start against a schema older than the
+// one it was built for: with MigrateOnStart the pending migrations are applied,
+// otherwise the service refuses to start.
+func checkSchema(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool, logger *slog.Logger) error {
+	migrator, err := migrate.New(pool, migrations.FS, logger)
+	if err != nil {
+		return err
+	}
+
+	if cfg.MigrateOnStart {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		logger.Info("database schema is up to date", "applied", applied)
+		return nil
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Dirty || len(status.Pending) > 0 {
+		return fmt.Errorf("database schema is at version %d with %d migrations pending: run `api migrate up` or set MIGRATE_ON_START=true",
+			status.Version, len(status.Pending))
+	}
+	return nil
+}
+
+// runMigrate implements the migrate subcommand.
+func runMigrate(args []string) error {
+	steps := 1
+	switch {
+	case len(args) == 2 && args[0] == "down":
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("steps must be a positive number, got %q", args[1])
+		}
+		steps = n
+	case len(args) == 1 && (args[0] == "up" || args[0] == "down" || args[0] == "status"):
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	logger := setupLogger(cfg.LogLevel)
+
+	ctx := context.Background()
+	pool, err := database.NewPool(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	migrator, err := migrate.New(pool, migrations.FS, logger)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		logger.Info("migrated up", "applied", applied)
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		logger.Info("migrated down", "reverted", reverted)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(status)
+	}
+	return nil
+}
+
+func printStatus(status *migrate.Status) {
+	dirty := ""
+	if status.Dirty {
+		dirty = " (dirty)"
+	}
+	fmt.Printf("version: %d%s\n\n", status.Version, dirty)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, m := range status.Applied {
+		fmt.Fprintf(w, "%06d\t%s\tapplied\n", m.Version, m.Name)
+	}
+	for _, m := range status.Pending {
+		fmt.Fprintf(w, "%06d\t%s\tpending\n", m.Version, m.Name)
+	}
+	w.Flush()
+}
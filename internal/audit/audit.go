@@ -0,0 +1,75 @@
+// Package audit records mutating service calls in the audit log. Its decorators run the
+// call and write its entry in one transaction, so an entry exists exactly when the
+// change was committed. Calls that fail leave no entry.
+package audit
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mivihan/Pull_Request_service/internal/auth"
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+)
+
+// Source describes the request a change came from.
+type Source struct {
+	RequestID string
+	IP        string
+}
+
+type sourceKey struct{}
+
+func WithSource(ctx context.Context, src Source) context.Context {
+	return context.WithValue(ctx, sourceKey{}, src)
+}
+
+// SourceFrom returns the zero Source for calls made outside of a request.
+func SourceFrom(ctx context.Context) Source {
+	src, _ := ctx.Value(sourceKey{}).(Source)
+	return src
+}
+
+type Recorder struct {
+	repos *repository.Repositories
+}
+
+func NewRecorder(repos *repository.Repositories) *Recorder {
+	return &Recorder{repos: repos}
+}
+
+// errUnchanged tells record that the change turned out to be a no-op, which is not
+// recorded.
+var errUnchanged = errors.New("nothing changed")
+
+// record runs change inside a transaction and appends the entry it filled in. change
+// receives the transaction context and should read the previous state through it.
+func (r *Recorder) record(ctx context.Context, action domain.AuditAction, targetType domain.AuditTarget, targetID string, change func(ctx context.Context, entry *domain.AuditEntry) error) error {
+	return r.repos.WithTx(ctx, func(txCtx context.Context) error {
+		entry := domain.NewAuditEntry(action, targetType, targetID)
+		if err := change(txCtx, entry); err != nil {
+			if errors.Is(err, errUnchanged) {
+				return nil
+			}
+			return err
+		}
+
+		entry.Actor = actor(ctx)
+		src := SourceFrom(ctx)
+		entry.RequestID = src.RequestID
+		entry.SourceIP = src.IP
+		return r.repos.Audit.Record(txCtx, entry)
+	})
+}
+
+// actor names the signed-in user, or the credential when there is none.
+func actor(ctx context.Context) string {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return ""
+	}
+	if principal.UserID != "" {
+		return principal.UserID
+	}
+	return principal.Subject
+}
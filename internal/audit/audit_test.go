@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mivihan/Pull_Request_service/internal/auth"
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+	"github.com/mivihan/Pull_Request_service/internal/service"
+)
+
+type txKey struct{}
+
+// fakeTx keeps entries recorded in a transaction until it commits.
+type fakeTx struct {
+	pending   []*domain.AuditEntry
+	committed []*domain.AuditEntry
+}
+
+func (t *fakeTx) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.pending = nil
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		return err
+	}
+	t.committed = append(t.committed, t.pending...)
+	return nil
+}
+
+type fakeAuditRepo struct {
+	repository.AuditRepository
+	tx *fakeTx
+}
+
+func (f *fakeAuditRepo) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	if ctx.Value(txKey{}) == nil {
+		return errors.New("audit entry recorded outside the transaction")
+	}
+	entry.EntryID = int64(len(f.tx.committed) + len(f.tx.pending) + 1)
+	f.tx.pending = append(f.tx.pending, entry)
+	return nil
+}
+
+type fakePRRepo struct {
+	repository.PRRepository
+	prs map[string]*domain.PullRequest
+}
+
+func (f *fakePRRepo) GetByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	if pr, ok := f.prs[prID]; ok {
+		copied := *pr
+		return &copied, nil
+	}
+	return nil, domain.ErrPRNotFound
+}
+
+func (f *fakePRRepo) GetByIDForUpdate(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return f.GetByID(ctx, prID)
+}
+
+type stubPRService struct {
+	service.PRService
+	prs *fakePRRepo
+	err error
+}
+
+func (s *stubPRService) MergePR(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	pr := s.prs.prs[prID]
+	pr.Status = domain.PRStatusMerged
+	return pr, nil
+}
+
+type stubAPIKeyService struct {
+	service.APIKeyService
+}
+
+func (stubAPIKeyService) CreateKey(ctx context.Context, name string, scopes []domain.Scope) (*domain.APIKey, string, error) {
+	return &domain.APIKey{KeyID: "3f9c0a1b2c3d4e5f", Name: name, Scopes: scopes}, "prs_3f9c0a1b2c3d4e5f_secret", nil
+}
+
+func newTestRecorder() (*Recorder, *fakeTx, *fakePRRepo) {
+	tx := &fakeTx{}
+	prs := &fakePRRepo{prs: map[string]*domain.PullRequest{
+		"pr-1": {PullRequestID: "pr-1", AuthorID: "author", Status: domain.PRStatusOpen, AssignedReviewers: []string{"r1"}},
+	}}
+	return NewRecorder(&repository.Repositories{
+		PR:    prs,
+		Audit: &fakeAuditRepo{tx: tx},
+		Tx:    tx,
+	}), tx, prs
+}
+
+func requestContext() context.Context {
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "jwt:sso#alice", UserID: "alice"})
+	return WithSource(ctx, Source{RequestID: "req-1", IP: "10.0.0.7"})
+}
+
+func TestPRService_RecordsMerge(t *testing.T) {
+	rec, tx, prs := newTestRecorder()
+	svc := NewPRService(&stubPRService{prs: prs}, rec)
+
+	if _, err := svc.MergePR(requestContext(), "pr-1"); err != nil {
+		t.Fatalf("MergePR: %v", err)
+	}
+
+	if len(tx.committed) != 1 {
+		t.Fatalf("expected 1 committed entry, got %d", len(tx.committed))
+	}
+	e := tx.committed[0]
+	if e.Action != domain.AuditPRMerge || e.TargetType != domain.AuditTargetPR || e.TargetID != "pr-1" {
+		t.Errorf("unexpected entry %s %s %s", e.Action, e.TargetType, e.TargetID)
+	}
+	if e.Actor != "alice" || e.RequestID != "req-1" || e.SourceIP != "10.0.0.7" {
+		t.Errorf("unexpected origin: actor %q, request %q, ip %q", e.Actor, e.RequestID, e.SourceIP)
+	}
+	if e.Before["status"] != domain.PRStatusOpen || e.After["status"] != domain.PRStatusMerged {
+		t.Errorf("expected OPEN -> MERGED, got %v -> %v", e.Before["status"], e.After["status"])
+	}
+}
+
+func TestPRService_RepeatedMergeLeavesNoEntry(t *testing.T) {
+	rec, tx, prs := newTestRecorder()
+	svc := NewPRService(&stubPRService{prs: prs}, rec)
+
+	for range 2 {
+		if _, err := svc.MergePR(requestContext(), "pr-1"); err != nil {
+			t.Fatalf("MergePR: %v", err)
+		}
+	}
+	if len(tx.committed) != 1 {
+		t.Errorf("expected only the first merge to be recorded, got %d entries", len(tx.committed))
+	}
+}
+
+func TestPRService_FailedCallLeavesNoEntry(t *testing.T) {
+	rec, tx, prs := newTestRecorder()
+	svc := NewPRService(&stubPRService{prs: prs, err: domain.ErrPRMerged}, rec)
+
+	if _, err := svc.MergePR(requestContext(), "pr-1"); !errors.Is(err, domain.ErrPRMerged) {
+		t.Fatalf("expected the service error, got %v", err)
+	}
+	if _, err := svc.MergePR(requestContext(), "missing"); !errors.Is(err, domain.ErrPRNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if len(tx.committed) != 0 {
+		t.Errorf("expected no entries, got %d", len(tx.committed))
+	}
+}
+
+func TestAPIKeyService_RecordsCreateWithoutSecret(t *testing.T) {
+	rec, tx, _ := newTestRecorder()
+	svc := NewAPIKeyService(stubAPIKeyService{}, rec)
+
+	// Without authentication there is no actor.
+	_, secret, err := svc.CreateKey(context.Background(), "ci", []domain.Scope{domain.ScopeRead})
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+
+	if len(tx.committed) != 1 {
+		t.Fatalf("expected 1 committed entry, got %d", len(tx.committed))
+	}
+	e := tx.committed[0]
+	if e.TargetID != "3f9c0a1b2c3d4e5f" || e.Actor != "" || e.Before != nil {
+		t.Errorf("unexpected entry: target %q, actor %q, before %v", e.TargetID, e.Actor, e.Before)
+	}
+	for key, value := range e.After {
+		if fmt.Sprint(value) == secret {
+			t.Errorf("secret recorded under %q", key)
+		}
+	}
+}
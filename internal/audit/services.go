@@ -0,0 +1,469 @@
+package audit
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/service"
+)
+
+type teamService struct {
+	service.TeamService
+	rec *Recorder
+}
+
+func NewTeamService(next service.TeamService, rec *Recorder) service.TeamService {
+	return &teamService{TeamService: next, rec: rec}
+}
+
+func (s *teamService) CreateTeam(ctx context.Context, teamName string, members []service.TeamMemberInput) (*service.TeamWithMembers, error) {
+	var team *service.TeamWithMembers
+	err := s.rec.record(ctx, domain.AuditTeamCreate, domain.AuditTargetTeam, teamName, func(ctx context.Context, e *domain.AuditEntry) error {
+		var err error
+		if team, err = s.TeamService.CreateTeam(ctx, teamName, members); err != nil {
+			return err
+		}
+		memberIDs := make([]string, len(team.Members))
+		for i, m := range team.Members {
+			memberIDs[i] = m.UserID
+		}
+		e.After = map[string]any{"members": memberIDs}
+		return nil
+	})
+	return team, err
+}
+
+func (s *teamService) DeactivateTeamUsers(ctx context.Context, teamName string, userIDs []string) (*service.DeactivationResult, error) {
+	var result *service.DeactivationResult
+	err := s.rec.record(ctx, domain.AuditTeamDeactivateUsers, domain.AuditTargetTeam, teamName, func(ctx context.Context, e *domain.AuditEntry) error {
+		members, err := s.rec.repos.User.ListByTeam(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		requested := make(map[string]bool, len(userIDs))
+		for _, id := range userIDs {
+			requested[id] = true
+		}
+		active := []string{}
+		for _, m := range members {
+			if m.IsActive && requested[m.UserID] {
+				active = append(active, m.UserID)
+			}
+		}
+		e.Before = map[string]any{"active": active}
+
+		if result, err = s.TeamService.DeactivateTeamUsers(ctx, teamName, userIDs); err != nil {
+			return err
+		}
+		e.After = map[string]any{
+			"deactivated_count": result.DeactivatedCount,
+			"affected_pr_count": result.AffectedPRCount,
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (s *teamService) UpdateSettings(ctx context.Context, settings *domain.TeamSettings) (*domain.TeamSettings, error) {
+	var updated *domain.TeamSettings
+	err := s.rec.record(ctx, domain.AuditTeamUpdateSettings, domain.AuditTargetTeam, settings.TeamName, func(ctx context.Context, e *domain.AuditEntry) error {
+		// The lock keeps the state recorded as before from changing until update runs.
+		if _, err := s.rec.repos.Team.GetByNameForUpdate(ctx, settings.TeamName); err != nil {
+			return err
+		}
+		before, err := s.rec.repos.Settings.Get(ctx, settings.TeamName)
+		if err != nil {
+			return err
+		}
+		e.Before = settingsState(before)
+
+		if updated, err = s.TeamService.UpdateSettings(ctx, settings); err != nil {
+			return err
+		}
+		e.After = settingsState(updated)
+		return nil
+	})
+	return updated, err
+}
+
+type userService struct {
+	service.UserService
+	rec *Recorder
+}
+
+func NewUserService(next service.UserService, rec *Recorder) service.UserService {
+	return &userService{UserService: next, rec: rec}
+}
+
+func (s *userService) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+	return s.updateUser(ctx, domain.AuditUserSetActive, userID,
+		func(u *domain.User) map[string]any { return map[string]any{"is_active": u.IsActive} },
+		func(ctx context.Context) (*domain.User, error) {
+			return s.UserService.SetIsActive(ctx, userID, isActive)
+		},
+	)
+}
+
+func (s *userService) SetSlackMemberID(ctx context.Context, userID, memberID string) (*domain.User, error) {
+	return s.updateUser(ctx, domain.AuditUserSetSlackID, userID,
+		func(u *domain.User) map[string]any { return map[string]any{"slack_member_id": u.SlackMemberID} },
+		func(ctx context.Context) (*domain.User, error) {
+			return s.UserService.SetSlackMemberID(ctx, userID, memberID)
+		},
+	)
+}
+
+func (s *userService) SetEmail(ctx context.Context, userID, email string) (*domain.User, error) {
+	return s.updateUser(ctx, domain.AuditUserSetEmail, userID,
+		func(u *domain.User) map[string]any { return map[string]any{"email": u.Email} },
+		func(ctx context.Context) (*domain.User, error) { return s.UserService.SetEmail(ctx, userID, email) },
+	)
+}
+
+// updateUser records a change of a single user field, summarised by state.
+func (s *userService) updateUser(
+	ctx context.Context,
+	action domain.AuditAction,
+	userID string,
+	state func(*domain.User) map[string]any,
+	update func(ctx context.Context) (*domain.User, error),
+) (*domain.User, error) {
+	var user *domain.User
+	err := s.rec.record(ctx, action, domain.AuditTargetUser, userID, func(ctx context.Context, e *domain.AuditEntry) error {
+		before, err := s.rec.repos.User.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		e.Before = state(before)
+
+		if user, err = update(ctx); err != nil {
+			return err
+		}
+		e.After = state(user)
+		return nil
+	})
+	return user, err
+}
+
+type prService struct {
+	service.PRService
+	rec *Recorder
+}
+
+func NewPRService(next service.PRService, rec *Recorder) service.PRService {
+	return &prService{PRService: next, rec: rec}
+}
+
+func (s *prService) CreatePR(ctx context.Context, prID, prName, authorID, repository string) (*domain.PullRequest, error) {
+	var pr *domain.PullRequest
+	err := s.rec.record(ctx, domain.AuditPRCreate, domain.AuditTargetPR, prID, func(ctx context.Context, e *domain.AuditEntry) error {
+		var err error
+		if pr, err = s.PRService.CreatePR(ctx, prID, prName, authorID, repository); err != nil {
+			return err
+		}
+		e.After = prState(pr)
+		e.After["author_id"] = pr.AuthorID
+		return nil
+	})
+	return pr, err
+}
+
+func (s *prService) MergePR(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return s.updatePR(ctx, domain.AuditPRMerge, prID, func(ctx context.Context) (*domain.PullRequest, error) {
+		return s.PRService.MergePR(ctx, prID)
+	})
+}
+
+func (s *prService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*domain.PullRequest, string, error) {
+	var replacedBy string
+	pr, err := s.updatePR(ctx, domain.AuditPRReassign, prID, func(ctx context.Context) (*domain.PullRequest, error) {
+		pr, newUserID, err := s.PRService.ReassignReviewer(ctx, prID, oldUserID)
+		replacedBy = newUserID
+		return pr, err
+	})
+	return pr, replacedBy, err
+}
+
+func (s *prService) DeclineReview(ctx context.Context, prID, userID string, reason domain.DeclineReason, comment string) (*domain.PullRequest, string, error) {
+	var replacedBy string
+	pr, err := s.updatePR(ctx, domain.AuditPRDecline, prID, func(ctx context.Context) (*domain.PullRequest, error) {
+		pr, newUserID, err := s.PRService.DeclineReview(ctx, prID, userID, reason, comment)
+		replacedBy = newUserID
+		return pr, err
+	})
+	return pr, replacedBy, err
+}
+
+func (s *prService) SubmitReview(ctx context.Context, prID, userID string, verdict domain.ReviewVerdict, comment string) (*domain.PREvent, error) {
+	var event *domain.PREvent
+	err := s.rec.record(ctx, domain.AuditPRReview, domain.AuditTargetPR, prID, func(ctx context.Context, e *domain.AuditEntry) error {
+		var err error
+		if event, err = s.PRService.SubmitReview(ctx, prID, userID, verdict, comment); err != nil {
+			return err
+		}
+		e.After = map[string]any{"reviewer": userID, "verdict": verdict}
+		return nil
+	})
+	return event, err
+}
+
+func (s *prService) AddReviewer(ctx context.Context, prID, userID, actorID string) (*domain.PullRequest, error) {
+	return s.updatePR(ctx, domain.AuditPRAddReviewer, prID, func(ctx context.Context) (*domain.PullRequest, error) {
+		return s.PRService.AddReviewer(ctx, prID, userID, actorID)
+	})
+}
+
+func (s *prService) RemoveReviewer(ctx context.Context, prID, userID, actorID string) (*domain.PullRequest, error) {
+	return s.updatePR(ctx, domain.AuditPRRemoveReviewer, prID, func(ctx context.Context) (*domain.PullRequest, error) {
+		return s.PRService.RemoveReviewer(ctx, prID, userID, actorID)
+	})
+}
+
+func (s *prService) SetReviewers(ctx context.Context, prID string, userIDs []string, actorID string) (*domain.PullRequest, error) {
+	return s.updatePR(ctx, domain.AuditPRSetReviewers, prID, func(ctx context.Context) (*domain.PullRequest, error) {
+		return s.PRService.SetReviewers(ctx, prID, userIDs, actorID)
+	})
+}
+
+// updatePR records the status and reviewers of a pull request around update.
+func (s *prService) updatePR(ctx context.Context, action domain.AuditAction, prID string, update func(ctx context.Context) (*domain.PullRequest, error)) (*domain.PullRequest, error) {
+	var pr *domain.PullRequest
+	err := s.rec.record(ctx, action, domain.AuditTargetPR, prID, func(ctx context.Context, e *domain.AuditEntry) error {
+		// The lock keeps the state recorded as before from changing until update runs.
+		before, err := s.rec.repos.PR.GetByIDForUpdate(ctx, prID)
+		if err != nil {
+			return err
+		}
+		e.Before = prState(before)
+
+		if pr, err = update(ctx); err != nil {
+			return err
+		}
+		e.After = prState(pr)
+		// Merging a merged pull request succeeds without changing it.
+		if reflect.DeepEqual(e.Before, e.After) {
+			return errUnchanged
+		}
+		return nil
+	})
+	return pr, err
+}
+
+type constraintService struct {
+	service.ConstraintService
+	rec *Recorder
+}
+
+func NewConstraintService(next service.ConstraintService, rec *Recorder) service.ConstraintService {
+	return &constraintService{ConstraintService: next, rec: rec}
+}
+
+func (s *constraintService) AddExclusion(ctx context.Context, teamName, userID, otherUserID string) (*domain.ExclusionPair, error) {
+	var pair *domain.ExclusionPair
+	err := s.rec.record(ctx, domain.AuditExclusionAdd, domain.AuditTargetTeam, teamName, func(ctx context.Context, e *domain.AuditEntry) error {
+		var err error
+		if pair, err = s.ConstraintService.AddExclusion(ctx, teamName, userID, otherUserID); err != nil {
+			return err
+		}
+		e.After = map[string]any{"user_a": pair.UserA, "user_b": pair.UserB}
+		return nil
+	})
+	return pair, err
+}
+
+func (s *constraintService) RemoveExclusion(ctx context.Context, teamName, userID, otherUserID string) error {
+	return s.rec.record(ctx, domain.AuditExclusionRemove, domain.AuditTargetTeam, teamName, func(ctx context.Context, e *domain.AuditEntry) error {
+		pair := domain.NewExclusionPair(teamName, userID, otherUserID)
+		e.Before = map[string]any{"user_a": pair.UserA, "user_b": pair.UserB}
+		return s.ConstraintService.RemoveExclusion(ctx, teamName, userID, otherUserID)
+	})
+}
+
+func (s *constraintService) AddNeverAssign(ctx context.Context, authorID, reviewerID string) (*domain.NeverAssign, error) {
+	var entry *domain.NeverAssign
+	err := s.rec.record(ctx, domain.AuditNeverAssignAdd, domain.AuditTargetUser, authorID, func(ctx context.Context, e *domain.AuditEntry) error {
+		var err error
+		if entry, err = s.ConstraintService.AddNeverAssign(ctx, authorID, reviewerID); err != nil {
+			return err
+		}
+		e.After = map[string]any{"reviewer_id": entry.ReviewerID}
+		return nil
+	})
+	return entry, err
+}
+
+func (s *constraintService) RemoveNeverAssign(ctx context.Context, authorID, reviewerID string) error {
+	return s.rec.record(ctx, domain.AuditNeverAssignRemove, domain.AuditTargetUser, authorID, func(ctx context.Context, e *domain.AuditEntry) error {
+		e.Before = map[string]any{"reviewer_id": reviewerID}
+		return s.ConstraintService.RemoveNeverAssign(ctx, authorID, reviewerID)
+	})
+}
+
+type reminderService struct {
+	service.ReminderService
+	rec *Recorder
+}
+
+func NewReminderService(next service.ReminderService, rec *Recorder) service.ReminderService {
+	return &reminderService{ReminderService: next, rec: rec}
+}
+
+func (s *reminderService) UpdatePreferences(ctx context.Context, prefs *domain.ReminderPreferences) (*domain.ReminderPreferences, error) {
+	var updated *domain.ReminderPreferences
+	err := s.rec.record(ctx, domain.AuditUserSetReminders, domain.AuditTargetUser, prefs.UserID, func(ctx context.Context, e *domain.AuditEntry) error {
+		before, err := s.rec.repos.Reminder.GetPreferences(ctx, prefs.UserID)
+		if err != nil {
+			return err
+		}
+		e.Before = reminderState(before)
+
+		if updated, err = s.ReminderService.UpdatePreferences(ctx, prefs); err != nil {
+			return err
+		}
+		e.After = reminderState(updated)
+		return nil
+	})
+	return updated, err
+}
+
+type notificationService struct {
+	service.NotificationService
+	rec *Recorder
+}
+
+func NewNotificationService(next service.NotificationService, rec *Recorder) service.NotificationService {
+	return &notificationService{NotificationService: next, rec: rec}
+}
+
+func (s *notificationService) SetTemplate(ctx context.Context, tmpl *domain.MessageTemplate) (*domain.MessageTemplate, error) {
+	var updated *domain.MessageTemplate
+	err := s.rec.record(ctx, domain.AuditTeamSetTemplate, domain.AuditTargetTeam, tmpl.TeamName, func(ctx context.Context, e *domain.AuditEntry) error {
+		before, err := s.rec.repos.Notification.GetTemplate(ctx, tmpl.TeamName, tmpl.Kind)
+		if err != nil {
+			return err
+		}
+		e.Before = templateState(tmpl.Kind, before)
+
+		if updated, err = s.NotificationService.SetTemplate(ctx, tmpl); err != nil {
+			return err
+		}
+		e.After = templateState(updated.Kind, updated.Body)
+		return nil
+	})
+	return updated, err
+}
+
+type apiKeyService struct {
+	service.APIKeyService
+	rec *Recorder
+}
+
+func NewAPIKeyService(next service.APIKeyService, rec *Recorder) service.APIKeyService {
+	return &apiKeyService{APIKeyService: next, rec: rec}
+}
+
+func (s *apiKeyService) CreateKey(ctx context.Context, name string, scopes []domain.Scope) (*domain.APIKey, string, error) {
+	var key *domain.APIKey
+	var secret string
+	err := s.rec.record(ctx, domain.AuditAPIKeyCreate, domain.AuditTargetAPIKey, "", func(ctx context.Context, e *domain.AuditEntry) error {
+		var err error
+		if key, secret, err = s.APIKeyService.CreateKey(ctx, name, scopes); err != nil {
+			return err
+		}
+		e.TargetID = key.KeyID
+		e.After = apiKeyState(key)
+		return nil
+	})
+	return key, secret, err
+}
+
+func (s *apiKeyService) RotateKey(ctx context.Context, keyID string) (*domain.APIKey, string, error) {
+	var key *domain.APIKey
+	var secret string
+	err := s.updateKey(ctx, domain.AuditAPIKeyRotate, keyID, func(ctx context.Context) (*domain.APIKey, error) {
+		var err error
+		key, secret, err = s.APIKeyService.RotateKey(ctx, keyID)
+		return key, err
+	})
+	return key, secret, err
+}
+
+func (s *apiKeyService) RevokeKey(ctx context.Context, keyID string) (*domain.APIKey, error) {
+	var key *domain.APIKey
+	err := s.updateKey(ctx, domain.AuditAPIKeyRevoke, keyID, func(ctx context.Context) (*domain.APIKey, error) {
+		var err error
+		key, err = s.APIKeyService.RevokeKey(ctx, keyID)
+		return key, err
+	})
+	return key, err
+}
+
+func (s *apiKeyService) updateKey(ctx context.Context, action domain.AuditAction, keyID string, update func(ctx context.Context) (*domain.APIKey, error)) error {
+	return s.rec.record(ctx, action, domain.AuditTargetAPIKey, keyID, func(ctx context.Context, e *domain.AuditEntry) error {
+		before, _, err := s.rec.repos.APIKey.GetByID(ctx, keyID)
+		if err != nil {
+			return err
+		}
+		e.Before = apiKeyState(before)
+
+		key, err := update(ctx)
+		if err != nil {
+			return err
+		}
+		e.After = apiKeyState(key)
+		return nil
+	})
+}
+
+type roleService struct {
+	service.RoleService
+	rec *Recorder
+}
+
+func NewRoleService(next service.RoleService, rec *Recorder) service.RoleService {
+	return &roleService{RoleService: next, rec: rec}
+}
+
+func (s *roleService) SetRole(ctx context.Context, assignment *domain.RoleAssignment) (*domain.RoleAssignment, error) {
+	var updated *domain.RoleAssignment
+	err := s.rec.record(ctx, domain.AuditRoleSet, domain.AuditTargetUser, assignment.UserID, func(ctx context.Context, e *domain.AuditEntry) error {
+		before, err := s.currentRole(ctx, assignment.UserID, assignment.TeamName)
+		if err != nil {
+			return err
+		}
+		e.Before = roleState(before)
+
+		if updated, err = s.RoleService.SetRole(ctx, assignment); err != nil {
+			return err
+		}
+		e.After = roleState(updated)
+		return nil
+	})
+	return updated, err
+}
+
+func (s *roleService) RemoveRole(ctx context.Context, userID, teamName string) error {
+	return s.rec.record(ctx, domain.AuditRoleRemove, domain.AuditTargetUser, userID, func(ctx context.Context, e *domain.AuditEntry) error {
+		before, err := s.currentRole(ctx, userID, teamName)
+		if err != nil {
+			return err
+		}
+		e.Before = roleState(before)
+		return s.RoleService.RemoveRole(ctx, userID, teamName)
+	})
+}
+
+// currentRole returns nil when the user has no role in the team.
+func (s *roleService) currentRole(ctx context.Context, userID, teamName string) (*domain.RoleAssignment, error) {
+	roles, err := s.rec.repos.Role.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range roles {
+		if r.TeamName == teamName {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+// The functions below summarise the audited fields of an object. They leave out
+// anything that is not changed by the API, and never include secrets.
+
+func prState(pr *domain.PullRequest) map[string]any {
+	return map[string]any{
+		"status":    pr.Status,
+		"reviewers": nonNil(pr.AssignedReviewers),
+	}
+}
+
+func settingsState(s *domain.TeamSettings) map[string]any {
+	return map[string]any{
+		"review_sla":     s.ReviewSLA.String(),
+		"stale_timeout":  s.StaleTimeout.String(),
+		"timezone":       s.Timezone,
+		"work_day_start": s.WorkDayStart,
+		"work_day_end":   s.WorkDayEnd,
+	}
+}
+
+func reminderState(p *domain.ReminderPreferences) map[string]any {
+	return map[string]any{
+		"enabled":     p.Enabled,
+		"frequency":   p.Frequency.String(),
+		"quiet_start": p.QuietStart,
+		"quiet_end":   p.QuietEnd,
+		"channel":     p.Channel,
+	}
+}
+
+func apiKeyState(k *domain.APIKey) map[string]any {
+	return map[string]any{
+		"name":       k.Name,
+		"scopes":     k.Scopes,
+		"rotated_at": k.RotatedAt,
+		"revoked_at": k.RevokedAt,
+	}
+}
+
+func roleState(a *domain.RoleAssignment) map[string]any {
+	if a == nil {
+		return nil
+	}
+	return map[string]any{
+		"team_name": a.TeamName,
+		"role":      a.Role,
+	}
+}
+
+func templateState(kind domain.NotificationKind, body string) map[string]any {
+	if body == "" {
+		return nil
+	}
+	return map[string]any{"kind": kind, "body": body}
+}
+
+// nonNil makes an empty list render as [] rather than null.
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
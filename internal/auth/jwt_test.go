@@ -0,0 +1,291 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testIssuer = "https://sso.example.com"
+
+var testNow = time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+
+// fakeJWKS serves a key set that tests can change to simulate rotation.
+type fakeJWKS struct {
+	mu       sync.Mutex
+	keys     []map[string]string
+	requests int
+}
+
+func (f *fakeJWKS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	json.NewEncoder(w).Encode(map[string]any{"keys": f.keys})
+}
+
+func (f *fakeJWKS) set(keys ...map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = keys
+}
+
+func (f *fakeJWKS) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   b64(key.N.Bytes()),
+		"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	point, _ := key.PublicKey.Bytes()
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   b64(point[1:33]),
+		"y":   b64(point[33:]),
+	}
+}
+
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		signature = sig
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signingInput + "." + b64(signature)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":   testIssuer,
+		"sub":   "abc-123",
+		"aud":   []string{"pr-reviewer", "other"},
+		"exp":   testNow.Add(time.Hour).Unix(),
+		"iat":   testNow.Unix(),
+		"uid":   "u1",
+		"roles": []string{"admin", "dev"},
+	}
+}
+
+func newTestVerifier(t *testing.T) (*JWTVerifier, *fakeJWKS, *rsa.PrivateKey, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate EC key: %v", err)
+	}
+
+	jwks := &fakeJWKS{}
+	jwks.set(rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey))
+	srv := httptest.NewServer(jwks)
+	t.Cleanup(srv.Close)
+
+	v := NewJWTVerifier(JWTConfig{
+		Issuers:   []string{"https://other.example.com", testIssuer},
+		Audience:  "pr-reviewer",
+		JWKSURL:   srv.URL,
+		UserClaim: "uid",
+	})
+	v.now = func() time.Time { return testNow }
+
+	return v, jwks, rsaKey, ecKey
+}
+
+func TestJWTVerifier_Verify(t *testing.T) {
+	v, _, rsaKey, ecKey := newTestVerifier(t)
+
+	for _, tc := range []struct {
+		alg, kid string
+		key      crypto.Signer
+	}{
+		{alg: "RS256", kid: "rsa-1", key: rsaKey},
+		{alg: "ES256", kid: "ec-1", key: ecKey},
+	} {
+		t.Run(tc.alg, func(t *testing.T) {
+			claims, err := v.Verify(context.Background(), signToken(t, tc.alg, tc.kid, tc.key, validClaims()))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claims.UserID != "u1" || claims.Subject != "abc-123" || claims.Issuer != testIssuer {
+				t.Errorf("unexpected claims %+v", claims)
+			}
+			if claims.TenantID != "default" {
+				t.Errorf("expected the default tenant without a tenant claim, got %q", claims.TenantID)
+			}
+			if len(claims.Roles) != 2 || claims.Roles[0] != "admin" {
+				t.Errorf("unexpected roles %v", claims.Roles)
+			}
+		})
+	}
+}
+
+func TestJWTVerifier_TenantClaim(t *testing.T) {
+	v, _, rsaKey, _ := newTestVerifier(t)
+
+	claims := validClaims()
+	claims["tenant"] = "acme"
+	id, err := v.Authenticate(context.Background(), signToken(t, "RS256", "rsa-1", rsaKey, claims))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != (Identity{TenantID: "acme", UserID: "u1"}) {
+		t.Errorf("expected u1 of acme, got %+v", id)
+	}
+}
+
+func TestJWTVerifier_Rejects(t *testing.T) {
+	v, _, rsaKey, ecKey := newTestVerifier(t)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	with := func(name string, value any) map[string]any {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+	valid := signToken(t, "RS256", "rsa-1", rsaKey, validClaims())
+	header, _ := json.Marshal(map[string]string{"alg": "none", "kid": "rsa-1"})
+	payload, _ := json.Marshal(validClaims())
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "expired", token: signToken(t, "RS256", "rsa-1", rsaKey, with("exp", testNow.Add(-2*time.Minute).Unix()))},
+		{name: "missing exp", token: signToken(t, "RS256", "rsa-1", rsaKey, with("exp", nil))},
+		{name: "not valid yet", token: signToken(t, "RS256", "rsa-1", rsaKey, with("nbf", testNow.Add(5*time.Minute).Unix()))},
+		{name: "unknown issuer", token: signToken(t, "RS256", "rsa-1", rsaKey, with("iss", "https://evil.example.com"))},
+		{name: "wrong audience", token: signToken(t, "RS256", "rsa-1", rsaKey, with("aud", "someone-else"))},
+		{name: "missing user claim", token: signToken(t, "RS256", "rsa-1", rsaKey, with("uid", nil))},
+		{name: "signed by another key", token: signToken(t, "RS256", "rsa-1", otherKey, validClaims())},
+		{name: "algorithm confusion", token: signToken(t, "ES256", "rsa-1", ecKey, validClaims())},
+		{name: "alg none", token: b64(header) + "." + b64(payload) + "."},
+		{name: "tampered payload", token: valid[:len(valid)-4] + "AAAA"},
+		{name: "malformed", token: "not-a-jwt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := v.Verify(context.Background(), tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestJWTVerifier_KeyRotation(t *testing.T) {
+	v, jwks, rsaKey, _ := newTestVerifier(t)
+	ctx := context.Background()
+
+	if _, err := v.Verify(ctx, signToken(t, "RS256", "rsa-1", rsaKey, validClaims())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := v.Verify(ctx, signToken(t, "RS256", "rsa-1", rsaKey, validClaims())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if jwks.count() != 1 {
+		t.Fatalf("expected keys to be cached, got %d fetches", jwks.count())
+	}
+
+	rotated, _ := rsa.GenerateKey(rand.Reader, 2048)
+	jwks.set(rsaJWK("rsa-2", rotated))
+	token := signToken(t, "RS256", "rsa-2", rotated, validClaims())
+
+	// Unknown keys are refetched at most once per interval.
+	v.now = func() time.Time { return testNow.Add(10 * time.Second) }
+	if _, err := v.Verify(ctx, token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected unknown key to be rejected before the refresh interval, got %v", err)
+	}
+
+	v.now = func() time.Time { return testNow.Add(jwksRefreshInterval) }
+	if _, err := v.Verify(ctx, token); err != nil {
+		t.Fatalf("expected rotated key to be fetched, got %v", err)
+	}
+	if jwks.count() != 2 {
+		t.Errorf("expected 2 fetches, got %d", jwks.count())
+	}
+
+	if _, err := v.Verify(ctx, signToken(t, "RS256", "rsa-1", rsaKey, validClaims())); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected retired key to be rejected, got %v", err)
+	}
+}
+
+func TestJWTVerifier_KeepsCachedKeysWhenProviderIsDown(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	jwks := &fakeJWKS{}
+	jwks.set(rsaJWK("rsa-1", rsaKey))
+	srv := httptest.NewServer(jwks)
+
+	v := NewJWTVerifier(JWTConfig{Issuers: []string{testIssuer}, JWKSURL: srv.URL})
+	v.now = func() time.Time { return testNow }
+	claims := validClaims()
+	delete(claims, "uid")
+
+	if _, err := v.Verify(context.Background(), signToken(t, "RS256", "rsa-1", rsaKey, claims)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv.Close()
+	v.now = func() time.Time { return testNow.Add(2 * defaultJWKSCacheTTL) }
+	claims["exp"] = testNow.Add(3 * defaultJWKSCacheTTL).Unix()
+	got, err := v.Verify(context.Background(), signToken(t, "RS256", "rsa-1", rsaKey, claims))
+	if err != nil {
+		t.Fatalf("expected cached key to be used, got %v", err)
+	}
+	if got.UserID != "abc-123" {
+		t.Errorf("expected sub to be the default user claim, got %s", got.UserID)
+	}
+}
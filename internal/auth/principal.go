@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the credential, for example "api_key:3f9c0a1b2c3d4e5f".
+	Subject string
+	// Tenant is the tenant the credential belongs to. It is empty for the bootstrap
+	// key, which operates the deployment and picks a tenant per request.
+	Tenant string
+	Scopes []domain.Scope
+	// UserID and Roles are set when the caller signed in as a user through SSO. Admin
+	// is set when the SSO roles make the user an administrator of every team.
+	UserID string
+	Roles  []string
+	Admin  bool
+}
+
+func (p *Principal) Allows(scope domain.Scope) bool {
+	return domain.ScopesAllow(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the caller of the request, if authentication is enabled.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
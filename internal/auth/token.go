@@ -0,0 +1,126 @@
+// Package auth verifies the credentials clients present to the service.
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Identity is the user a token was issued to. User IDs are only unique within a tenant.
+type Identity struct {
+	TenantID string
+	UserID   string
+}
+
+// Authenticator resolves a bearer token to the user it was issued to.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (Identity, error)
+}
+
+// HMACAuthenticator accepts tokens of the form [tenant.]user.expiry.signature, where
+// tenant and user are the base64url-encoded tenant and user IDs, expiry a Unix
+// timestamp and signature the base64url HMAC-SHA256 of everything before it under the
+// shared secret. Tokens without a tenant belong to the default tenant.
+type HMACAuthenticator struct {
+	secret []byte
+	now    func() time.Time
+}
+
+func NewHMACAuthenticator(secret string) *HMACAuthenticator {
+	return &HMACAuthenticator{secret: []byte(secret), now: time.Now}
+}
+
+// Issue returns a token for the user of the tenant that expires after ttl. Tokens of
+// the default tenant leave the tenant out, so they stay readable by older replicas.
+func (a *HMACAuthenticator) Issue(tenantID, userID string, ttl time.Duration) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userID)) + "." +
+		strconv.FormatInt(a.now().Add(ttl).Unix(), 10)
+	if tenantID != "" && tenantID != tenant.Default {
+		payload = base64.RawURLEncoding.EncodeToString([]byte(tenantID)) + "." + payload
+	}
+	return payload + "." + a.sign(payload)
+}
+
+func (a *HMACAuthenticator) Authenticate(_ context.Context, token string) (Identity, error) {
+	idx := strings.LastIndexByte(token, '.')
+	if idx < 0 {
+		return Identity{}, ErrInvalidToken
+	}
+	payload, signature := token[:idx], token[idx+1:]
+	if !hmac.Equal([]byte(signature), []byte(a.sign(payload))) {
+		return Identity{}, ErrInvalidToken
+	}
+
+	parts := strings.Split(payload, ".")
+	id := Identity{TenantID: tenant.Default}
+	switch len(parts) {
+	case 2:
+	case 3:
+		tenantID, err := base64.RawURLEncoding.DecodeString(parts[0])
+		if err != nil || len(tenantID) == 0 {
+			return Identity{}, ErrInvalidToken
+		}
+		id.TenantID = string(tenantID)
+		parts = parts[1:]
+	default:
+		return Identity{}, ErrInvalidToken
+	}
+
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || a.now().Unix() >= expiry {
+		return Identity{}, ErrInvalidToken
+	}
+	userID, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(userID) == 0 {
+		return Identity{}, ErrInvalidToken
+	}
+	id.UserID = string(userID)
+
+	return id, nil
+}
+
+func (a *HMACAuthenticator) sign(payload string) string {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// InsecureAuthenticator takes the token to be the user ID, or "tenant/user" for users
+// outside the default tenant. It is meant for local development only, when no secret
+// is configured.
+type InsecureAuthenticator struct{}
+
+func (InsecureAuthenticator) Authenticate(_ context.Context, token string) (Identity, error) {
+	tenantID, userID, ok := strings.Cut(token, "/")
+	if !ok {
+		tenantID, userID = tenant.Default, token
+	}
+	if tenantID == "" || userID == "" {
+		return Identity{}, ErrInvalidToken
+	}
+	return Identity{TenantID: tenantID, UserID: userID}, nil
+}
+
+// Chain tries each authenticator in turn and returns the first success.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(ctx context.Context, token string) (Identity, error) {
+	err := ErrInvalidToken
+	for _, a := range c {
+		var id Identity
+		if id, err = a.Authenticate(ctx, token); err == nil {
+			return id, nil
+		}
+	}
+	return Identity{}, err
+}
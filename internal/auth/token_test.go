@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACAuthenticator(t *testing.T) {
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	a := NewHMACAuthenticator("secret")
+	a.now = func() time.Time { return now }
+
+	token := a.Issue("", "u1", time.Hour)
+	id, err := a.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != (Identity{TenantID: "default", UserID: "u1"}) {
+		t.Errorf("expected u1 of the default tenant, got %+v", id)
+	}
+
+	tenantToken := a.Issue("acme", "u1", time.Hour)
+	if strings.Count(tenantToken, ".") != 3 {
+		t.Fatalf("expected a tenant segment in %q", tenantToken)
+	}
+	id, err = a.Authenticate(context.Background(), tenantToken)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != (Identity{TenantID: "acme", UserID: "u1"}) {
+		t.Errorf("expected u1 of acme, got %+v", id)
+	}
+
+	other := NewHMACAuthenticator("other")
+	other.now = a.now
+	forged := strings.Replace(token, token[:strings.IndexByte(token, '.')], "dTI", 1)
+
+	tests := []struct {
+		name  string
+		token string
+		at    time.Time
+	}{
+		{name: "expired", token: token, at: now.Add(time.Hour)},
+		{name: "wrong secret", token: other.Issue("", "u1", time.Hour), at: now},
+		{name: "tampered user", token: forged, at: now},
+		{name: "tenant dropped", token: tenantToken[strings.IndexByte(tenantToken, '.')+1:], at: now},
+		{name: "malformed", token: "u1", at: now},
+		{name: "empty", token: "", at: now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a.now = func() time.Time { return tt.at }
+			if _, err := a.Authenticate(context.Background(), tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestInsecureAuthenticator(t *testing.T) {
+	tests := []struct {
+		token    string
+		expected Identity
+		valid    bool
+	}{
+		{token: "u1", expected: Identity{TenantID: "default", UserID: "u1"}, valid: true},
+		{token: "acme/u1", expected: Identity{TenantID: "acme", UserID: "u1"}, valid: true},
+		{token: "", valid: false},
+		{token: "acme/", valid: false},
+		{token: "/u1", valid: false},
+	}
+	for _, tt := range tests {
+		id, err := InsecureAuthenticator{}.Authenticate(context.Background(), tt.token)
+		if (err == nil) != tt.valid {
+			t.Errorf("Authenticate(%q) error = %v, want valid %v", tt.token, err, tt.valid)
+			continue
+		}
+		if id != tt.expected {
+			t.Errorf("Authenticate(%q) = %+v, want %+v", tt.token, id, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mivihan/Pull_Request_service/internal/auth"
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+	"github.com/mivihan/Pull_Request_service/internal/service"
+)
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[string]*domain.User
+}
+
+func (f *fakeUserRepo) GetByID(ctx context.Context, userID string) (*domain.User, error) {
+	if u, ok := f.users[userID]; ok {
+		return u, nil
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+type fakePRRepo struct {
+	repository.PRRepository
+	prs map[string]*domain.PullRequest
+}
+
+func (f *fakePRRepo) GetByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	if pr, ok := f.prs[prID]; ok {
+		return pr, nil
+	}
+	return nil, domain.ErrPRNotFound
+}
+
+type fakeRoleRepo struct {
+	repository.RoleRepository
+	roles []*domain.RoleAssignment
+}
+
+func (f *fakeRoleRepo) ListByUser(ctx context.Context, userID string) ([]*domain.RoleAssignment, error) {
+	var result []*domain.RoleAssignment
+	for _, r := range f.roles {
+		if r.UserID == userID {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// allowAll stands in for the real services; reaching it means the call was authorized.
+type allowAll struct {
+	service.TeamService
+	service.PRService
+	service.RoleService
+	service.UserService
+	service.ConstraintService
+	service.NotificationService
+	service.APIKeyService
+	service.AuditService
+}
+
+func (allowAll) MergePR(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return &domain.PullRequest{PullRequestID: prID}, nil
+}
+
+func (allowAll) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*domain.PullRequest, string, error) {
+	return &domain.PullRequest{PullRequestID: prID}, "", nil
+}
+
+func (allowAll) DeactivateTeamUsers(ctx context.Context, teamName string, userIDs []string) (*service.DeactivationResult, error) {
+	return &service.DeactivationResult{}, nil
+}
+
+func (allowAll) SetRole(ctx context.Context, a *domain.RoleAssignment) (*domain.RoleAssignment, error) {
+	return a, nil
+}
+
+func (allowAll) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+	return &domain.User{UserID: userID, IsActive: isActive}, nil
+}
+
+func (allowAll) AddExclusion(ctx context.Context, teamName, userID, otherUserID string) (*domain.ExclusionPair, error) {
+	return &domain.ExclusionPair{}, nil
+}
+
+func (allowAll) AddNeverAssign(ctx context.Context, authorID, reviewerID string) (*domain.NeverAssign, error) {
+	return &domain.NeverAssign{}, nil
+}
+
+func (allowAll) SetTemplate(ctx context.Context, tmpl *domain.MessageTemplate) (*domain.MessageTemplate, error) {
+	return tmpl, nil
+}
+
+func (allowAll) CreateKey(ctx context.Context, name string, scopes []domain.Scope) (*domain.APIKey, string, error) {
+	return &domain.APIKey{Name: name, Scopes: scopes}, "secret", nil
+}
+
+func (allowAll) ListEntries(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	return nil, nil
+}
+
+func newTestAuthorizer() *Authorizer {
+	return NewAuthorizer(&repository.Repositories{
+		User: &fakeUserRepo{users: map[string]*domain.User{
+			"author":  {UserID: "author", TeamName: "backend"},
+			"member":  {UserID: "member", TeamName: "backend"},
+			"lead":    {UserID: "lead", TeamName: "backend"},
+			"outside": {UserID: "outside", TeamName: "frontend"},
+			"root":    {UserID: "root", TeamName: "frontend"},
+		}},
+		PR: &fakePRRepo{prs: map[string]*domain.PullRequest{
+			"pr-1": {PullRequestID: "pr-1", AuthorID: "author", Status: domain.PRStatusOpen},
+		}},
+		Role: &fakeRoleRepo{roles: []*domain.RoleAssignment{
+			{UserID: "lead", TeamName: "backend", Role: domain.RoleTeamAdmin},
+			{UserID: "root", Role: domain.RoleAdmin},
+		}},
+	})
+}
+
+func as(userID string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "jwt:" + userID, UserID: userID})
+}
+
+func isForbidden(err error) bool {
+	var domainErr *domain.DomainError
+	return errors.As(err, &domainErr) && domainErr.Code == domain.ErrCodeForbidden
+}
+
+func TestPRService_Authorization(t *testing.T) {
+	prs := NewPRService(allowAll{}, newTestAuthorizer())
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		call    func(ctx context.Context) error
+		allowed bool
+	}{
+		{
+			name:    "author merges",
+			ctx:     as("author"),
+			call:    func(ctx context.Context) error { _, err := prs.MergePR(ctx, "pr-1"); return err },
+			allowed: true,
+		},
+		{
+			name:    "team member cannot merge",
+			ctx:     as("member"),
+			call:    func(ctx context.Context) error { _, err := prs.MergePR(ctx, "pr-1"); return err },
+			allowed: false,
+		},
+		{
+			name:    "team admin merges",
+			ctx:     as("lead"),
+			call:    func(ctx context.Context) error { _, err := prs.MergePR(ctx, "pr-1"); return err },
+			allowed: true,
+		},
+		{
+			name:    "admin merges",
+			ctx:     as("root"),
+			call:    func(ctx context.Context) error { _, err := prs.MergePR(ctx, "pr-1"); return err },
+			allowed: true,
+		},
+		{
+			name:    "team member reassigns",
+			ctx:     as("member"),
+			call:    func(ctx context.Context) error { _, _, err := prs.ReassignReviewer(ctx, "pr-1", "x"); return err },
+			allowed: true,
+		},
+		{
+			name:    "other team cannot reassign",
+			ctx:     as("outside"),
+			call:    func(ctx context.Context) error { _, _, err := prs.ReassignReviewer(ctx, "pr-1", "x"); return err },
+			allowed: false,
+		},
+		{
+			name:    "API key callers are left to scopes",
+			ctx:     auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "api_key:k"}),
+			call:    func(ctx context.Context) error { _, err := prs.MergePR(ctx, "pr-1"); return err },
+			allowed: true,
+		},
+		{
+			name:    "authentication disabled",
+			ctx:     context.Background(),
+			call:    func(ctx context.Context) error { _, err := prs.MergePR(ctx, "pr-1"); return err },
+			allowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(tt.ctx)
+			if tt.allowed && err != nil {
+				t.Errorf("expected call to be allowed, got %v", err)
+			}
+			if !tt.allowed && !isForbidden(err) {
+				t.Errorf("expected FORBIDDEN, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTeamService_Authorization(t *testing.T) {
+	teams := NewTeamService(allowAll{}, newTestAuthorizer())
+
+	for userID, allowed := range map[string]bool{"lead": true, "root": true, "member": false, "outside": false} {
+		_, err := teams.DeactivateTeamUsers(as(userID), "backend", []string{"member"})
+		if allowed && err != nil {
+			t.Errorf("%s: expected call to be allowed, got %v", userID, err)
+		}
+		if !allowed && !isForbidden(err) {
+			t.Errorf("%s: expected FORBIDDEN, got %v", userID, err)
+		}
+	}
+
+	ssoAdmin := auth.WithPrincipal(context.Background(), &auth.Principal{UserID: "member", Admin: true})
+	if _, err := teams.DeactivateTeamUsers(ssoAdmin, "frontend", nil); err != nil {
+		t.Errorf("expected SSO admin to manage any team, got %v", err)
+	}
+}
+
+func TestRoleService_Authorization(t *testing.T) {
+	roles := NewRoleService(allowAll{}, newTestAuthorizer())
+
+	teamAdmin := &domain.RoleAssignment{UserID: "member", TeamName: "backend", Role: domain.RoleTeamAdmin}
+	if _, err := roles.SetRole(as("lead"), teamAdmin); err != nil {
+		t.Errorf("expected team admin to grant team_admin in own team, got %v", err)
+	}
+	if _, err := roles.SetRole(as("outside"), teamAdmin); !isForbidden(err) {
+		t.Errorf("expected FORBIDDEN for another team, got %v", err)
+	}
+
+	admin := &domain.RoleAssignment{UserID: "member", Role: domain.RoleAdmin}
+	if _, err := roles.SetRole(as("lead"), admin); !isForbidden(err) {
+		t.Errorf("expected team admin not to grant admin, got %v", err)
+	}
+	if _, err := roles.SetRole(as("root"), admin); err != nil {
+		t.Errorf("expected admin to grant admin, got %v", err)
+	}
+}
+
+func TestTeamScopedServices_Authorization(t *testing.T) {
+	authorizer := newTestAuthorizer()
+	users := NewUserService(allowAll{}, authorizer)
+	constraints := NewConstraintService(allowAll{}, authorizer)
+	notifications := NewNotificationService(allowAll{}, authorizer)
+
+	tests := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{
+			name: "deactivate user",
+			call: func(ctx context.Context) error { _, err := users.SetIsActive(ctx, "member", false); return err },
+		},
+		{
+			name: "add exclusion",
+			call: func(ctx context.Context) error {
+				_, err := constraints.AddExclusion(ctx, "backend", "member", "author")
+				return err
+			},
+		},
+		{
+			name: "add never-assign rule",
+			call: func(ctx context.Context) error {
+				_, err := constraints.AddNeverAssign(ctx, "author", "member")
+				return err
+			},
+		},
+		{
+			name: "set template",
+			call: func(ctx context.Context) error {
+				_, err := notifications.SetTemplate(ctx, &domain.MessageTemplate{TeamName: "backend"})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for userID, allowed := range map[string]bool{"lead": true, "root": true, "member": false, "outside": false} {
+				err := tt.call(as(userID))
+				if allowed && err != nil {
+					t.Errorf("%s: expected call to be allowed, got %v", userID, err)
+				}
+				if !allowed && !isForbidden(err) {
+					t.Errorf("%s: expected FORBIDDEN, got %v", userID, err)
+				}
+			}
+		})
+	}
+}
+
+func TestAdminServices_Authorization(t *testing.T) {
+	authorizer := newTestAuthorizer()
+	keys := NewAPIKeyService(allowAll{}, authorizer)
+	auditLog := NewAuditService(allowAll{}, authorizer)
+
+	if _, _, err := keys.CreateKey(as("lead"), "ci", domain.AllScopes); !isForbidden(err) {
+		t.Errorf("expected team admin not to create API keys, got %v", err)
+	}
+	if _, _, err := keys.CreateKey(as("root"), "ci", domain.AllScopes); err != nil {
+		t.Errorf("expected admin to create API keys, got %v", err)
+	}
+	if _, err := auditLog.ListEntries(as("lead"), domain.AuditFilter{}); !isForbidden(err) {
+		t.Errorf("expected team admin not to read the audit log, got %v", err)
+	}
+	if _, err := auditLog.ListEntries(as("root"), domain.AuditFilter{}); err != nil {
+		t.Errorf("expected admin to read the audit log, got %v", err)
+	}
+
+	apiKey := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "api_key:k"})
+	if _, _, err := keys.CreateKey(apiKey, "ci", domain.AllScopes); err != nil {
+		t.Errorf("expected API key callers to be left to scopes, got %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/service"
+)
+
+type teamService struct {
+	service.TeamService
+	authz *Authorizer
+}
+
+// NewTeamService lets only admins of a team change its members and settings.
+func NewTeamService(next service.TeamService, authz *Authorizer) service.TeamService {
+	return &teamService{TeamService: next, authz: authz}
+}
+
+func (s *teamService) CreateTeam(ctx context.Context, teamName string, members []service.TeamMemberInput) (*service.TeamWithMembers, error) {
+	if err := s.authz.requireTeamManager(ctx, teamName); err != nil {
+		return nil, err
+	}
+	return s.TeamService.CreateTeam(ctx, teamName, members)
+}
+
+func (s *teamService) DeactivateTeamUsers(ctx context.Context, teamName string, userIDs []string) (*service.DeactivationResult, error) {
+	if err := s.authz.requireTeamManager(ctx, teamName); err != nil {
+		return nil, err
+	}
+	return s.TeamService.DeactivateTeamUsers(ctx, teamName, userIDs)
+}
+
+func (s *teamService) UpdateSettings(ctx context.Context, settings *domain.TeamSettings) (*domain.TeamSettings, error) {
+	if err := s.authz.requireTeamManager(ctx, settings.TeamName); err != nil {
+		return nil, err
+	}
+	return s.TeamService.UpdateSettings(ctx, settings)
+}
+
+type prService struct {
+	service.PRService
+	authz *Authorizer
+}
+
+// NewPRService lets members of the author's team change reviewers and only the author
+// or team admins merge.
+func NewPRService(next service.PRService, authz *Authorizer) service.PRService {
+	return &prService{PRService: next, authz: authz}
+}
+
+func (s *prService) MergePR(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	if err := s.authz.requireMerge(ctx, prID); err != nil {
+		return nil, err
+	}
+	return s.PRService.MergePR(ctx, prID)
+}
+
+func (s *prService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*domain.PullRequest, string, error) {
+	if err := s.authz.requireReviewerChange(ctx, prID); err != nil {
+		return nil, "", err
+	}
+	return s.PRService.ReassignReviewer(ctx, prID, oldUserID)
+}
+
+func (s *prService) AddReviewer(ctx context.Context, prID, userID, actorID string) (*domain.PullRequest, error) {
+	if err := s.authz.requireReviewerChange(ctx, prID); err != nil {
+		return nil, err
+	}
+	return s.PRService.AddReviewer(ctx, prID, userID, actorID)
+}
+
+func (s *prService) RemoveReviewer(ctx context.Context, prID, userID, actorID string) (*domain.PullRequest, error) {
+	if err := s.authz.requireReviewerChange(ctx, prID); err != nil {
+		return nil, err
+	}
+	return s.PRService.RemoveReviewer(ctx, prID, userID, actorID)
+}
+
+func (s *prService) SetReviewers(ctx context.Context, prID string, userIDs []string, actorID string) (*domain.PullRequest, error) {
+	if err := s.authz.requireReviewerChange(ctx, prID); err != nil {
+		return nil, err
+	}
+	return s.PRService.SetReviewers(ctx, prID, userIDs, actorID)
+}
+
+type roleService struct {
+	service.RoleService
+	authz *Authorizer
+}
+
+// NewRoleService lets team admins grant team_admin in their teams; only admins grant
+// admin.
+func NewRoleService(next service.RoleService, authz *Authorizer) service.RoleService {
+	return &roleService{RoleService: next, authz: authz}
+}
+
+func (s *roleService) SetRole(ctx context.Context, assignment *domain.RoleAssignment) (*domain.RoleAssignment, error) {
+	if err := s.requireRoleManager(ctx, assignment.TeamName); err != nil {
+		return nil, err
+	}
+	return s.RoleService.SetRole(ctx, assignment)
+}
+
+func (s *roleService) RemoveRole(ctx context.Context, userID, teamName string) error {
+	if err := s.requireRoleManager(ctx, teamName); err != nil {
+		return err
+	}
+	return s.RoleService.RemoveRole(ctx, userID, teamName)
+}
+
+// requireRoleManager checks the right to change roles in a team; the empty team holds
+// the admin role.
+func (s *roleService) requireRoleManager(ctx context.Context, teamName string) error {
+	if teamName != "" {
+		return s.authz.requireTeamManager(ctx, teamName)
+	}
+	return s.authz.requireAdmin(ctx, "only admins can grant the admin role")
+}
+
+type userService struct {
+	service.UserService
+	authz *Authorizer
+}
+
+// NewUserService lets only admins of a user's team change the user.
+func NewUserService(next service.UserService, authz *Authorizer) service.UserService {
+	return &userService{UserService: next, authz: authz}
+}
+
+func (s *userService) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+	if err := s.authz.requireUserManager(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.UserService.SetIsActive(ctx, userID, isActive)
+}
+
+func (s *userService) SetSlackMemberID(ctx context.Context, userID, memberID string) (*domain.User, error) {
+	if err := s.authz.requireUserManager(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.UserService.SetSlackMemberID(ctx, userID, memberID)
+}
+
+func (s *userService) SetEmail(ctx context.Context, userID, email string) (*domain.User, error) {
+	if err := s.authz.requireUserManager(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.UserService.SetEmail(ctx, userID, email)
+}
+
+type reminderService struct {
+	service.ReminderService
+	authz *Authorizer
+}
+
+// NewReminderService lets only admins of a user's team change the user's reminders.
+func NewReminderService(next service.ReminderService, authz *Authorizer) service.ReminderService {
+	return &reminderService{ReminderService: next, authz: authz}
+}
+
+func (s *reminderService) UpdatePreferences(ctx context.Context, prefs *domain.ReminderPreferences) (*domain.ReminderPreferences, error) {
+	if err := s.authz.requireUserManager(ctx, prefs.UserID); err != nil {
+		return nil, err
+	}
+	return s.ReminderService.UpdatePreferences(ctx, prefs)
+}
+
+type constraintService struct {
+	service.ConstraintService
+	authz *Authorizer
+}
+
+// NewConstraintService lets only team admins change the exclusions of their team and
+// the never-assign rules of its authors.
+func NewConstraintService(next service.ConstraintService, authz *Authorizer) service.ConstraintService {
+	return &constraintService{ConstraintService: next, authz: authz}
+}
+
+func (s *constraintService) AddExclusion(ctx context.Context, teamName, userID, otherUserID string) (*domain.ExclusionPair, error) {
+	if err := s.authz.requireTeamManager(ctx, teamName); err != nil {
+		return nil, err
+	}
+	return s.ConstraintService.AddExclusion(ctx, teamName, userID, otherUserID)
+}
+
+func (s *constraintService) RemoveExclusion(ctx context.Context, teamName, userID, otherUserID string) error {
+	if err := s.authz.requireTeamManager(ctx, teamName); err != nil {
+		return err
+	}
+	return s.ConstraintService.RemoveExclusion(ctx, teamName, userID, otherUserID)
+}
+
+func (s *constraintService) AddNeverAssign(ctx context.Context, authorID, reviewerID string) (*domain.NeverAssign, error) {
+	if err := s.authz.requireUserManager(ctx, authorID); err != nil {
+		return nil, err
+	}
+	return s.ConstraintService.AddNeverAssign(ctx, authorID, reviewerID)
+}
+
+func (s *constraintService) RemoveNeverAssign(ctx context.Context, authorID, reviewerID string) error {
+	if err := s.authz.requireUserManager(ctx, authorID); err != nil {
+		return err
+	}
+	return s.ConstraintService.RemoveNeverAssign(ctx, authorID, reviewerID)
+}
+
+type notificationService struct {
+	service.NotificationService
+	authz *Authorizer
+}
+
+// NewNotificationService lets only team admins change the templates of their team.
+func NewNotificationService(next service.NotificationService, authz *Authorizer) service.NotificationService {
+	return &notificationService{NotificationService: next, authz: authz}
+}
+
+func (s *notificationService) SetTemplate(ctx context.Context, tmpl *domain.MessageTemplate) (*domain.MessageTemplate, error) {
+	if err := s.authz.requireTeamManager(ctx, tmpl.TeamName); err != nil {
+		return nil, err
+	}
+	return s.NotificationService.SetTemplate(ctx, tmpl)
+}
+
+type apiKeyService struct {
+	service.APIKeyService
+	authz *Authorizer
+}
+
+// NewAPIKeyService reserves managing API keys to admins: a key acts for the whole
+// tenant, beyond the teams of any team admin.
+func NewAPIKeyService(next service.APIKeyService, authz *Authorizer) service.APIKeyService {
+	return &apiKeyService{APIKeyService: next, authz: authz}
+}
+
+func (s *apiKeyService) CreateKey(ctx context.Context, name string, scopes []domain.Scope) (*domain.APIKey, string, error) {
+	if err := s.authz.requireAdmin(ctx, "only admins can manage API keys"); err != nil {
+		return nil, "", err
+	}
+	return s.APIKeyService.CreateKey(ctx, name, scopes)
+}
+
+func (s *apiKeyService) RotateKey(ctx context.Context, keyID string) (*domain.APIKey, string, error) {
+	if err := s.authz.requireAdmin(ctx, "only admins can manage API keys"); err != nil {
+		return nil, "", err
+	}
+	return s.APIKeyService.RotateKey(ctx, keyID)
+}
+
+func (s *apiKeyService) RevokeKey(ctx context.Context, keyID string) (*domain.APIKey, error) {
+	if err := s.authz.requireAdmin(ctx, "only admins can manage API keys"); err != nil {
+		return nil, err
+	}
+	return s.APIKeyService.RevokeKey(ctx, keyID)
+}
+
+func (s *apiKeyService) ListKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	if err := s.authz.requireAdmin(ctx, "only admins can manage API keys"); err != nil {
+		return nil, err
+	}
+	return s.APIKeyService.ListKeys(ctx)
+}
+
+type auditService struct {
+	service.AuditService
+	authz *Authorizer
+}
+
+// NewAuditService reserves the audit log, which covers every team, to admins.
+func NewAuditService(next service.AuditService, authz *Authorizer) service.AuditService {
+	return &auditService{AuditService: next, authz: authz}
+}
+
+func (s *auditService) ListEntries(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	if err := s.authz.requireAdmin(ctx, "only admins can read the audit log"); err != nil {
+		return nil, err
+	}
+	return s.AuditService.ListEntries(ctx, filter)
+}
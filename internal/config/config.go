@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -11,19 +13,144 @@ type Config struct {
 
 	DatabaseURL string
 
+	// MigrateOnStart applies pending migrations before the service starts. Replicas
+	// starting together take turns, so only the first one migrates.
+	MigrateOnStart bool
+
 	LogLevel string
+
+	DeclineQuota  int
+	DeclinePeriod time.Duration
+
+	// SelectionMode is "random", "deterministic" or "round_robin". SelectionSeed, when
+	// non-zero, seeds the random mode so that a run can be reproduced.
+	SelectionMode string
+	SelectionSeed int64
+
+	// StaleCheckInterval is how often stale reviews are looked for; zero disables the job.
+	StaleCheckInterval    time.Duration
+	StaleMaxReassignments int
+
+	// ReminderCheckInterval is how often review reminders are sent; zero disables the job.
+	ReminderCheckInterval time.Duration
+
+	// NotifyCheckInterval is how often new timeline events are turned into notifications.
+	NotifyCheckInterval time.Duration
+
+	// StreamPollInterval is how often the event log is read for /events/stream.
+	StreamPollInterval time.Duration
+
+	// WSAuthSecret signs the tokens /ws/reviews clients authenticate with. When empty,
+	// the token is taken to be the user ID, which is only fit for development.
+	WSAuthSecret string
+
+	// AuthEnabled requires an API key on every endpoint except /health. AuthBootstrapKey
+	// is accepted as a key with every scope, for creating the first keys.
+	AuthEnabled      bool
+	AuthBootstrapKey string
+
+	// JWT bearer tokens from the company SSO are accepted when JWTJWKSURL is set.
+	// JWTUserClaim holds the internal user ID; JWTAdminRole grants admin:teams.
+	JWTJWKSURL      string
+	JWTIssuers      []string
+	JWTAudience     string
+	JWTUserClaim    string
+	JWTRolesClaim   string
+	JWTTenantClaim  string
+	JWTAdminRole    string
+	JWTJWKSCacheTTL time.Duration
+
+	// RateLimitDefault ("30/m", "100/10s:200") applies to routes without a limit in
+	// RateLimitRoutes ("/pullRequest/create=10/m"). RateLimitShared keeps the buckets in
+	// Postgres so that the limits hold across replicas. RateLimitAddress limits every
+	// client address before authentication, so that guessing credentials is throttled too.
+	RateLimitDefault string
+	RateLimitRoutes  []string
+	RateLimitAddress string
+	RateLimitShared  bool
+
+	// IdempotencyTTL is how long responses to requests with an Idempotency-Key are
+	// kept for replay; zero turns idempotency keys off.
+	IdempotencyTTL time.Duration
+
+	// Slack notifications are enabled by SlackWebhookURL or SlackBotToken.
+	SlackWebhookURL string
+	SlackBotToken   string
+	SlackAPIURL     string
+	SlackChannel    string
+	SlackMaxRetries int
+
+	// Email notifications are enabled by SMTPHost. SMTPTemplateDir may override the
+	// built-in email templates.
+	SMTPHost        string
+	SMTPPort        int
+	SMTPUsername    string
+	SMTPPassword    string
+	SMTPFrom        string
+	SMTPStartTLS    bool
+	SMTPTemplateDir string
 }
 
 func Load() (*Config, error) {
 	cfg := &Config{
-		Port:        getEnv("PORT", "8080"),
-		DatabaseURL: getEnv("DATABASE_URL", ""),
-		LogLevel:    getEnv("LOG_LEVEL", "info"),
+		Port:           getEnv("PORT", "8080"),
+		DatabaseURL:    getEnv("DATABASE_URL", ""),
+		MigrateOnStart: getEnvAsBool("MIGRATE_ON_START", false),
+		LogLevel:       getEnv("LOG_LEVEL", "info"),
+		DeclineQuota:   getEnvAsInt("DECLINE_QUOTA", 3),
+		DeclinePeriod:  getEnvAsDuration("DECLINE_PERIOD", 7*24*time.Hour),
+		SelectionMode:  getEnv("SELECTION_MODE", "random"),
+		SelectionSeed:  int64(getEnvAsInt("SELECTION_SEED", 0)),
+
+		StaleCheckInterval:    getEnvAsDuration("STALE_CHECK_INTERVAL", 5*time.Minute),
+		StaleMaxReassignments: getEnvAsInt("STALE_MAX_REASSIGNMENTS", 2),
+
+		ReminderCheckInterval: getEnvAsDuration("REMINDER_CHECK_INTERVAL", 15*time.Minute),
+		NotifyCheckInterval:   getEnvAsDuration("NOTIFY_CHECK_INTERVAL", 30*time.Second),
+		StreamPollInterval:    getEnvAsDuration("STREAM_POLL_INTERVAL", time.Second),
+
+		WSAuthSecret: getEnv("WS_AUTH_SECRET", ""),
+
+		AuthEnabled:      getEnvAsBool("AUTH_ENABLED", false),
+		AuthBootstrapKey: getEnv("AUTH_BOOTSTRAP_KEY", ""),
+
+		JWTJWKSURL:      getEnv("JWT_JWKS_URL", ""),
+		JWTIssuers:      getEnvAsList("JWT_ISSUERS"),
+		JWTAudience:     getEnv("JWT_AUDIENCE", ""),
+		JWTUserClaim:    getEnv("JWT_USER_CLAIM", "sub"),
+		JWTRolesClaim:   getEnv("JWT_ROLES_CLAIM", "roles"),
+		JWTTenantClaim:  getEnv("JWT_TENANT_CLAIM", "tenant"),
+		JWTAdminRole:    getEnv("JWT_ADMIN_ROLE", "admin"),
+		JWTJWKSCacheTTL: getEnvAsDuration("JWT_JWKS_CACHE_TTL", time.Hour),
+
+		RateLimitDefault: getEnv("RATE_LIMIT_DEFAULT", ""),
+		RateLimitRoutes:  getEnvAsList("RATE_LIMIT_ROUTES"),
+		RateLimitAddress: getEnv("RATE_LIMIT_ADDRESS", ""),
+		RateLimitShared:  getEnvAsBool("RATE_LIMIT_SHARED", false),
+
+		IdempotencyTTL: getEnvAsDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+
+		SlackWebhookURL: getEnv("SLACK_WEBHOOK_URL", ""),
+		SlackBotToken:   getEnv("SLACK_BOT_TOKEN", ""),
+		SlackAPIURL:     getEnv("SLACK_API_URL", "https://slack.com/api"),
+		SlackChannel:    getEnv("SLACK_CHANNEL", ""),
+		SlackMaxRetries: getEnvAsInt("SLACK_MAX_RETRIES", 3),
+
+		SMTPHost:        getEnv("SMTP_HOST", ""),
+		SMTPPort:        getEnvAsInt("SMTP_PORT", 587),
+		SMTPUsername:    getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:    getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:        getEnv("SMTP_FROM", ""),
+		SMTPStartTLS:    getEnvAsBool("SMTP_STARTTLS", true),
+		SMTPTemplateDir: getEnv("SMTP_TEMPLATE_DIR", ""),
 	}
 
 	if cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL is required")
 	}
+	if cfg.JWTJWKSURL != "" && len(cfg.JWTIssuers) == 0 {
+		return nil, fmt.Errorf("JWT_ISSUERS is required with JWT_JWKS_URL")
+	}
 
 	return cfg, nil
 }
@@ -46,3 +173,38 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return value
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvAsList splits a comma-separated variable, dropping empty items.
+func getEnvAsList(key string) []string {
+	var result []string
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
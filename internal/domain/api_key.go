@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Scope is a permission granted to an API key.
+type Scope string
+
+const (
+	ScopeRead       Scope = "read"
+	ScopeWritePR    Scope = "write:pr"
+	ScopeAdminTeams Scope = "admin:teams"
+)
+
+var AllScopes = []Scope{ScopeRead, ScopeWritePR, ScopeAdminTeams}
+
+func ParseScope(s string) (Scope, error) {
+	scope := Scope(s)
+	if !slices.Contains(AllScopes, scope) {
+		return "", fmt.Errorf("unknown scope %q", s)
+	}
+	return scope, nil
+}
+
+// ScopesAllow reports whether the granted scopes permit an operation requiring scope.
+// Any write scope implies read.
+func ScopesAllow(granted []Scope, scope Scope) bool {
+	if slices.Contains(granted, scope) {
+		return true
+	}
+	return scope == ScopeRead && len(granted) > 0
+}
+
+// APIKey describes an issued key; the secret itself is only known to the client.
+type APIKey struct {
+	KeyID string
+	// TenantID is the tenant the key acts for; it is empty for the bootstrap key, which
+	// may act for any tenant.
+	TenantID   string
+	Name       string
+	Scopes     []Scope
+	CreatedAt  time.Time
+	RotatedAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
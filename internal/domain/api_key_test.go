@@ -0,0 +1,36 @@
+package domain
+
+import "testing"
+
+func TestScopesAllow(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []Scope
+		scope    Scope
+		expected bool
+	}{
+		{name: "exact scope", granted: []Scope{ScopeWritePR}, scope: ScopeWritePR, expected: true},
+		{name: "write implies read", granted: []Scope{ScopeWritePR}, scope: ScopeRead, expected: true},
+		{name: "admin implies read", granted: []Scope{ScopeAdminTeams}, scope: ScopeRead, expected: true},
+		{name: "read does not imply write", granted: []Scope{ScopeRead}, scope: ScopeWritePR, expected: false},
+		{name: "write does not imply admin", granted: []Scope{ScopeWritePR}, scope: ScopeAdminTeams, expected: false},
+		{name: "no scopes", granted: nil, scope: ScopeRead, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScopesAllow(tt.granted, tt.scope); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	if s, err := ParseScope("write:pr"); err != nil || s != ScopeWritePR {
+		t.Errorf("expected write:pr, got %q, %v", s, err)
+	}
+	if _, err := ParseScope("write"); err == nil {
+		t.Error("expected unknown scope to be rejected")
+	}
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuditAction names a mutating operation recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditTeamCreate          AuditAction = "team.create"
+	AuditTeamDeactivateUsers AuditAction = "team.deactivate_users"
+	AuditTeamUpdateSettings  AuditAction = "team.update_settings"
+	AuditTeamSetTemplate     AuditAction = "team.set_template"
+	AuditExclusionAdd        AuditAction = "exclusion.add"
+	AuditExclusionRemove     AuditAction = "exclusion.remove"
+	AuditNeverAssignAdd      AuditAction = "never_assign.add"
+	AuditNeverAssignRemove   AuditAction = "never_assign.remove"
+	AuditUserSetActive       AuditAction = "user.set_active"
+	AuditUserSetSlackID      AuditAction = "user.set_slack_member_id"
+	AuditUserSetEmail        AuditAction = "user.set_email"
+	AuditUserSetReminders    AuditAction = "user.set_reminders"
+	AuditPRCreate            AuditAction = "pr.create"
+	AuditPRMerge             AuditAction = "pr.merge"
+	AuditPRReassign          AuditAction = "pr.reassign"
+	AuditPRDecline           AuditAction = "pr.decline"
+	AuditPRReview            AuditAction = "pr.review"
+	AuditPRAddReviewer       AuditAction = "pr.add_reviewer"
+	AuditPRRemoveReviewer    AuditAction = "pr.remove_reviewer"
+	AuditPRSetReviewers      AuditAction = "pr.set_reviewers"
+	AuditAPIKeyCreate        AuditAction = "api_key.create"
+	AuditAPIKeyRotate        AuditAction = "api_key.rotate"
+	AuditAPIKeyRevoke        AuditAction = "api_key.revoke"
+	AuditRoleSet             AuditAction = "role.set"
+	AuditRoleRemove          AuditAction = "role.remove"
+)
+
+// AuditTarget is the kind of object an audited operation changed.
+type AuditTarget string
+
+const (
+	AuditTargetTeam   AuditTarget = "team"
+	AuditTargetUser   AuditTarget = "user"
+	AuditTargetPR     AuditTarget = "pull_request"
+	AuditTargetAPIKey AuditTarget = "api_key"
+)
+
+func ParseAuditTarget(s string) (AuditTarget, error) {
+	switch target := AuditTarget(s); target {
+	case AuditTargetTeam, AuditTargetUser, AuditTargetPR, AuditTargetAPIKey:
+		return target, nil
+	default:
+		return "", fmt.Errorf("invalid audit target: %s", s)
+	}
+}
+
+// AuditEntry records who changed what. Before and After summarise the changed fields
+// and are nil when there was nothing before or nothing is left after. Actor is the
+// signed-in user or the credential of the request, empty when authentication is off.
+type AuditEntry struct {
+	EntryID    int64
+	Actor      string
+	Action     AuditAction
+	TargetType AuditTarget
+	TargetID   string
+	Before     map[string]any
+	After      map[string]any
+	RequestID  string
+	SourceIP   string
+	CreatedAt  time.Time
+}
+
+func NewAuditEntry(action AuditAction, targetType AuditTarget, targetID string) *AuditEntry {
+	return &AuditEntry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		CreatedAt:  time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+// AuditFilter selects audit entries; empty fields match everything. Entries are listed
+// in ID order starting after AfterID.
+type AuditFilter struct {
+	Actor      string
+	Action     AuditAction
+	TargetType AuditTarget
+	TargetID   string
+	From       time.Time
+	To         time.Time
+	AfterID    int64
+	Limit      int
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExclusionPair forbids two members of a team from reviewing each other's pull requests.
+type ExclusionPair struct {
+	TeamName  string
+	UserA     string
+	UserB     string
+	CreatedAt time.Time
+}
+
+// NewExclusionPair stores the pair in a canonical order so (a, b) and (b, a) are the same pair.
+func NewExclusionPair(teamName, userID, otherUserID string) *ExclusionPair {
+	a, b := userID, otherUserID
+	if b < a {
+		a, b = b, a
+	}
+	return &ExclusionPair{
+		TeamName:  teamName,
+		UserA:     a,
+		UserB:     b,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (p *ExclusionPair) Validate() error {
+	if strings.TrimSpace(p.TeamName) == "" {
+		return fmt.Errorf("team_name cannot be empty")
+	}
+	if strings.TrimSpace(p.UserA) == "" || strings.TrimSpace(p.UserB) == "" {
+		return fmt.Errorf("user ids cannot be empty")
+	}
+	if p.UserA == p.UserB {
+		return fmt.Errorf("user ids must differ")
+	}
+	return nil
+}
+
+// NeverAssign forbids a reviewer from being assigned to a particular author's pull requests.
+type NeverAssign struct {
+	AuthorID   string
+	ReviewerID string
+	CreatedAt  time.Time
+}
+
+func (n *NeverAssign) Validate() error {
+	if strings.TrimSpace(n.AuthorID) == "" {
+		return fmt.Errorf("author_id cannot be empty")
+	}
+	if strings.TrimSpace(n.ReviewerID) == "" {
+		return fmt.Errorf("reviewer_id cannot be empty")
+	}
+	if n.AuthorID == n.ReviewerID {
+		return fmt.Errorf("author_id and reviewer_id must differ")
+	}
+	return nil
+}
+
+// ReviewConstraints is the set of exclusion pairs and never-assign entries relevant to a team.
+type ReviewConstraints struct {
+	Pairs       []*ExclusionPair
+	NeverAssign []*NeverAssign
+}
+
+// Allows reports whether reviewerID may review a pull request written by authorID.
+func (c *ReviewConstraints) Allows(authorID, reviewerID string) bool {
+	if authorID == reviewerID {
+		return false
+	}
+	for _, p := range c.Pairs {
+		if (p.UserA == authorID && p.UserB == reviewerID) || (p.UserA == reviewerID && p.UserB == authorID) {
+			return false
+		}
+	}
+	for _, n := range c.NeverAssign {
+		if n.AuthorID == authorID && n.ReviewerID == reviewerID {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckAssignable verifies that every active member who could get a reviewer without
+// constraints still has at least one allowed active reviewer with them.
+func (c *ReviewConstraints) CheckAssignable(members []*User) error {
+	var active []*User
+	for _, m := range members {
+		if m.CanBeReviewer() {
+			active = append(active, m)
+		}
+	}
+	if len(active) < 2 {
+		return nil
+	}
+
+	for _, author := range active {
+		allowed := false
+		for _, reviewer := range active {
+			if c.Allows(author.UserID, reviewer.UserID) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return NewDomainError(ErrCodeUnassignable,
+				fmt.Sprintf("constraints leave no reviewer for %s", author.UserID))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package domain
+
+import "testing"
+
+func TestNewExclusionPair_CanonicalOrder(t *testing.T) {
+	p1 := NewExclusionPair("backend", "u2", "u1")
+	p2 := NewExclusionPair("backend", "u1", "u2")
+
+	if p1.UserA != p2.UserA || p1.UserB != p2.UserB {
+		t.Errorf("pairs should be equal: (%s, %s) != (%s, %s)", p1.UserA, p1.UserB, p2.UserA, p2.UserB)
+	}
+	if p1.UserA != "u1" {
+		t.Errorf("expected user_a u1, got %s", p1.UserA)
+	}
+}
+
+func TestReviewConstraints_Allows(t *testing.T) {
+	c := &ReviewConstraints{
+		Pairs: []*ExclusionPair{
+			NewExclusionPair("backend", "u1", "u2"),
+		},
+		NeverAssign: []*NeverAssign{
+			{AuthorID: "u3", ReviewerID: "u4"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		author   string
+		reviewer string
+		expected bool
+	}{
+		{name: "pair blocks forward", author: "u1", reviewer: "u2", expected: false},
+		{name: "pair blocks reverse", author: "u2", reviewer: "u1", expected: false},
+		{name: "never-assign blocks", author: "u3", reviewer: "u4", expected: false},
+		{name: "never-assign is one way", author: "u4", reviewer: "u3", expected: true},
+		{name: "unrelated users", author: "u1", reviewer: "u3", expected: true},
+		{name: "author cannot review own PR", author: "u1", reviewer: "u1", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Allows(tt.author, tt.reviewer); got != tt.expected {
+				t.Errorf("Allows(%s, %s) = %v, want %v", tt.author, tt.reviewer, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReviewConstraints_CheckAssignable(t *testing.T) {
+	members := []*User{
+		{UserID: "u1", IsActive: true},
+		{UserID: "u2", IsActive: true},
+		{UserID: "u3", IsActive: true},
+		{UserID: "u4", IsActive: false},
+	}
+
+	tests := []struct {
+		name        string
+		constraints *ReviewConstraints
+		wantErr     bool
+	}{
+		{
+			name:        "no constraints",
+			constraints: &ReviewConstraints{},
+			wantErr:     false,
+		},
+		{
+			name: "one pair leaves a reviewer",
+			constraints: &ReviewConstraints{
+				Pairs: []*ExclusionPair{NewExclusionPair("t", "u1", "u2")},
+			},
+			wantErr: false,
+		},
+		{
+			name: "author isolated by pairs",
+			constraints: &ReviewConstraints{
+				Pairs: []*ExclusionPair{
+					NewExclusionPair("t", "u1", "u2"),
+					NewExclusionPair("t", "u1", "u3"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "author isolated by pair and never-assign",
+			constraints: &ReviewConstraints{
+				Pairs:       []*ExclusionPair{NewExclusionPair("t", "u1", "u2")},
+				NeverAssign: []*NeverAssign{{AuthorID: "u1", ReviewerID: "u3"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "inactive member does not count as reviewer",
+			constraints: &ReviewConstraints{
+				NeverAssign: []*NeverAssign{
+					{AuthorID: "u2", ReviewerID: "u1"},
+					{AuthorID: "u2", ReviewerID: "u3"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.constraints.CheckAssignable(members)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckAssignable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
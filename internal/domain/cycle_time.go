@@ -0,0 +1,240 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// CycleTimeDimension selects how cycle-time metrics are broken down.
+type CycleTimeDimension string
+
+const (
+	CycleByTeam       CycleTimeDimension = "team"
+	CycleByRepository CycleTimeDimension = "repository"
+	CycleByReviewer   CycleTimeDimension = "reviewer"
+)
+
+// ParseCycleTimeDimension defaults to a per-team breakdown.
+func ParseCycleTimeDimension(raw string) (CycleTimeDimension, bool) {
+	switch d := CycleTimeDimension(raw); d {
+	case "":
+		return CycleByTeam, true
+	case CycleByTeam, CycleByRepository, CycleByReviewer:
+		return d, true
+	default:
+		return "", false
+	}
+}
+
+// ReviewerCycle is one reviewer's part in a pull request, measured from the moment
+// they were first assigned.
+type ReviewerCycle struct {
+	UserID        string
+	AssignedAt    time.Time
+	FirstReviewAt *time.Time
+	ApprovedAt    *time.Time
+	// Reassignments counts how many times the review was taken away from this reviewer.
+	Reassignments int
+}
+
+// PRCycleTime holds the milestones of a pull request reconstructed from its timeline.
+type PRCycleTime struct {
+	PRID          string
+	TeamName      string
+	Repository    string
+	CreatedAt     time.Time
+	FirstReviewAt *time.Time
+	ApprovedAt    *time.Time
+	MergedAt      *time.Time
+	Reassignments int
+	Reviewers     []*ReviewerCycle
+}
+
+// NewPRCycleTime replays the events of a pull request, which must be ordered by EventID.
+// teamName is the author's team.
+func NewPRCycleTime(pr *PullRequest, teamName string, events []*PREvent) *PRCycleTime {
+	c := &PRCycleTime{
+		PRID:       pr.PullRequestID,
+		TeamName:   teamName,
+		Repository: pr.Repository,
+		CreatedAt:  pr.CreatedAt,
+		MergedAt:   pr.MergedAt,
+	}
+
+	reviewers := make(map[string]*ReviewerCycle)
+	assign := func(userID string, at time.Time) {
+		if _, ok := reviewers[userID]; ok {
+			return
+		}
+		rc := &ReviewerCycle{UserID: userID, AssignedAt: at}
+		reviewers[userID] = rc
+		c.Reviewers = append(c.Reviewers, rc)
+	}
+
+	for _, e := range events {
+		switch e.Type {
+		case PREventReviewerAssigned:
+			assign(e.UserID, e.CreatedAt)
+		case PREventReviewerReassigned, PREventReviewerDeclined:
+			if e.ReplacedBy == "" {
+				continue
+			}
+			c.Reassignments++
+			if rc, ok := reviewers[e.UserID]; ok {
+				rc.Reassignments++
+			}
+			assign(e.ReplacedBy, e.CreatedAt)
+		case PREventReviewSubmitted:
+			at := e.CreatedAt
+			if c.FirstReviewAt == nil {
+				c.FirstReviewAt = &at
+			}
+			if ReviewVerdict(e.Reason) == ReviewApproved && c.ApprovedAt == nil {
+				c.ApprovedAt = &at
+			}
+			rc, ok := reviewers[e.UserID]
+			if !ok {
+				continue
+			}
+			if rc.FirstReviewAt == nil {
+				rc.FirstReviewAt = &at
+			}
+			if ReviewVerdict(e.Reason) == ReviewApproved && rc.ApprovedAt == nil {
+				rc.ApprovedAt = &at
+			}
+		}
+	}
+
+	return c
+}
+
+// Percentiles uses the nearest-rank method. All fields are zero when Count is zero.
+type Percentiles struct {
+	Count int
+	P50   float64
+	P90   float64
+	P99   float64
+}
+
+func ComputePercentiles(values []float64) Percentiles {
+	if len(values) == 0 {
+		return Percentiles{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := func(p float64) float64 {
+		idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		return sorted[idx]
+	}
+
+	return Percentiles{
+		Count: len(sorted),
+		P50:   rank(50),
+		P90:   rank(90),
+		P99:   rank(99),
+	}
+}
+
+// CycleTimeSummary aggregates durations in hours. Pull requests that have not reached a
+// milestone yet are left out of that milestone's percentiles.
+type CycleTimeSummary struct {
+	PullRequests      int
+	TimeToFirstReview Percentiles
+	TimeToApproval    Percentiles
+	TimeToMerge       Percentiles
+	Reassignments     Percentiles
+}
+
+type CycleTimeGroup struct {
+	Key string
+	CycleTimeSummary
+}
+
+type CycleTimeReport struct {
+	Overall CycleTimeSummary
+	Groups  []CycleTimeGroup
+}
+
+// SummarizeCycleTimes aggregates all pull requests and breaks them down by dimension.
+// In the per-reviewer breakdown durations start at the reviewer's assignment, and a
+// pull request without a repository is left out of the per-repository one.
+func SummarizeCycleTimes(items []*PRCycleTime, by CycleTimeDimension) *CycleTimeReport {
+	var overall cycleSamples
+	groups := make(map[string]*cycleSamples)
+	group := func(key string) *cycleSamples {
+		g, ok := groups[key]
+		if !ok {
+			g = &cycleSamples{}
+			groups[key] = g
+		}
+		return g
+	}
+
+	for _, c := range items {
+		overall.add(c.CreatedAt, c.FirstReviewAt, c.ApprovedAt, c.MergedAt, c.Reassignments)
+
+		switch by {
+		case CycleByTeam:
+			group(c.TeamName).add(c.CreatedAt, c.FirstReviewAt, c.ApprovedAt, c.MergedAt, c.Reassignments)
+		case CycleByRepository:
+			if c.Repository != "" {
+				group(c.Repository).add(c.CreatedAt, c.FirstReviewAt, c.ApprovedAt, c.MergedAt, c.Reassignments)
+			}
+		case CycleByReviewer:
+			for _, rc := range c.Reviewers {
+				group(rc.UserID).add(rc.AssignedAt, rc.FirstReviewAt, rc.ApprovedAt, c.MergedAt, rc.Reassignments)
+			}
+		}
+	}
+
+	report := &CycleTimeReport{
+		Overall: overall.summary(),
+		Groups:  make([]CycleTimeGroup, 0, len(groups)),
+	}
+	for key, g := range groups {
+		report.Groups = append(report.Groups, CycleTimeGroup{Key: key, CycleTimeSummary: g.summary()})
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Key < report.Groups[j].Key
+	})
+
+	return report
+}
+
+type cycleSamples struct {
+	count         int
+	firstReview   []float64
+	approval      []float64
+	merge         []float64
+	reassignments []float64
+}
+
+func (s *cycleSamples) add(start time.Time, firstReview, approval, merged *time.Time, reassignments int) {
+	s.count++
+	if firstReview != nil {
+		s.firstReview = append(s.firstReview, firstReview.Sub(start).Hours())
+	}
+	if approval != nil {
+		s.approval = append(s.approval, approval.Sub(start).Hours())
+	}
+	if merged != nil {
+		s.merge = append(s.merge, merged.Sub(start).Hours())
+	}
+	s.reassignments = append(s.reassignments, float64(reassignments))
+}
+
+func (s *cycleSamples) summary() CycleTimeSummary {
+	return CycleTimeSummary{
+		PullRequests:      s.count,
+		TimeToFirstReview: ComputePercentiles(s.firstReview),
+		TimeToApproval:    ComputePercentiles(s.approval),
+		TimeToMerge:       ComputePercentiles(s.merge),
+		Reassignments:     ComputePercentiles(s.reassignments),
+	}
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputePercentiles(t *testing.T) {
+	values := make([]float64, 0, 100)
+	for i := 100; i >= 1; i-- {
+		values = append(values, float64(i))
+	}
+
+	p := ComputePercentiles(values)
+	if p.Count != 100 {
+		t.Errorf("expected count 100, got %d", p.Count)
+	}
+	if p.P50 != 50 || p.P90 != 90 || p.P99 != 99 {
+		t.Errorf("expected 50/90/99, got %v/%v/%v", p.P50, p.P90, p.P99)
+	}
+
+	if empty := ComputePercentiles(nil); empty != (Percentiles{}) {
+		t.Errorf("expected zero percentiles, got %+v", empty)
+	}
+
+	single := ComputePercentiles([]float64{3})
+	if single.P50 != 3 || single.P99 != 3 {
+		t.Errorf("expected single value everywhere, got %+v", single)
+	}
+}
+
+func TestNewPRCycleTime(t *testing.T) {
+	created := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return created.Add(time.Duration(h) * time.Hour) }
+	merged := at(30)
+
+	pr := &PullRequest{PullRequestID: "pr-1", Repository: "api", CreatedAt: created, MergedAt: &merged}
+	events := []*PREvent{
+		{Type: PREventCreated, CreatedAt: at(0)},
+		{Type: PREventReviewerAssigned, UserID: "u2", CreatedAt: at(0)},
+		{Type: PREventReviewerAssigned, UserID: "u3", CreatedAt: at(0)},
+		{Type: PREventReviewerDeclined, UserID: "u3", ReplacedBy: "u4", CreatedAt: at(2)},
+		{Type: PREventReviewSubmitted, UserID: "u2", Reason: string(ReviewChangesRequested), CreatedAt: at(4)},
+		{Type: PREventReviewSubmitted, UserID: "u4", Reason: string(ReviewApproved), CreatedAt: at(10)},
+		{Type: PREventReviewSubmitted, UserID: "u2", Reason: string(ReviewApproved), CreatedAt: at(12)},
+		{Type: PREventMerged, CreatedAt: merged},
+	}
+
+	c := NewPRCycleTime(pr, "backend", events)
+
+	if c.FirstReviewAt == nil || !c.FirstReviewAt.Equal(at(4)) {
+		t.Errorf("expected first review at +4h, got %v", c.FirstReviewAt)
+	}
+	if c.ApprovedAt == nil || !c.ApprovedAt.Equal(at(10)) {
+		t.Errorf("expected approval at +10h, got %v", c.ApprovedAt)
+	}
+	if c.Reassignments != 1 {
+		t.Errorf("expected 1 reassignment, got %d", c.Reassignments)
+	}
+	if len(c.Reviewers) != 3 {
+		t.Fatalf("expected 3 reviewers, got %d", len(c.Reviewers))
+	}
+
+	byUser := make(map[string]*ReviewerCycle)
+	for _, rc := range c.Reviewers {
+		byUser[rc.UserID] = rc
+	}
+	if byUser["u3"].Reassignments != 1 || byUser["u3"].FirstReviewAt != nil {
+		t.Errorf("u3 should have been moved away before reviewing: %+v", byUser["u3"])
+	}
+	if !byUser["u4"].AssignedAt.Equal(at(2)) {
+		t.Errorf("u4 should be assigned when taking over, got %v", byUser["u4"].AssignedAt)
+	}
+	if byUser["u2"].ApprovedAt == nil || !byUser["u2"].ApprovedAt.Equal(at(12)) {
+		t.Errorf("expected u2 approval at +12h, got %v", byUser["u2"].ApprovedAt)
+	}
+}
+
+func TestSummarizeCycleTimes(t *testing.T) {
+	created := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	hours := func(h int) *time.Time {
+		t := created.Add(time.Duration(h) * time.Hour)
+		return &t
+	}
+
+	items := []*PRCycleTime{
+		{
+			PRID: "pr-1", TeamName: "backend", Repository: "api", CreatedAt: created,
+			FirstReviewAt: hours(2), ApprovedAt: hours(4), MergedAt: hours(6),
+			Reviewers: []*ReviewerCycle{{UserID: "u2", AssignedAt: created, FirstReviewAt: hours(2)}},
+		},
+		{
+			PRID: "pr-2", TeamName: "backend", CreatedAt: created,
+			FirstReviewAt: hours(8), Reassignments: 2,
+			Reviewers: []*ReviewerCycle{{UserID: "u2", AssignedAt: *hours(1), FirstReviewAt: hours(8)}},
+		},
+		{
+			PRID: "pr-3", TeamName: "frontend", Repository: "web", CreatedAt: created,
+		},
+	}
+
+	t.Run("by team", func(t *testing.T) {
+		report := SummarizeCycleTimes(items, CycleByTeam)
+
+		if report.Overall.PullRequests != 3 {
+			t.Errorf("expected 3 PRs overall, got %d", report.Overall.PullRequests)
+		}
+		if report.Overall.TimeToFirstReview.Count != 2 || report.Overall.TimeToMerge.Count != 1 {
+			t.Errorf("unreached milestones should be skipped: %+v", report.Overall)
+		}
+		if len(report.Groups) != 2 || report.Groups[0].Key != "backend" || report.Groups[1].Key != "frontend" {
+			t.Fatalf("unexpected groups: %+v", report.Groups)
+		}
+		if report.Groups[0].Reassignments.P99 != 2 {
+			t.Errorf("expected p99 reassignments 2, got %v", report.Groups[0].Reassignments.P99)
+		}
+	})
+
+	t.Run("by repository skips unknown", func(t *testing.T) {
+		report := SummarizeCycleTimes(items, CycleByRepository)
+		if len(report.Groups) != 2 || report.Groups[0].Key != "api" || report.Groups[1].Key != "web" {
+			t.Errorf("unexpected groups: %+v", report.Groups)
+		}
+	})
+
+	t.Run("by reviewer measures from assignment", func(t *testing.T) {
+		report := SummarizeCycleTimes(items, CycleByReviewer)
+		if len(report.Groups) != 1 || report.Groups[0].Key != "u2" {
+			t.Fatalf("unexpected groups: %+v", report.Groups)
+		}
+		first := report.Groups[0].TimeToFirstReview
+		if first.Count != 2 || first.P50 != 2 || first.P99 != 7 {
+			t.Errorf("expected first review 2h and 7h, got %+v", first)
+		}
+	})
+}
@@ -11,6 +11,27 @@ const (
 	ErrCodeNotAssigned ErrorCode = "NOT_ASSIGNED"
 	ErrCodeNoCandidate ErrorCode = "NO_CANDIDATE"
 	ErrCodeNotFound    ErrorCode = "NOT_FOUND"
+
+	ErrCodeUnassignable  ErrorCode = "TEAM_UNASSIGNABLE"
+	ErrCodeNotTeamMember ErrorCode = "NOT_TEAM_MEMBER"
+
+	ErrCodeDeclineQuotaExceeded ErrorCode = "DECLINE_QUOTA_EXCEEDED"
+
+	ErrCodeInvalidReviewer  ErrorCode = "INVALID_REVIEWER"
+	ErrCodeAlreadyAssigned  ErrorCode = "ALREADY_ASSIGNED"
+	ErrCodeTooManyReviewers ErrorCode = "TOO_MANY_REVIEWERS"
+
+	ErrCodeInvalidSettings ErrorCode = "INVALID_SETTINGS"
+
+	ErrCodeUnauthorized  ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden     ErrorCode = "FORBIDDEN"
+	ErrCodeAPIKeyRevoked ErrorCode = "API_KEY_REVOKED"
+	ErrCodeInvalidRole   ErrorCode = "INVALID_ROLE"
+
+	ErrCodeTenantExists  ErrorCode = "TENANT_EXISTS"
+	ErrCodeInvalidTenant ErrorCode = "INVALID_TENANT"
+
+	ErrCodeVersionMismatch ErrorCode = "VERSION_MISMATCH"
 )
 
 type DomainError struct {
@@ -38,4 +59,29 @@ var (
 	ErrTeamNotFound = &DomainError{Code: ErrCodeNotFound, Message: "team not found"}
 	ErrUserNotFound = &DomainError{Code: ErrCodeNotFound, Message: "user not found"}
 	ErrPRNotFound   = &DomainError{Code: ErrCodeNotFound, Message: "pull request not found"}
+
+	ErrNotTeamMember      = &DomainError{Code: ErrCodeNotTeamMember, Message: "user is not a member of the team"}
+	ErrExclusionNotFound  = &DomainError{Code: ErrCodeNotFound, Message: "exclusion pair not found"}
+	ErrNeverAssignMissing = &DomainError{Code: ErrCodeNotFound, Message: "never-assign entry not found"}
+
+	ErrDeclineQuotaExceeded = &DomainError{Code: ErrCodeDeclineQuotaExceeded, Message: "decline quota for the current period is exhausted"}
+
+	ErrReviewerIsAuthor   = &DomainError{Code: ErrCodeInvalidReviewer, Message: "author cannot review own pull request"}
+	ErrReviewerInactive   = &DomainError{Code: ErrCodeInvalidReviewer, Message: "reviewer is not active"}
+	ErrReviewerOtherTeam  = &DomainError{Code: ErrCodeInvalidReviewer, Message: "reviewer is not in the author's team"}
+	ErrReviewerNotAllowed = &DomainError{Code: ErrCodeInvalidReviewer, Message: "reviewer is excluded for this author"}
+	ErrDuplicateReviewer  = &DomainError{Code: ErrCodeInvalidReviewer, Message: "reviewer listed more than once"}
+	ErrAlreadyAssigned    = &DomainError{Code: ErrCodeAlreadyAssigned, Message: "user is already assigned as reviewer"}
+	ErrTooManyReviewers   = &DomainError{Code: ErrCodeTooManyReviewers, Message: "pull request cannot have more reviewers"}
+
+	ErrUnauthorized   = &DomainError{Code: ErrCodeUnauthorized, Message: "missing or invalid credentials"}
+	ErrForbidden      = &DomainError{Code: ErrCodeForbidden, Message: "insufficient permissions"}
+	ErrAPIKeyNotFound = &DomainError{Code: ErrCodeNotFound, Message: "API key not found"}
+	ErrAPIKeyRevoked  = &DomainError{Code: ErrCodeAPIKeyRevoked, Message: "API key is revoked"}
+	ErrRoleNotFound   = &DomainError{Code: ErrCodeNotFound, Message: "role assignment not found"}
+
+	ErrTenantExists   = &DomainError{Code: ErrCodeTenantExists, Message: "tenant already exists"}
+	ErrTenantNotFound = &DomainError{Code: ErrCodeNotFound, Message: "tenant not found"}
+
+	ErrVersionMismatch = &DomainError{Code: ErrCodeVersionMismatch, Message: "resource was changed since the given version"}
 )
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type PREventType string
+
+const (
+	PREventCreated            PREventType = "CREATED"
+	PREventReviewerAssigned   PREventType = "REVIEWER_ASSIGNED"
+	PREventReviewerReassigned PREventType = "REVIEWER_REASSIGNED"
+	PREventReviewerDeclined   PREventType = "REVIEWER_DECLINED"
+	PREventReviewerRemoved    PREventType = "REVIEWER_REMOVED"
+	PREventReviewSubmitted    PREventType = "REVIEW_SUBMITTED"
+	PREventMerged             PREventType = "MERGED"
+)
+
+// ManualReason marks timeline events caused by an explicit reviewer override.
+const ManualReason = "MANUAL"
+
+// PREvent is one entry of a pull request timeline. UserID is the reviewer the event
+// is about and ReplacedBy is set when another reviewer took their place.
+type PREvent struct {
+	EventID    int64
+	PRID       string
+	Type       PREventType
+	ActorID    string
+	UserID     string
+	ReplacedBy string
+	Reason     string
+	Comment    string
+	CreatedAt  time.Time
+}
+
+func NewPREvent(prID string, eventType PREventType) *PREvent {
+	return &PREvent{
+		PRID:      prID,
+		Type:      eventType,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+// FeedEvent is a timeline event with the tenant, author and team of its pull request,
+// as delivered to live subscribers.
+type FeedEvent struct {
+	*PREvent
+	TenantID string
+	AuthorID string
+	TeamName string
+}
+
+// EventFilter selects feed events of one tenant; other empty fields match everything.
+// UserID matches events the user took part in and events of pull requests the user
+// authored.
+type EventFilter struct {
+	TenantID string
+	TeamName string
+	UserID   string
+	PRID     string
+}
+
+func (f EventFilter) Matches(e *FeedEvent) bool {
+	if e.TenantID != f.TenantID {
+		return false
+	}
+	if f.TeamName != "" && e.TeamName != f.TeamName {
+		return false
+	}
+	if f.PRID != "" && e.PRID != f.PRID {
+		return false
+	}
+	if f.UserID != "" {
+		switch f.UserID {
+		case e.UserID, e.ReplacedBy, e.ActorID, e.AuthorID:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type DeclineReason string
+
+const (
+	DeclineReasonBusy      DeclineReason = "BUSY"
+	DeclineReasonNoContext DeclineReason = "NO_CONTEXT"
+	DeclineReasonConflict  DeclineReason = "CONFLICT"
+)
+
+func (r DeclineReason) IsValid() bool {
+	return r == DeclineReasonBusy || r == DeclineReasonNoContext || r == DeclineReasonConflict
+}
+
+func ParseDeclineReason(s string) (DeclineReason, error) {
+	reason := DeclineReason(strings.ToUpper(strings.TrimSpace(s)))
+	if !reason.IsValid() {
+		return "", fmt.Errorf("invalid decline reason: %s", s)
+	}
+	return reason, nil
+}
+
+// DeclineStat summarises how often a reviewer declined the reviews assigned to them.
+type DeclineStat struct {
+	UserID      string
+	Assignments int
+	Declines    int
+	ByReason    map[DeclineReason]int
+}
+
+func (s *DeclineStat) Rate() float64 {
+	if s.Assignments == 0 {
+		return 0
+	}
+	return float64(s.Declines) / float64(s.Assignments)
+}
+
+// ReviewVerdict is the outcome of a submitted review, stored as the event reason.
+type ReviewVerdict string
+
+const (
+	ReviewApproved         ReviewVerdict = "APPROVED"
+	ReviewCommented        ReviewVerdict = "COMMENTED"
+	ReviewChangesRequested ReviewVerdict = "CHANGES_REQUESTED"
+)
+
+func (v ReviewVerdict) IsValid() bool {
+	return v == ReviewApproved || v == ReviewCommented || v == ReviewChangesRequested
+}
+
+func ParseReviewVerdict(s string) (ReviewVerdict, error) {
+	verdict := ReviewVerdict(strings.ToUpper(strings.TrimSpace(s)))
+	if !verdict.IsValid() {
+		return "", fmt.Errorf("invalid review verdict: %s", s)
+	}
+	return verdict, nil
+}
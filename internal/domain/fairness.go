@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ActivityChange records a user's is_active flag switching at a point in time.
+type ActivityChange struct {
+	UserID    string
+	IsActive  bool
+	ChangedAt time.Time
+}
+
+// ActiveDuration returns how long the user was active within [from, to). Changes must
+// belong to a single user and be sorted by ChangedAt; the user is considered inactive
+// before the first change.
+func ActiveDuration(changes []ActivityChange, from, to time.Time) time.Duration {
+	var total time.Duration
+	active := false
+	since := from
+
+	for _, c := range changes {
+		if !c.ChangedAt.After(from) {
+			active = c.IsActive
+			continue
+		}
+		if !c.ChangedAt.Before(to) {
+			break
+		}
+		if active && !c.IsActive {
+			total += c.ChangedAt.Sub(since)
+		}
+		if !active && c.IsActive {
+			since = c.ChangedAt
+		}
+		active = c.IsActive
+	}
+
+	if active {
+		total += to.Sub(since)
+	}
+
+	return total
+}
+
+// MemberLoad is one reviewer's assignment count and availability within a window.
+type MemberLoad struct {
+	UserID      string
+	ActiveDays  float64
+	Assignments int
+}
+
+type MemberFairness struct {
+	MemberLoad
+	ExpectedShare float64
+	ActualShare   float64
+}
+
+// FairnessReport summarises how evenly assignments were spread across a team.
+// MaxMinRatio is nil when some member received no assignments.
+type FairnessReport struct {
+	Members          []MemberFairness
+	TotalAssignments int
+	MeanAssignments  float64
+	StdDev           float64
+	Gini             float64
+	MaxMinRatio      *float64
+}
+
+// ComputeFairness builds a report over members that were active at some point in the
+// window. A member's expected share is proportional to the days they were active.
+func ComputeFairness(loads []MemberLoad) *FairnessReport {
+	report := &FairnessReport{}
+
+	var totalDays float64
+	var members []MemberLoad
+	for _, l := range loads {
+		if l.ActiveDays <= 0 {
+			continue
+		}
+		members = append(members, l)
+		totalDays += l.ActiveDays
+		report.TotalAssignments += l.Assignments
+	}
+
+	if len(members) == 0 {
+		return report
+	}
+
+	counts := make([]float64, len(members))
+	for i, m := range members {
+		counts[i] = float64(m.Assignments)
+
+		mf := MemberFairness{MemberLoad: m, ExpectedShare: m.ActiveDays / totalDays}
+		if report.TotalAssignments > 0 {
+			mf.ActualShare = float64(m.Assignments) / float64(report.TotalAssignments)
+		}
+		report.Members = append(report.Members, mf)
+	}
+
+	n := float64(len(counts))
+	report.MeanAssignments = float64(report.TotalAssignments) / n
+
+	var variance float64
+	for _, c := range counts {
+		variance += (c - report.MeanAssignments) * (c - report.MeanAssignments)
+	}
+	report.StdDev = math.Sqrt(variance / n)
+
+	report.Gini = gini(counts)
+
+	sort.Float64s(counts)
+	if counts[0] > 0 {
+		ratio := counts[len(counts)-1] / counts[0]
+		report.MaxMinRatio = &ratio
+	}
+
+	sort.Slice(report.Members, func(i, j int) bool {
+		return report.Members[i].UserID < report.Members[j].UserID
+	})
+
+	return report
+}
+
+// gini returns the Gini coefficient: 0 for a perfectly even spread, approaching 1 when
+// one member gets everything.
+func gini(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var sum, weighted float64
+	for i, v := range sorted {
+		sum += v
+		weighted += float64(i+1) * v
+	}
+	if sum == 0 {
+		return 0
+	}
+
+	n := float64(len(sorted))
+	return (2*weighted)/(n*sum) - (n+1)/n
+}
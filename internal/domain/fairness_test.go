@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestActiveDuration(t *testing.T) {
+	day := 24 * time.Hour
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(10 * day)
+
+	tests := []struct {
+		name     string
+		changes  []ActivityChange
+		expected time.Duration
+	}{
+		{
+			name:     "no history",
+			changes:  nil,
+			expected: 0,
+		},
+		{
+			name:     "active before window",
+			changes:  []ActivityChange{{IsActive: true, ChangedAt: from.Add(-day)}},
+			expected: 10 * day,
+		},
+		{
+			name:     "joined mid window",
+			changes:  []ActivityChange{{IsActive: true, ChangedAt: from.Add(4 * day)}},
+			expected: 6 * day,
+		},
+		{
+			name: "deactivated and reactivated",
+			changes: []ActivityChange{
+				{IsActive: true, ChangedAt: from.Add(-day)},
+				{IsActive: false, ChangedAt: from.Add(2 * day)},
+				{IsActive: true, ChangedAt: from.Add(5 * day)},
+			},
+			expected: 7 * day,
+		},
+		{
+			name: "deactivated before window",
+			changes: []ActivityChange{
+				{IsActive: true, ChangedAt: from.Add(-5 * day)},
+				{IsActive: false, ChangedAt: from.Add(-day)},
+			},
+			expected: 0,
+		},
+		{
+			name: "change after window is ignored",
+			changes: []ActivityChange{
+				{IsActive: true, ChangedAt: from.Add(-day)},
+				{IsActive: false, ChangedAt: to.Add(day)},
+			},
+			expected: 10 * day,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ActiveDuration(tt.changes, from, to); got != tt.expected {
+				t.Errorf("ActiveDuration() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestComputeFairness_Even(t *testing.T) {
+	report := ComputeFairness([]MemberLoad{
+		{UserID: "u1", ActiveDays: 10, Assignments: 5},
+		{UserID: "u2", ActiveDays: 10, Assignments: 5},
+	})
+
+	if report.StdDev != 0 {
+		t.Errorf("expected stddev 0, got %f", report.StdDev)
+	}
+	if report.Gini != 0 {
+		t.Errorf("expected gini 0, got %f", report.Gini)
+	}
+	if report.MaxMinRatio == nil || *report.MaxMinRatio != 1 {
+		t.Errorf("expected max/min ratio 1, got %v", report.MaxMinRatio)
+	}
+	for _, m := range report.Members {
+		if m.ExpectedShare != 0.5 || m.ActualShare != 0.5 {
+			t.Errorf("unexpected shares for %s: %+v", m.UserID, m)
+		}
+	}
+}
+
+func TestComputeFairness_Skewed(t *testing.T) {
+	report := ComputeFairness([]MemberLoad{
+		{UserID: "u1", ActiveDays: 10, Assignments: 9},
+		{UserID: "u2", ActiveDays: 5, Assignments: 1},
+		{UserID: "u3", ActiveDays: 5, Assignments: 0},
+		{UserID: "u4", ActiveDays: 0, Assignments: 0},
+	})
+
+	if len(report.Members) != 3 {
+		t.Fatalf("members never active in the window should be skipped, got %d", len(report.Members))
+	}
+	if report.TotalAssignments != 10 {
+		t.Errorf("expected 10 assignments, got %d", report.TotalAssignments)
+	}
+	if report.MaxMinRatio != nil {
+		t.Errorf("max/min ratio should be undefined when a member has no assignments")
+	}
+	if math.Abs(report.Gini-0.6) > 0.001 {
+		t.Errorf("expected gini ~0.6, got %f", report.Gini)
+	}
+	if report.Members[0].UserID != "u1" || report.Members[0].ExpectedShare != 0.5 || report.Members[0].ActualShare != 0.9 {
+		t.Errorf("unexpected u1 fairness: %+v", report.Members[0])
+	}
+}
@@ -0,0 +1,17 @@
+package domain
+
+// IdempotencyRecord is what is kept of the first request made with an idempotency key:
+// a fingerprint of the request and, once it has been handled, its response.
+type IdempotencyRecord struct {
+	Fingerprint string
+	// StatusCode is zero while the first request is still being handled.
+	StatusCode  int
+	ContentType string
+	// ETag carries the version of the pull request or team the response describes.
+	ETag string
+	Body []byte
+}
+
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
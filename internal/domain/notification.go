@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NotificationChannel names the way a user wants to be notified.
+type NotificationChannel string
+
+const (
+	// ChannelLog only writes notifications to the service log; it is always available.
+	ChannelLog   NotificationChannel = "log"
+	ChannelSlack NotificationChannel = "slack"
+	ChannelEmail NotificationChannel = "email"
+)
+
+type NotificationKind string
+
+const (
+	NotificationReviewReminder   NotificationKind = "REVIEW_REMINDER"
+	NotificationReviewerAssigned NotificationKind = "REVIEWER_ASSIGNED"
+	NotificationReviewerReplaced NotificationKind = "REVIEWER_REPLACED"
+	NotificationPRMerged         NotificationKind = "PR_MERGED"
+)
+
+func (k NotificationKind) IsValid() bool {
+	switch k {
+	case NotificationReviewReminder, NotificationReviewerAssigned, NotificationReviewerReplaced, NotificationPRMerged:
+		return true
+	}
+	return false
+}
+
+func ParseNotificationKind(s string) (NotificationKind, error) {
+	kind := NotificationKind(strings.ToUpper(strings.TrimSpace(s)))
+	if !kind.IsValid() {
+		return "", fmt.Errorf("invalid notification kind: %s", s)
+	}
+	return kind, nil
+}
+
+// MessageTemplate overrides the chat message a team receives for one kind of notification.
+// Body is a text/template.
+type MessageTemplate struct {
+	TeamName string
+	Kind     NotificationKind
+	Body     string
+}
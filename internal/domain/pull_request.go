@@ -6,14 +6,20 @@ import (
 	"time"
 )
 
+// MaxReviewers is the upper bound on reviewers assigned to a single pull request.
+const MaxReviewers = 2
+
 type PullRequest struct {
 	PullRequestID     string
 	PullRequestName   string
 	AuthorID          string
+	Repository        string
 	Status            PRStatus
 	AssignedReviewers []string
 	CreatedAt         time.Time
 	MergedAt          *time.Time
+	// Version grows with every change to the pull request or its reviewers.
+	Version int64
 }
 
 func (pr *PullRequest) Validate() error {
@@ -29,8 +35,8 @@ func (pr *PullRequest) Validate() error {
 	if !pr.Status.IsValid() {
 		return fmt.Errorf("invalid status: %s", pr.Status)
 	}
-	if len(pr.AssignedReviewers) > 2 {
-		return fmt.Errorf("cannot have more than 2 reviewers")
+	if len(pr.AssignedReviewers) > MaxReviewers {
+		return fmt.Errorf("cannot have more than %d reviewers", MaxReviewers)
 	}
 	return nil
 }
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+const defaultReminderFrequency = 24 * time.Hour
+
+// ReminderPreferences controls review reminders of one user. Frequency is both how often
+// a digest may be sent (see DigestDue) and how long a pull request is not repeated in
+// later digests.
+// Quiet hours are minutes after midnight in the user's team time zone; QuietStart after
+// QuietEnd spans midnight and equal values mean no quiet hours.
+type ReminderPreferences struct {
+	UserID     string
+	Enabled    bool
+	Frequency  time.Duration
+	QuietStart int
+	QuietEnd   int
+	Channel    NotificationChannel
+}
+
+func DefaultReminderPreferences(userID string) *ReminderPreferences {
+	return &ReminderPreferences{
+		UserID:    userID,
+		Enabled:   true,
+		Frequency: defaultReminderFrequency,
+		Channel:   ChannelLog,
+	}
+}
+
+func (p *ReminderPreferences) Validate() error {
+	if p.Frequency < time.Minute {
+		return NewDomainError(ErrCodeInvalidSettings, "reminder frequency must be at least one minute")
+	}
+	if p.QuietStart < 0 || p.QuietStart >= 24*60 || p.QuietEnd < 0 || p.QuietEnd >= 24*60 {
+		return NewDomainError(ErrCodeInvalidSettings, "quiet hours must be within a day")
+	}
+	if p.Channel == "" {
+		return NewDomainError(ErrCodeInvalidSettings, fmt.Sprintf("channel is required for user %s", p.UserID))
+	}
+	return nil
+}
+
+// IsQuiet reports whether t falls into the user's quiet hours in loc.
+func (p *ReminderPreferences) IsQuiet(t time.Time, loc *time.Location) bool {
+	if p.QuietStart == p.QuietEnd {
+		return false
+	}
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	if p.QuietStart < p.QuietEnd {
+		return minute >= p.QuietStart && minute < p.QuietEnd
+	}
+	return minute >= p.QuietStart || minute < p.QuietEnd
+}
+
+// DigestDue reports whether Frequency has passed since the user's last digest.
+// lastReminded maps pull request IDs to the last reminder, so the latest of them is
+// when the last digest was sent.
+func (p *ReminderPreferences) DigestDue(lastReminded map[string]time.Time, now time.Time) bool {
+	for _, at := range lastReminded {
+		if now.Sub(at) < p.Frequency {
+			return false
+		}
+	}
+	return true
+}
+
+// PendingForReminder picks the open pull requests that were not part of a reminder
+// within the last Frequency. lastReminded maps pull request IDs to the last reminder.
+func (p *ReminderPreferences) PendingForReminder(prs []*PullRequest, lastReminded map[string]time.Time, now time.Time) []*PullRequest {
+	var pending []*PullRequest
+	for _, pr := range prs {
+		if pr.IsMerged() {
+			continue
+		}
+		if at, ok := lastReminded[pr.PullRequestID]; ok && now.Sub(at) < p.Frequency {
+			continue
+		}
+		pending = append(pending, pr)
+	}
+	return pending
+}
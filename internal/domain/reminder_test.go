@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReminderPreferences_IsQuiet(t *testing.T) {
+	day := func(h, m int) time.Time { return time.Date(2025, 3, 3, h, m, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		start    int
+		end      int
+		at       time.Time
+		expected bool
+	}{
+		{name: "no quiet hours", start: 0, end: 0, at: day(3, 0), expected: false},
+		{name: "inside daytime window", start: 12 * 60, end: 13 * 60, at: day(12, 30), expected: true},
+		{name: "end is exclusive", start: 12 * 60, end: 13 * 60, at: day(13, 0), expected: false},
+		{name: "overnight before midnight", start: 22 * 60, end: 8 * 60, at: day(23, 0), expected: true},
+		{name: "overnight after midnight", start: 22 * 60, end: 8 * 60, at: day(7, 59), expected: true},
+		{name: "overnight daytime", start: 22 * 60, end: 8 * 60, at: day(8, 0), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultReminderPreferences("u1")
+			p.QuietStart, p.QuietEnd = tt.start, tt.end
+			if got := p.IsQuiet(tt.at, time.UTC); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	p := DefaultReminderPreferences("u1")
+	p.QuietStart, p.QuietEnd = 22*60, 8*60
+	msk := time.FixedZone("MSK", 3*60*60)
+	if !p.IsQuiet(day(20, 0), msk) {
+		t.Error("20:00 UTC is 23:00 MSK and should be quiet")
+	}
+}
+
+func TestReminderPreferences_PendingForReminder(t *testing.T) {
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	p := DefaultReminderPreferences("u2")
+
+	prs := []*PullRequest{
+		{PullRequestID: "pr-new", Status: PRStatusOpen},
+		{PullRequestID: "pr-recent", Status: PRStatusOpen},
+		{PullRequestID: "pr-old", Status: PRStatusOpen},
+		{PullRequestID: "pr-merged", Status: PRStatusMerged},
+	}
+	last := map[string]time.Time{
+		"pr-recent": now.Add(-time.Hour),
+		"pr-old":    now.Add(-25 * time.Hour),
+	}
+
+	pending := p.PendingForReminder(prs, last, now)
+	if len(pending) != 2 || pending[0].PullRequestID != "pr-new" || pending[1].PullRequestID != "pr-old" {
+		ids := make([]string, len(pending))
+		for i, pr := range pending {
+			ids[i] = pr.PullRequestID
+		}
+		t.Errorf("expected [pr-new pr-old], got %v", ids)
+	}
+}
+
+func TestReminderPreferences_DigestDue(t *testing.T) {
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	p := DefaultReminderPreferences("u2")
+
+	if !p.DigestDue(nil, now) {
+		t.Error("expected a digest to be due for a user never reminded")
+	}
+	last := map[string]time.Time{
+		"pr-old":    now.Add(-25 * time.Hour),
+		"pr-recent": now.Add(-time.Hour),
+	}
+	if p.DigestDue(last, now) {
+		t.Error("expected no digest within a day of the last one")
+	}
+	if !p.DigestDue(last, now.Add(23*time.Hour)) {
+		t.Error("expected a digest to be due a day after the last one")
+	}
+}
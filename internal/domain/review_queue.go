@@ -0,0 +1,74 @@
+package domain
+
+// ReviewQueue tracks the open pull requests a reviewer is assigned to, so that live
+// clients can be sent changes instead of the whole queue.
+type ReviewQueue struct {
+	userID string
+	prs    map[string]struct{}
+}
+
+func NewReviewQueue(userID string, prIDs []string) *ReviewQueue {
+	q := &ReviewQueue{userID: userID, prs: make(map[string]struct{}, len(prIDs))}
+	for _, id := range prIDs {
+		q.prs[id] = struct{}{}
+	}
+	return q
+}
+
+// QueueChange is a pull request entering or leaving a review queue because of Event.
+type QueueChange struct {
+	Added bool
+	PRID  string
+	Event *PREvent
+}
+
+func (q *ReviewQueue) Contains(prID string) bool {
+	_, ok := q.prs[prID]
+	return ok
+}
+
+func (q *ReviewQueue) Len() int {
+	return len(q.prs)
+}
+
+// Apply updates the queue with a timeline event and reports the change, or nil when the
+// event does not affect the queue. Applying an event twice is harmless, which lets
+// callers overlap a snapshot with the live feed.
+func (q *ReviewQueue) Apply(e *PREvent) *QueueChange {
+	switch e.Type {
+	case PREventReviewerAssigned:
+		if e.UserID == q.userID {
+			return q.add(e)
+		}
+	case PREventReviewerReassigned, PREventReviewerDeclined:
+		if e.UserID == q.userID {
+			return q.remove(e)
+		}
+		if e.ReplacedBy == q.userID {
+			return q.add(e)
+		}
+	case PREventReviewerRemoved:
+		if e.UserID == q.userID {
+			return q.remove(e)
+		}
+	case PREventMerged:
+		return q.remove(e)
+	}
+	return nil
+}
+
+func (q *ReviewQueue) add(e *PREvent) *QueueChange {
+	if q.Contains(e.PRID) {
+		return nil
+	}
+	q.prs[e.PRID] = struct{}{}
+	return &QueueChange{Added: true, PRID: e.PRID, Event: e}
+}
+
+func (q *ReviewQueue) remove(e *PREvent) *QueueChange {
+	if !q.Contains(e.PRID) {
+		return nil
+	}
+	delete(q.prs, e.PRID)
+	return &QueueChange{Added: false, PRID: e.PRID, Event: e}
+}
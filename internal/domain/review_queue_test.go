@@ -0,0 +1,67 @@
+package domain
+
+import "testing"
+
+func TestReviewQueue_Apply(t *testing.T) {
+	event := func(eventType PREventType, prID, userID, replacedBy string) *PREvent {
+		e := NewPREvent(prID, eventType)
+		e.UserID = userID
+		e.ReplacedBy = replacedBy
+		return e
+	}
+
+	tests := []struct {
+		name    string
+		event   *PREvent
+		changed bool
+		added   bool
+	}{
+		{name: "assigned to me", event: event(PREventReviewerAssigned, "pr-2", "u1", ""), changed: true, added: true},
+		{name: "assigned to someone else", event: event(PREventReviewerAssigned, "pr-2", "u2", ""), changed: false},
+		{name: "assigned again", event: event(PREventReviewerAssigned, "pr-1", "u1", ""), changed: false},
+		{name: "reassigned away from me", event: event(PREventReviewerReassigned, "pr-1", "u1", "u2"), changed: true, added: false},
+		{name: "reassigned to me", event: event(PREventReviewerReassigned, "pr-2", "u2", "u1"), changed: true, added: true},
+		{name: "declined by me", event: event(PREventReviewerDeclined, "pr-1", "u1", ""), changed: true, added: false},
+		{name: "declined with me as replacement", event: event(PREventReviewerDeclined, "pr-2", "u2", "u1"), changed: true, added: true},
+		{name: "removed", event: event(PREventReviewerRemoved, "pr-1", "u1", ""), changed: true, added: false},
+		{name: "other reviewer removed", event: event(PREventReviewerRemoved, "pr-1", "u2", ""), changed: false},
+		{name: "merged", event: event(PREventMerged, "pr-1", "", ""), changed: true, added: false},
+		{name: "merged outside queue", event: event(PREventMerged, "pr-2", "", ""), changed: false},
+		{name: "review submitted", event: event(PREventReviewSubmitted, "pr-1", "u1", ""), changed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewReviewQueue("u1", []string{"pr-1"})
+			change := q.Apply(tt.event)
+			if (change != nil) != tt.changed {
+				t.Fatalf("expected change %v, got %+v", tt.changed, change)
+			}
+			if change == nil {
+				return
+			}
+			if change.Added != tt.added || change.PRID != tt.event.PRID {
+				t.Errorf("unexpected change %+v", change)
+			}
+			if q.Contains(tt.event.PRID) != tt.added {
+				t.Errorf("queue membership of %s not updated", tt.event.PRID)
+			}
+		})
+	}
+}
+
+func TestReviewQueue_ApplyIsIdempotent(t *testing.T) {
+	q := NewReviewQueue("u1", nil)
+	e := NewPREvent("pr-1", PREventReviewerAssigned)
+	e.UserID = "u1"
+
+	if q.Apply(e) == nil {
+		t.Fatal("expected first assignment to add the pull request")
+	}
+	if q.Apply(e) != nil {
+		t.Error("expected repeated assignment to be ignored")
+	}
+	if q.Len() != 1 {
+		t.Errorf("expected 1 pull request, got %d", q.Len())
+	}
+}
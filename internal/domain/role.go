@@ -0,0 +1,82 @@
+package domain
+
+import "fmt"
+
+// Role is granted to a user on top of plain team membership, which every user has in
+// their own team.
+type Role string
+
+const (
+	// RoleAdmin may do anything in every team.
+	RoleAdmin Role = "admin"
+	// RoleTeamAdmin manages one team and the pull requests authored in it.
+	RoleTeamAdmin Role = "team_admin"
+)
+
+func ParseRole(s string) (Role, error) {
+	switch Role(s) {
+	case RoleAdmin, RoleTeamAdmin:
+		return Role(s), nil
+	default:
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+}
+
+// RoleAssignment grants Role to a user. TeamName is empty for RoleAdmin.
+type RoleAssignment struct {
+	UserID   string
+	TeamName string
+	Role     Role
+}
+
+func (a *RoleAssignment) Validate() error {
+	switch {
+	case a.Role == RoleAdmin && a.TeamName != "":
+		return NewDomainError(ErrCodeInvalidRole, "admin role is not bound to a team")
+	case a.Role == RoleTeamAdmin && a.TeamName == "":
+		return NewDomainError(ErrCodeInvalidRole, "team_admin role requires a team")
+	}
+	return nil
+}
+
+// Permissions are what a signed-in user may do, derived from their team and roles.
+type Permissions struct {
+	UserID   string
+	TeamName string
+	IsAdmin  bool
+	adminOf  map[string]bool
+}
+
+func NewPermissions(user *User, roles []*RoleAssignment) *Permissions {
+	p := &Permissions{
+		UserID:   user.UserID,
+		TeamName: user.TeamName,
+		adminOf:  make(map[string]bool),
+	}
+	for _, r := range roles {
+		switch r.Role {
+		case RoleAdmin:
+			p.IsAdmin = true
+		case RoleTeamAdmin:
+			p.adminOf[r.TeamName] = true
+		}
+	}
+	return p
+}
+
+// CanManageTeam covers changing the team's members and settings and granting team_admin
+// in it.
+func (p *Permissions) CanManageTeam(teamName string) bool {
+	return p.IsAdmin || p.adminOf[teamName]
+}
+
+// CanChangeReviewers allows members of the author's team to reassign and override
+// reviewers of a pull request.
+func (p *Permissions) CanChangeReviewers(authorTeam string) bool {
+	return p.TeamName == authorTeam || p.CanManageTeam(authorTeam)
+}
+
+// CanMerge is limited to the author and admins of the author's team.
+func (p *Permissions) CanMerge(pr *PullRequest, authorTeam string) bool {
+	return pr.AuthorID == p.UserID || p.CanManageTeam(authorTeam)
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "testing"
+
+func TestPermissions(t *testing.T) {
+	member := &User{UserID: "u1", TeamName: "backend"}
+	pr := &PullRequest{PullRequestID: "pr-1", AuthorID: "u2"}
+
+	plain := NewPermissions(member, nil)
+	teamAdmin := NewPermissions(member, []*RoleAssignment{{UserID: "u1", TeamName: "frontend", Role: RoleTeamAdmin}})
+	admin := NewPermissions(member, []*RoleAssignment{{UserID: "u1", Role: RoleAdmin}})
+
+	tests := []struct {
+		name     string
+		got      bool
+		expected bool
+	}{
+		{name: "member cannot manage own team", got: plain.CanManageTeam("backend"), expected: false},
+		{name: "team admin manages that team", got: teamAdmin.CanManageTeam("frontend"), expected: true},
+		{name: "team admin does not manage other teams", got: teamAdmin.CanManageTeam("mobile"), expected: false},
+		{name: "admin manages every team", got: admin.CanManageTeam("mobile"), expected: true},
+		{name: "member changes reviewers in own team", got: plain.CanChangeReviewers("backend"), expected: true},
+		{name: "member cannot change reviewers elsewhere", got: plain.CanChangeReviewers("frontend"), expected: false},
+		{name: "team admin changes reviewers in managed team", got: teamAdmin.CanChangeReviewers("frontend"), expected: true},
+		{name: "member cannot merge others' PR", got: plain.CanMerge(pr, "backend"), expected: false},
+		{name: "author merges own PR", got: NewPermissions(&User{UserID: "u2", TeamName: "backend"}, nil).CanMerge(pr, "backend"), expected: true},
+		{name: "team admin merges in managed team", got: teamAdmin.CanMerge(pr, "frontend"), expected: true},
+		{name: "admin merges anywhere", got: admin.CanMerge(pr, "backend"), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, tt.got)
+			}
+		})
+	}
+}
+
+func TestRoleAssignment_Validate(t *testing.T) {
+	valid := []*RoleAssignment{
+		{UserID: "u1", Role: RoleAdmin},
+		{UserID: "u1", TeamName: "backend", Role: RoleTeamAdmin},
+	}
+	for _, a := range valid {
+		if err := a.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", a, err)
+		}
+	}
+
+	invalid := []*RoleAssignment{
+		{UserID: "u1", TeamName: "backend", Role: RoleAdmin},
+		{UserID: "u1", Role: RoleTeamAdmin},
+	}
+	for _, a := range invalid {
+		if err := a.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", a)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimezone     = "UTC"
+	DefaultWorkDayStart = 9 * 60
+	DefaultWorkDayEnd   = 18 * 60
+)
+
+// TeamSettings holds the review SLA of a team. The SLA is measured in working time:
+// WorkDayStart..WorkDayEnd (minutes after midnight) in Timezone, Monday to Friday.
+// A zero ReviewSLA means the team has no SLA. StaleTimeout, also in working time, is how
+// long an unanswered assignment may wait before it is reassigned automatically; zero
+// turns automatic reassignment off.
+type TeamSettings struct {
+	TeamName     string
+	ReviewSLA    time.Duration
+	StaleTimeout time.Duration
+	Timezone     string
+	WorkDayStart int
+	WorkDayEnd   int
+	// Version is the version of the team the settings belong to.
+	Version int64
+}
+
+func DefaultTeamSettings(teamName string) *TeamSettings {
+	return &TeamSettings{
+		TeamName:     teamName,
+		Timezone:     DefaultTimezone,
+		WorkDayStart: DefaultWorkDayStart,
+		WorkDayEnd:   DefaultWorkDayEnd,
+	}
+}
+
+func (s *TeamSettings) Validate() error {
+	if strings.TrimSpace(s.TeamName) == "" {
+		return NewDomainError(ErrCodeInvalidSettings, "team_name cannot be empty")
+	}
+	if s.ReviewSLA < 0 {
+		return NewDomainError(ErrCodeInvalidSettings, "review SLA cannot be negative")
+	}
+	if s.StaleTimeout < 0 {
+		return NewDomainError(ErrCodeInvalidSettings, "stale timeout cannot be negative")
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return NewDomainError(ErrCodeInvalidSettings, fmt.Sprintf("unknown timezone: %s", s.Timezone))
+	}
+	if s.WorkDayStart < 0 || s.WorkDayEnd > 24*60 || s.WorkDayStart >= s.WorkDayEnd {
+		return NewDomainError(ErrCodeInvalidSettings, "work day must start before it ends and fit into a day")
+	}
+	return nil
+}
+
+func (s *TeamSettings) HasSLA() bool {
+	return s.ReviewSLA > 0
+}
+
+// ReviewDeadline is the moment an assignment made at assignedAt breaches the SLA.
+func (s *TeamSettings) ReviewDeadline(assignedAt time.Time) (time.Time, bool) {
+	if !s.HasSLA() {
+		return time.Time{}, false
+	}
+	return s.AddWorkingTime(assignedAt, s.ReviewSLA), true
+}
+
+func (s *TeamSettings) HasStaleTimeout() bool {
+	return s.StaleTimeout > 0
+}
+
+// StaleDeadline is the moment an unanswered assignment made at assignedAt becomes stale.
+func (s *TeamSettings) StaleDeadline(assignedAt time.Time) (time.Time, bool) {
+	if !s.HasStaleTimeout() {
+		return time.Time{}, false
+	}
+	return s.AddWorkingTime(assignedAt, s.StaleTimeout), true
+}
+
+// Location returns the team time zone, falling back to UTC for unknown names.
+func (s *TeamSettings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// AddWorkingTime moves start forward by d, counting only working hours on weekdays.
+func (s *TeamSettings) AddWorkingTime(start time.Time, d time.Duration) time.Time {
+	loc := s.Location()
+	t := start.In(loc)
+	for {
+		y, m, day := t.Date()
+		// time.Date normalises minutes past 59, which keeps DST days correct.
+		dayStart := time.Date(y, m, day, 0, s.WorkDayStart, 0, 0, loc)
+		dayEnd := time.Date(y, m, day, 0, s.WorkDayEnd, 0, 0, loc)
+
+		if isWeekend(t.Weekday()) || !t.Before(dayEnd) {
+			t = time.Date(y, m, day+1, 0, s.WorkDayStart, 0, 0, loc)
+			continue
+		}
+		if t.Before(dayStart) {
+			t = dayStart
+		}
+
+		available := dayEnd.Sub(t)
+		if d <= available {
+			return t.Add(d)
+		}
+		d -= available
+		t = time.Date(y, m, day+1, 0, s.WorkDayStart, 0, 0, loc)
+	}
+}
+
+func isWeekend(d time.Weekday) bool {
+	return d == time.Saturday || d == time.Sunday
+}
+
+// AssignmentSLA follows one reviewer assignment until the reviewer responds (reviews or
+// declines), loses the review to someone else, or the pull request is merged.
+type AssignmentSLA struct {
+	PRID        string
+	ReviewerID  string
+	AssignedAt  time.Time
+	Deadline    time.Time
+	RespondedAt *time.Time
+	EndedAt     *time.Time
+	Breached    bool
+}
+
+func (a *AssignmentSLA) IsOpen() bool {
+	return a.RespondedAt == nil && a.EndedAt == nil
+}
+
+// DeadlineFunc returns the SLA deadline of an assignment, or false when the reviewer's
+// team has no SLA.
+type DeadlineFunc func(reviewerID string, assignedAt time.Time) (time.Time, bool)
+
+// EvaluateAssignmentSLA replays the events of one pull request, ordered by EventID.
+// An assignment is breached when the response came after the deadline, or when it is
+// still open, or ended without a response, after the deadline.
+func EvaluateAssignmentSLA(events []*PREvent, deadline DeadlineFunc, now time.Time) []*AssignmentSLA {
+	var result []*AssignmentSLA
+	open := make(map[string]*AssignmentSLA)
+
+	assign := func(e *PREvent, reviewerID string) {
+		if _, ok := open[reviewerID]; ok {
+			return
+		}
+		due, ok := deadline(reviewerID, e.CreatedAt)
+		if !ok {
+			return
+		}
+		a := &AssignmentSLA{PRID: e.PRID, ReviewerID: reviewerID, AssignedAt: e.CreatedAt, Deadline: due}
+		open[reviewerID] = a
+		result = append(result, a)
+	}
+	respond := func(reviewerID string, at time.Time) {
+		if a, ok := open[reviewerID]; ok {
+			a.RespondedAt = &at
+			a.Breached = at.After(a.Deadline)
+			delete(open, reviewerID)
+		}
+	}
+	end := func(reviewerID string, at time.Time) {
+		if a, ok := open[reviewerID]; ok {
+			a.EndedAt = &at
+			a.Breached = at.After(a.Deadline)
+			delete(open, reviewerID)
+		}
+	}
+
+	for _, e := range events {
+		switch e.Type {
+		case PREventReviewerAssigned:
+			assign(e, e.UserID)
+		case PREventReviewSubmitted:
+			respond(e.UserID, e.CreatedAt)
+		case PREventReviewerDeclined:
+			respond(e.UserID, e.CreatedAt)
+			if e.ReplacedBy != "" {
+				assign(e, e.ReplacedBy)
+			}
+		case PREventReviewerReassigned:
+			end(e.UserID, e.CreatedAt)
+			if e.ReplacedBy != "" {
+				assign(e, e.ReplacedBy)
+			}
+		case PREventReviewerRemoved:
+			end(e.UserID, e.CreatedAt)
+		case PREventMerged:
+			for reviewerID := range open {
+				end(reviewerID, e.CreatedAt)
+			}
+		}
+	}
+
+	for _, a := range open {
+		a.Breached = now.After(a.Deadline)
+	}
+
+	return result
+}
+
+// SLAStat counts assignments and breaches of a team or a reviewer.
+type SLAStat struct {
+	Key         string
+	Assignments int
+	Breaches    int
+}
+
+func (s *SLAStat) BreachRate() float64 {
+	if s.Assignments == 0 {
+		return 0
+	}
+	return float64(s.Breaches) / float64(s.Assignments)
+}
+
+type SLAReport struct {
+	Teams    []*SLAStat
+	Users    []*SLAStat
+	Breaches []*AssignmentSLA
+}
+
+// BuildSLAReport aggregates assignments by the reviewer's team (teamOf) and by reviewer.
+func BuildSLAReport(assignments []*AssignmentSLA, teamOf map[string]string) *SLAReport {
+	teams := make(map[string]*SLAStat)
+	users := make(map[string]*SLAStat)
+	report := &SLAReport{Breaches: []*AssignmentSLA{}}
+
+	count := func(stats map[string]*SLAStat, key string, breached bool) {
+		stat, ok := stats[key]
+		if !ok {
+			stat = &SLAStat{Key: key}
+			stats[key] = stat
+		}
+		stat.Assignments++
+		if breached {
+			stat.Breaches++
+		}
+	}
+
+	for _, a := range assignments {
+		count(teams, teamOf[a.ReviewerID], a.Breached)
+		count(users, a.ReviewerID, a.Breached)
+		if a.Breached {
+			report.Breaches = append(report.Breaches, a)
+		}
+	}
+
+	report.Teams = sortedSLAStats(teams)
+	report.Users = sortedSLAStats(users)
+	sort.Slice(report.Breaches, func(i, j int) bool {
+		return report.Breaches[i].Deadline.Before(report.Breaches[j].Deadline)
+	})
+
+	return report
+}
+
+// sortedSLAStats puts the worst breach rate first.
+func sortedSLAStats(stats map[string]*SLAStat) []*SLAStat {
+	result := make([]*SLAStat, 0, len(stats))
+	for _, s := range stats {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].BreachRate() != result[j].BreachRate() {
+			return result[i].BreachRate() > result[j].BreachRate()
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// ReviewAssignment is a pull request in a reviewer's queue together with the SLA state
+// of the reviewer's current assignment. Deadline is nil when the team has no SLA.
+type ReviewAssignment struct {
+	*PullRequest
+	Deadline *time.Time
+	Overdue  bool
+}
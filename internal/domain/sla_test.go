@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTeamSettings_AddWorkingTime(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	s := &TeamSettings{
+		TeamName:     "backend",
+		ReviewSLA:    8 * time.Hour,
+		Timezone:     "UTC",
+		WorkDayStart: DefaultWorkDayStart,
+		WorkDayEnd:   DefaultWorkDayEnd,
+	}
+
+	tests := []struct {
+		name     string
+		timezone string
+		start    time.Time
+		d        time.Duration
+		expected time.Time
+	}{
+		{
+			name:     "within one day",
+			start:    time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC),
+			d:        8 * time.Hour,
+			expected: time.Date(2025, 3, 3, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "before work day starts",
+			start:    time.Date(2025, 3, 3, 6, 30, 0, 0, time.UTC),
+			d:        time.Hour,
+			expected: time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "rolls over to next day",
+			start:    time.Date(2025, 3, 3, 15, 0, 0, 0, time.UTC),
+			d:        8 * time.Hour,
+			expected: time.Date(2025, 3, 4, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "friday evening skips weekend",
+			start:    time.Date(2025, 3, 7, 17, 0, 0, 0, time.UTC),
+			d:        2 * time.Hour,
+			expected: time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "assigned on saturday",
+			start:    time.Date(2025, 3, 8, 12, 0, 0, 0, time.UTC),
+			d:        time.Hour,
+			expected: time.Date(2025, 3, 10, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "ends exactly at close",
+			start:    time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC),
+			d:        9 * time.Hour,
+			expected: time.Date(2025, 3, 3, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			// 20:00 UTC on Sunday is already 23:00 in MSK, still the weekend there.
+			name:     "team time zone decides the day",
+			timezone: "Europe/Moscow",
+			start:    time.Date(2025, 3, 9, 20, 0, 0, 0, time.UTC),
+			d:        time.Hour,
+			expected: time.Date(2025, 3, 10, 10, 0, 0, 0, msk),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := *s
+			if tt.timezone != "" {
+				if _, err := time.LoadLocation(tt.timezone); err != nil {
+					t.Skip("tz database not available")
+				}
+				settings.Timezone = tt.timezone
+			}
+
+			got := settings.AddWorkingTime(tt.start, tt.d)
+			if !got.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTeamSettings_Validate(t *testing.T) {
+	valid := DefaultTeamSettings("backend")
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("default settings should be valid: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(s *TeamSettings)
+	}{
+		{name: "negative SLA", mutate: func(s *TeamSettings) { s.ReviewSLA = -time.Minute }},
+		{name: "unknown timezone", mutate: func(s *TeamSettings) { s.Timezone = "Mars/Olympus" }},
+		{name: "inverted work day", mutate: func(s *TeamSettings) { s.WorkDayStart, s.WorkDayEnd = 18*60, 9*60 }},
+		{name: "work day past midnight", mutate: func(s *TeamSettings) { s.WorkDayEnd = 25 * 60 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := DefaultTeamSettings("backend")
+			tt.mutate(s)
+			if err := s.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestEvaluateAssignmentSLA(t *testing.T) {
+	base := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+	deadline := func(reviewerID string, assignedAt time.Time) (time.Time, bool) {
+		if reviewerID == "u9" {
+			return time.Time{}, false
+		}
+		return assignedAt.Add(4 * time.Hour), true
+	}
+
+	events := []*PREvent{
+		{PRID: "pr-1", Type: PREventReviewerAssigned, UserID: "u2", CreatedAt: at(0)},
+		{PRID: "pr-1", Type: PREventReviewerAssigned, UserID: "u3", CreatedAt: at(0)},
+		{PRID: "pr-1", Type: PREventReviewerAssigned, UserID: "u9", CreatedAt: at(0)},
+		{PRID: "pr-1", Type: PREventReviewSubmitted, UserID: "u2", CreatedAt: at(2)},
+		{PRID: "pr-1", Type: PREventReviewerReassigned, UserID: "u3", ReplacedBy: "u4", CreatedAt: at(6)},
+	}
+
+	result := EvaluateAssignmentSLA(events, deadline, at(12))
+	if len(result) != 3 {
+		t.Fatalf("expected 3 tracked assignments, got %d", len(result))
+	}
+
+	byReviewer := make(map[string]*AssignmentSLA)
+	for _, a := range result {
+		byReviewer[a.ReviewerID] = a
+	}
+
+	if a := byReviewer["u2"]; a.Breached || a.IsOpen() {
+		t.Errorf("u2 responded in time: %+v", a)
+	}
+	if a := byReviewer["u3"]; !a.Breached || a.EndedAt == nil {
+		t.Errorf("u3 lost the review after the deadline: %+v", a)
+	}
+	if a := byReviewer["u4"]; !a.Breached || !a.IsOpen() {
+		t.Errorf("u4 is still open past the deadline: %+v", a)
+	}
+	if _, ok := byReviewer["u9"]; ok {
+		t.Error("reviewers without SLA should not be tracked")
+	}
+
+	merged := append(events, &PREvent{PRID: "pr-1", Type: PREventMerged, CreatedAt: at(7)})
+	for _, a := range EvaluateAssignmentSLA(merged, deadline, at(12)) {
+		if a.ReviewerID == "u4" && (a.Breached || a.IsOpen()) {
+			t.Errorf("merge within u4's deadline should close it without breach: %+v", a)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// StaleReason marks reassignments made because the reviewer did not respond in time.
+const StaleReason = "STALE"
+
+// FindStaleReviewers returns the reviewers of one pull request whose assignment is still
+// unanswered past the deadline, oldest first. Events must be ordered by EventID. At most
+// maxAuto automatic reassignments are made per pull request, so fewer reviewers (or
+// none) are returned once earlier STALE reassignments used up the budget.
+func FindStaleReviewers(events []*PREvent, deadline DeadlineFunc, now time.Time, maxAuto int) []string {
+	done := 0
+	for _, e := range events {
+		if e.Type == PREventReviewerReassigned && e.Reason == StaleReason {
+			done++
+		}
+	}
+	remaining := maxAuto - done
+	if remaining <= 0 {
+		return nil
+	}
+
+	var stale []*AssignmentSLA
+	for _, a := range EvaluateAssignmentSLA(events, deadline, now) {
+		if a.IsOpen() && a.Breached {
+			stale = append(stale, a)
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].Deadline.Before(stale[j].Deadline)
+	})
+	if len(stale) > remaining {
+		stale = stale[:remaining]
+	}
+
+	reviewers := make([]string, len(stale))
+	for i, a := range stale {
+		reviewers[i] = a.ReviewerID
+	}
+	return reviewers
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindStaleReviewers(t *testing.T) {
+	base := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+	deadline := func(_ string, assignedAt time.Time) (time.Time, bool) {
+		return assignedAt.Add(4 * time.Hour), true
+	}
+
+	assigned := []*PREvent{
+		{PRID: "pr-1", Type: PREventReviewerAssigned, UserID: "u2", CreatedAt: at(0)},
+		{PRID: "pr-1", Type: PREventReviewerAssigned, UserID: "u3", CreatedAt: at(1)},
+	}
+
+	tests := []struct {
+		name     string
+		events   []*PREvent
+		now      time.Time
+		maxAuto  int
+		expected []string
+	}{
+		{
+			name:     "nothing stale yet",
+			events:   assigned,
+			now:      at(3),
+			maxAuto:  2,
+			expected: nil,
+		},
+		{
+			name:     "both stale, oldest first",
+			events:   assigned,
+			now:      at(6),
+			maxAuto:  2,
+			expected: []string{"u2", "u3"},
+		},
+		{
+			name: "responded reviewer is not stale",
+			events: append(append([]*PREvent{}, assigned...),
+				&PREvent{PRID: "pr-1", Type: PREventReviewSubmitted, UserID: "u3", CreatedAt: at(2)}),
+			now:      at(6),
+			maxAuto:  2,
+			expected: []string{"u2"},
+		},
+		{
+			name:     "cap limits the batch",
+			events:   assigned,
+			now:      at(6),
+			maxAuto:  1,
+			expected: []string{"u2"},
+		},
+		{
+			name: "cap already used up",
+			events: append(append([]*PREvent{}, assigned...),
+				&PREvent{PRID: "pr-1", Type: PREventReviewerReassigned, UserID: "u2", ReplacedBy: "u4", Reason: StaleReason, CreatedAt: at(5)}),
+			now:      at(20),
+			maxAuto:  1,
+			expected: nil,
+		},
+		{
+			name: "manual reassignments do not count",
+			events: append(append([]*PREvent{}, assigned...),
+				&PREvent{PRID: "pr-1", Type: PREventReviewerReassigned, UserID: "u2", ReplacedBy: "u4", CreatedAt: at(1)}),
+			now:      at(6),
+			maxAuto:  1,
+			expected: []string{"u3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindStaleReviewers(tt.events, deadline, tt.now, tt.maxAuto)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
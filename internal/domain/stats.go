@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// StatsGrouping is the bucket size of a statistics time series.
+type StatsGrouping string
+
+const (
+	GroupByDay   StatsGrouping = "day"
+	GroupByWeek  StatsGrouping = "week"
+	GroupByMonth StatsGrouping = "month"
+)
+
+// ParseStatsGrouping accepts an empty value, meaning no time series is requested.
+func ParseStatsGrouping(raw string) (StatsGrouping, bool) {
+	switch g := StatsGrouping(raw); g {
+	case "", GroupByDay, GroupByWeek, GroupByMonth:
+		return g, true
+	default:
+		return "", false
+	}
+}
+
+// StatsFilter narrows statistics down to a team and a time window.
+// Empty fields are not applied; To is exclusive.
+type StatsFilter struct {
+	TeamName string
+	From     time.Time
+	To       time.Time
+	GroupBy  StatsGrouping
+}
+
+// ReviewerStatPoint is the number of assignments a reviewer received in one period.
+type ReviewerStatPoint struct {
+	Period      time.Time
+	UserID      string
+	Assignments int
+}
+
+// PRStatPoint is the number of pull requests created and merged in one period.
+type PRStatPoint struct {
+	Period  time.Time
+	Created int
+	Merged  int
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "testing"
+
+func TestParseStatsGrouping(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected StatsGrouping
+		ok       bool
+	}{
+		{raw: "", expected: "", ok: true},
+		{raw: "day", expected: GroupByDay, ok: true},
+		{raw: "week", expected: GroupByWeek, ok: true},
+		{raw: "month", expected: GroupByMonth, ok: true},
+		{raw: "year", expected: "", ok: false},
+		{raw: "DAY", expected: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, ok := ParseStatsGrouping(tt.raw)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("ParseStatsGrouping(%q) = (%q, %v), expected (%q, %v)", tt.raw, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
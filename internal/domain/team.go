@@ -9,6 +9,8 @@ import (
 type Team struct {
     TeamName  string
     CreatedAt time.Time
+    // Version grows with every change to the team's settings or members.
+    Version int64
 }
 
 func (t *Team) Validate() error {
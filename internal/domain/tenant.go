@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// tenantIDPattern keeps tenant IDs safe to use in headers, tokens and lock names.
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// Tenant is an organization served by the deployment. Teams, users and pull requests of
+// different tenants never see each other and may reuse the same identifiers.
+type Tenant struct {
+	TenantID  string
+	Name      string
+	CreatedAt time.Time
+}
+
+func (t *Tenant) Validate() error {
+	if err := ValidateTenantID(t.TenantID); err != nil {
+		return err
+	}
+	if strings.TrimSpace(t.Name) == "" {
+		return NewDomainError(ErrCodeInvalidTenant, "tenant name cannot be empty")
+	}
+	return nil
+}
+
+// ValidateTenantID accepts lowercase letters, digits, '-' and '_', up to 64 characters.
+func ValidateTenantID(id string) error {
+	if !tenantIDPattern.MatchString(id) {
+		return NewDomainError(ErrCodeInvalidTenant,
+			"tenant_id must be 1-64 lowercase letters, digits, '-' or '_' starting with a letter or digit")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateTenantID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{id: "default", valid: true},
+		{id: "payments-eu_2", valid: true},
+		{id: "", valid: false},
+		{id: "-payments", valid: false},
+		{id: "Payments", valid: false},
+		{id: "pay ments", valid: false},
+		{id: "a/b", valid: false},
+		{id: strings.Repeat("a", 64), valid: true},
+		{id: strings.Repeat("a", 65), valid: false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateTenantID(tt.id)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateTenantID(%q) = %v, want valid %v", tt.id, err, tt.valid)
+		}
+		var domainErr *DomainError
+		if err != nil && (!errors.As(err, &domainErr) || domainErr.Code != ErrCodeInvalidTenant) {
+			t.Errorf("ValidateTenantID(%q) returned %v, want INVALID_TENANT", tt.id, err)
+		}
+	}
+}
+
+func TestTenantValidateRequiresName(t *testing.T) {
+	if err := (&Tenant{TenantID: "acme", Name: " "}).Validate(); err == nil {
+		t.Fatal("expected an error for an empty name")
+	}
+	if err := (&Tenant{TenantID: "acme", Name: "Acme"}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -7,12 +7,14 @@ import (
 )
 
 type User struct {
-	UserID    string
-	Username  string
-	TeamName  string
-	IsActive  bool
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	UserID        string
+	Username      string
+	TeamName      string
+	IsActive      bool
+	SlackMemberID string
+	Email         string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
 
 func (u *User) Validate() error {
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/service"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+	logger        *slog.Logger
+}
+
+func NewAPIKeyHandler(apiKeyService service.APIKeyService, logger *slog.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		logger:        logger,
+	}
+}
+
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if req.Name == "" || len(req.Scopes) == 0 {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "name and scopes are required",
+			},
+		})
+		return
+	}
+
+	scopes := make([]domain.Scope, 0, len(req.Scopes))
+	for _, raw := range req.Scopes {
+		scope, err := domain.ParseScope(raw)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error: ErrorDetail{
+					Code:    "INVALID_REQUEST",
+					Message: "scopes must be read, write:pr or admin:teams",
+				},
+			})
+			return
+		}
+		scopes = append(scopes, scope)
+	}
+
+	key, secret, err := h.apiKeyService.CreateKey(r.Context(), req.Name, scopes)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, APIKeySecretResponse{
+		Key:    mapAPIKeyToDTO(key),
+		Secret: secret,
+	})
+}
+
+func (h *APIKeyHandler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	keyID, ok := decodeAPIKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	key, secret, err := h.apiKeyService.RotateKey(r.Context(), keyID)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, APIKeySecretResponse{
+		Key:    mapAPIKeyToDTO(key),
+		Secret: secret,
+	})
+}
+
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	keyID, ok := decodeAPIKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	key, err := h.apiKeyService.RevokeKey(r.Context(), keyID)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, APIKeyResponse{Key: mapAPIKeyToDTO(key)})
+}
+
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.ListKeys(r.Context())
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	result := make([]APIKeyDTO, len(keys))
+	for i, k := range keys {
+		result[i] = mapAPIKeyToDTO(k)
+	}
+
+	respondJSON(w, http.StatusOK, APIKeysResponse{Keys: result})
+}
+
+func decodeAPIKeyID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req APIKeyIDRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return "", false
+	}
+
+	if req.KeyID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "key_id is required",
+			},
+		})
+		return "", false
+	}
+
+	return req.KeyID, true
+}
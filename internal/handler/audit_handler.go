@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/audit"
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/middleware"
+	"github.com/mivihan/Pull_Request_service/internal/service"
+)
+
+// auditExportPageSize is how many entries an export reads from the database at a time.
+const auditExportPageSize = 500
+
+type AuditHandler struct {
+	auditService service.AuditService
+	logger       *slog.Logger
+}
+
+func NewAuditHandler(auditService service.AuditService, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListEntries returns a page of the audit log; the next page starts after next_after_id.
+func (h *AuditHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+	if filter.Limit, ok = parseIntParam(w, r, "limit"); !ok {
+		return
+	}
+
+	entries, err := h.auditService.ListEntries(r.Context(), filter)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	resp := AuditLogResponse{Entries: make([]AuditEntryDTO, len(entries))}
+	for i, e := range entries {
+		resp.Entries[i] = mapAuditEntryToDTO(e)
+	}
+	if len(entries) > 0 {
+		next := entries[len(entries)-1].EntryID
+		resp.NextAfterID = &next
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// Export writes every matching entry as JSON Lines.
+func (h *AuditHandler) Export(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.Limit = auditExportPageSize
+
+	ctx := r.Context()
+	entries, err := h.auditService.ListEntries(ctx, filter)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	// Large exports outlive the server write timeout.
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	for {
+		for _, e := range entries {
+			if err := enc.Encode(mapAuditEntryToDTO(e)); err != nil {
+				return
+			}
+		}
+		if len(entries) < filter.Limit {
+			return
+		}
+
+		filter.AfterID = entries[len(entries)-1].EntryID
+		if entries, err = h.auditService.ListEntries(ctx, filter); err != nil {
+			// The status is sent already; the truncated export is all we can report.
+			h.logger.Error("audit export failed", "error", err, "after_id", filter.AfterID)
+			return
+		}
+	}
+}
+
+// parseAuditFilter reads the actor, action, target_type, target_id, from, to and
+// after_id query parameters.
+func parseAuditFilter(w http.ResponseWriter, r *http.Request) (domain.AuditFilter, bool) {
+	query := r.URL.Query()
+	filter := domain.AuditFilter{
+		Actor:    query.Get("actor"),
+		Action:   domain.AuditAction(query.Get("action")),
+		TargetID: query.Get("target_id"),
+	}
+
+	if raw := query.Get("target_type"); raw != "" {
+		target, err := domain.ParseAuditTarget(raw)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error: ErrorDetail{
+					Code:    "INVALID_REQUEST",
+					Message: "target_type must be team, user, pull_request or api_key",
+				},
+			})
+			return filter, false
+		}
+		filter.TargetType = target
+	}
+
+	var ok bool
+	if filter.From, ok = parseTimeParam(w, r, "from"); !ok {
+		return filter, false
+	}
+	if filter.To, ok = parseTimeParam(w, r, "to"); !ok {
+		return filter, false
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		respondInvalidWindow(w)
+		return filter, false
+	}
+
+	afterID, ok := parseIntParam(w, r, "after_id")
+	if !ok {
+		return filter, false
+	}
+	filter.AfterID = int64(afterID)
+
+	return filter, true
+}
+
+// parseIntParam returns zero when the parameter is absent.
+func parseIntParam(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, true
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: name + " must be a non-negative integer",
+			},
+		})
+		return 0, false
+	}
+
+	return value, true
+}
+
+// auditSource tags the request context with what the audit log records about the origin
+// of a change.
+func auditSource(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.WithSource(r.Context(), audit.Source{
+			RequestID: middleware.RequestIDFrom(r.Context()),
+			IP:        middleware.ClientIP(r),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mivihan/Pull_Request_service/internal/auth"
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/service"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+// TenantHeader names the tenant a request acts for. Only callers not bound to a tenant
+// choose one: the bootstrap key, or anyone while authentication is disabled.
+const TenantHeader = "X-Tenant-ID"
+
+// AuthConfig controls authentication of API requests. When Enabled is false every
+// endpoint stays open, as before authentication existed.
+type AuthConfig struct {
+	Enabled bool
+	APIKeys service.APIKeyService
+	// Tokens, when set, accepts SSO JWTs as bearer tokens. Holders of AdminRole are
+	// admins of every team.
+	Tokens    *auth.JWTVerifier
+	AdminRole string
+}
+
+type authMiddleware struct {
+	cfg     AuthConfig
+	users   service.UserService
+	roles   service.RoleService
+	tenants service.TenantService
+	logger  *slog.Logger
+}
+
+// authenticate identifies the caller by an SSO JWT or an API key, passed as a bearer
+// token or, for API keys, in the X-API-Key header.
+func (m *authMiddleware) authenticate(next http.Handler) http.Handler {
+	if !m.cfg.Enabled {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := r.Header.Get("X-API-Key")
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			secret = strings.TrimSpace(token)
+		}
+		if secret == "" {
+			m.unauthorized(w, domain.ErrUnauthorized)
+			return
+		}
+
+		var principal *auth.Principal
+		var err error
+		if m.cfg.Tokens != nil && auth.LooksLikeJWT(secret) {
+			principal, err = m.tokenPrincipal(r, secret)
+		} else {
+			principal, err = m.apiKeyPrincipal(r, secret)
+		}
+		if errors.Is(err, domain.ErrUnauthorized) {
+			m.unauthorized(w, err)
+			return
+		}
+		if err != nil {
+			respondError(w, err, m.logger)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+func (m *authMiddleware) apiKeyPrincipal(r *http.Request, secret string) (*auth.Principal, error) {
+	key, err := m.cfg.APIKeys.Authenticate(r.Context(), secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.Principal{
+		Subject: "api_key:" + key.KeyID,
+		Tenant:  key.TenantID,
+		Scopes:  key.Scopes,
+	}, nil
+}
+
+// tokenPrincipal maps a verified token to the internal user it names within the tenant
+// of the token. Tokens of users unknown to that tenant are rejected. Admins, by SSO role or stored role, and team
+// admins get admin:teams; the authz layer narrows it down to their teams.
+func (m *authMiddleware) tokenPrincipal(r *http.Request, token string) (*auth.Principal, error) {
+	claims, err := m.cfg.Tokens.Verify(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			m.logger.Debug("rejected bearer token", "error", err)
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	ctx := tenant.WithID(r.Context(), claims.TenantID)
+	user, err := m.users.GetUser(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	roles, err := m.roles.GetUserRoles(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	admin := m.cfg.AdminRole != "" && slices.Contains(claims.Roles, m.cfg.AdminRole)
+	scopes := []domain.Scope{domain.ScopeRead, domain.ScopeWritePR}
+	if admin || len(roles) > 0 {
+		scopes = append(scopes, domain.ScopeAdminTeams)
+	}
+
+	return &auth.Principal{
+		Subject: "jwt:" + claims.Issuer + "#" + claims.Subject,
+		Tenant:  claims.TenantID,
+		Scopes:  scopes,
+		UserID:  user.UserID,
+		Roles:   claims.Roles,
+		Admin:   admin,
+	}, nil
+}
+
+// require rejects callers without the scope. It must run after authenticate.
+func (m *authMiddleware) require(scope domain.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !m.cfg.Enabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.PrincipalFrom(r.Context())
+			if !ok {
+				m.unauthorized(w, domain.ErrUnauthorized)
+				return
+			}
+			if !principal.Allows(scope) {
+				respondError(w, domain.NewDomainError(domain.ErrCodeForbidden, "scope "+string(scope)+" is required"), m.logger)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// resolveTenant scopes the request to the tenant of its credential. Callers not bound to
+// a tenant act for the one named by TenantHeader, the default tenant when it is absent.
+// It must run after authenticate.
+func (m *authMiddleware) resolveTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested := r.Header.Get(TenantHeader)
+		tenantID := tenant.Default
+
+		principal, ok := auth.PrincipalFrom(r.Context())
+		switch {
+		case ok && principal.Tenant != "":
+			if requested != "" && requested != principal.Tenant {
+				respondError(w, domain.NewDomainError(domain.ErrCodeForbidden, "credential belongs to another tenant"), m.logger)
+				return
+			}
+			tenantID = principal.Tenant
+		case requested != "":
+			if _, err := m.tenants.GetTenant(r.Context(), requested); err != nil {
+				respondError(w, err, m.logger)
+				return
+			}
+			tenantID = requested
+		}
+
+		next.ServeHTTP(w, r.WithContext(tenant.WithID(r.Context(), tenantID)))
+	})
+}
+
+// requirePlatform admits only callers not bound to a tenant, which operate the whole
+// deployment. It must run after authenticate.
+func (m *authMiddleware) requirePlatform(next http.Handler) http.Handler {
+	if !m.cfg.Enabled {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.PrincipalFrom(r.Context())
+		if !ok {
+			m.unauthorized(w, domain.ErrUnauthorized)
+			return
+		}
+		if principal.Tenant != "" {
+			respondError(w, domain.NewDomainError(domain.ErrCodeForbidden, "only the bootstrap key manages tenants"), m.logger)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (m *authMiddleware) unauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="pr-reviewer"`)
+	respondError(w, err, m.logger)
+}
+
+// callerUserID fills in a missing user ID field with the signed-in caller, if any.
+func callerUserID(r *http.Request, userID string) string {
+	if userID != "" {
+		return userID
+	}
+	if principal, ok := auth.PrincipalFrom(r.Context()); ok {
+		return principal.UserID
+	}
+	return ""
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/mivihan/Pull_Request_service/internal/service"
+)
+
+type ConstraintHandler struct {
+	constraintService service.ConstraintService
+	logger            *slog.Logger
+}
+
+func NewConstraintHandler(constraintService service.ConstraintService, logger *slog.Logger) *ConstraintHandler {
+	return &ConstraintHandler{
+		constraintService: constraintService,
+		logger:            logger,
+	}
+}
+
+func (h *ConstraintHandler) AddExclusion(w http.ResponseWriter, r *http.Request) {
+	var req ExclusionPairRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if !validateExclusionRequest(w, req) {
+		return
+	}
+
+	pair, err := h.constraintService.AddExclusion(r.Context(), req.TeamName, req.UserID, req.OtherUserID)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, mapExclusionPairToDTO(pair))
+}
+
+func (h *ConstraintHandler) RemoveExclusion(w http.ResponseWriter, r *http.Request) {
+	var req ExclusionPairRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if !validateExclusionRequest(w, req) {
+		return
+	}
+
+	if err := h.constraintService.RemoveExclusion(r.Context(), req.TeamName, req.UserID, req.OtherUserID); err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ConstraintHandler) ListExclusions(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "team_name query parameter is required",
+			},
+		})
+		return
+	}
+
+	pairs, err := h.constraintService.ListExclusions(r.Context(), teamName)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	exclusions := make([]ExclusionPairDTO, len(pairs))
+	for i, p := range pairs {
+		exclusions[i] = mapExclusionPairToDTO(p)
+	}
+
+	respondJSON(w, http.StatusOK, ExclusionsResponse{
+		TeamName:   teamName,
+		Exclusions: exclusions,
+	})
+}
+
+func (h *ConstraintHandler) AddNeverAssign(w http.ResponseWriter, r *http.Request) {
+	var req NeverAssignRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if !validateNeverAssignRequest(w, req) {
+		return
+	}
+
+	entry, err := h.constraintService.AddNeverAssign(r.Context(), req.AuthorID, req.ReviewerID)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, NeverAssignRequest{
+		AuthorID:   entry.AuthorID,
+		ReviewerID: entry.ReviewerID,
+	})
+}
+
+func (h *ConstraintHandler) RemoveNeverAssign(w http.ResponseWriter, r *http.Request) {
+	var req NeverAssignRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if !validateNeverAssignRequest(w, req) {
+		return
+	}
+
+	if err := h.constraintService.RemoveNeverAssign(r.Context(), req.AuthorID, req.ReviewerID); err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ConstraintHandler) ListNeverAssign(w http.ResponseWriter, r *http.Request) {
+	authorID := r.URL.Query().Get("author_id")
+	if authorID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "author_id query parameter is required",
+			},
+		})
+		return
+	}
+
+	entries, err := h.constraintService.ListNeverAssign(r.Context(), authorID)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	reviewerIDs := make([]string, len(entries))
+	for i, e := range entries {
+		reviewerIDs[i] = e.ReviewerID
+	}
+
+	respondJSON(w, http.StatusOK, NeverAssignResponse{
+		AuthorID:    authorID,
+		ReviewerIDs: reviewerIDs,
+	})
+}
+
+func validateExclusionRequest(w http.ResponseWriter, req ExclusionPairRequest) bool {
+	if req.TeamName == "" || req.UserID == "" || req.OtherUserID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "team_name, user_id and other_user_id are required",
+			},
+		})
+		return false
+	}
+	if req.UserID == req.OtherUserID {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "user_id and other_user_id must differ",
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func validateNeverAssignRequest(w http.ResponseWriter, req NeverAssignRequest) bool {
+	if req.AuthorID == "" || req.ReviewerID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "author_id and reviewer_id are required",
+			},
+		})
+		return false
+	}
+	if req.AuthorID == req.ReviewerID {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "author_id and reviewer_id must differ",
+			},
+		})
+		return false
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/mivihan/Pull_Request_service/internal/domain"
@@ -16,23 +17,28 @@ type TeamMemberDTO struct {
 type TeamDTO struct {
 	TeamName string          `json:"team_name"`
 	Members  []TeamMemberDTO `json:"members"`
+	Version  int64           `json:"version"`
 }
 
 type UserDTO struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID        string `json:"user_id"`
+	Username      string `json:"username"`
+	TeamName      string `json:"team_name"`
+	IsActive      bool   `json:"is_active"`
+	SlackMemberID string `json:"slack_member_id,omitempty"`
+	Email         string `json:"email,omitempty"`
 }
 
 type PullRequestDTO struct {
 	PullRequestID     string     `json:"pull_request_id"`
 	PullRequestName   string     `json:"pull_request_name"`
 	AuthorID          string     `json:"author_id"`
+	Repository        string     `json:"repository,omitempty"`
 	Status            string     `json:"status"`
 	AssignedReviewers []string   `json:"assigned_reviewers"`
 	CreatedAt         time.Time  `json:"createdAt"`
 	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	Version           int64      `json:"version"`
 }
 
 type PullRequestShortDTO struct {
@@ -42,6 +48,13 @@ type PullRequestShortDTO struct {
 	Status          string `json:"status"`
 }
 
+// ReviewAssignmentDTO is a pull request in a reviewer's queue.
+type ReviewAssignmentDTO struct {
+	PullRequestShortDTO
+	ReviewDeadline *time.Time `json:"review_deadline,omitempty"`
+	Overdue        bool       `json:"overdue"`
+}
+
 type CreateTeamRequest struct {
 	TeamName string          `json:"team_name"`
 	Members  []TeamMemberDTO `json:"members"`
@@ -52,10 +65,21 @@ type SetIsActiveRequest struct {
 	IsActive bool   `json:"is_active"`
 }
 
+type SetSlackMemberIDRequest struct {
+	UserID        string `json:"user_id"`
+	SlackMemberID string `json:"slack_member_id"`
+}
+
+type SetEmailRequest struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
+
 type CreatePRRequest struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
+	Repository      string `json:"repository"`
 }
 
 type MergePRRequest struct {
@@ -86,15 +110,17 @@ type ReassignResponse struct {
 
 type UserReviewsResponse struct {
 	UserID       string                `json:"user_id"`
-	PullRequests []PullRequestShortDTO `json:"pull_requests"`
+	PullRequests []ReviewAssignmentDTO `json:"pull_requests"`
 }
 
 func mapUserToDTO(u *domain.User) UserDTO {
 	return UserDTO{
-		UserID:   u.UserID,
-		Username: u.Username,
-		TeamName: u.TeamName,
-		IsActive: u.IsActive,
+		UserID:        u.UserID,
+		Username:      u.Username,
+		TeamName:      u.TeamName,
+		IsActive:      u.IsActive,
+		SlackMemberID: u.SlackMemberID,
+		Email:         u.Email,
 	}
 }
 
@@ -114,6 +140,7 @@ func mapTeamWithMembersToDTO(t *service.TeamWithMembers) TeamDTO {
 	return TeamDTO{
 		TeamName: t.TeamName,
 		Members:  members,
+		Version:  t.Version,
 	}
 }
 
@@ -122,10 +149,12 @@ func mapPRToDTO(pr *domain.PullRequest) PullRequestDTO {
 		PullRequestID:     pr.PullRequestID,
 		PullRequestName:   pr.PullRequestName,
 		AuthorID:          pr.AuthorID,
+		Repository:        pr.Repository,
 		Status:            pr.Status.String(),
 		AssignedReviewers: pr.AssignedReviewers,
 		CreatedAt:         pr.CreatedAt,
 		MergedAt:          pr.MergedAt,
+		Version:           pr.Version,
 	}
 }
 
@@ -144,12 +173,26 @@ type ReviewerStatDTO struct {
 }
 
 type ReviewerStatsResponse struct {
-	Reviewers []ReviewerStatDTO `json:"reviewers"`
+	Reviewers []ReviewerStatDTO      `json:"reviewers"`
+	Series    []ReviewerStatPointDTO `json:"series,omitempty"`
 }
 
 type PRStatsResponse struct {
-	Open   int `json:"open"`
-	Merged int `json:"merged"`
+	Open   int              `json:"open"`
+	Merged int              `json:"merged"`
+	Series []PRStatPointDTO `json:"series,omitempty"`
+}
+
+type ReviewerStatPointDTO struct {
+	Period           string `json:"period"`
+	UserID           string `json:"user_id"`
+	AssignmentsCount int    `json:"assignments_count"`
+}
+
+type PRStatPointDTO struct {
+	Period  string `json:"period"`
+	Created int    `json:"created"`
+	Merged  int    `json:"merged"`
 }
 
 type DeactivateUsersRequest struct {
@@ -158,7 +201,629 @@ type DeactivateUsersRequest struct {
 }
 
 type DeactivateUsersResponse struct {
-	TeamName        string `json:"team_name"`
+	TeamName         string `json:"team_name"`
 	DeactivatedCount int    `json:"deactivated_count"`
 	AffectedPRCount  int    `json:"affected_pr_count"`
-}
\ No newline at end of file
+}
+type ExclusionPairRequest struct {
+	TeamName    string `json:"team_name"`
+	UserID      string `json:"user_id"`
+	OtherUserID string `json:"other_user_id"`
+}
+
+type ExclusionPairDTO struct {
+	UserID      string `json:"user_id"`
+	OtherUserID string `json:"other_user_id"`
+}
+
+type ExclusionsResponse struct {
+	TeamName   string             `json:"team_name"`
+	Exclusions []ExclusionPairDTO `json:"exclusions"`
+}
+
+type NeverAssignRequest struct {
+	AuthorID   string `json:"author_id"`
+	ReviewerID string `json:"reviewer_id"`
+}
+
+type NeverAssignResponse struct {
+	AuthorID    string   `json:"author_id"`
+	ReviewerIDs []string `json:"reviewer_ids"`
+}
+
+func mapExclusionPairToDTO(p *domain.ExclusionPair) ExclusionPairDTO {
+	return ExclusionPairDTO{
+		UserID:      p.UserA,
+		OtherUserID: p.UserB,
+	}
+}
+
+type DeclineReviewRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+	Reason        string `json:"reason"`
+	Comment       string `json:"comment"`
+}
+
+type DeclineResponse struct {
+	PR         PullRequestDTO `json:"pr"`
+	ReplacedBy string         `json:"replaced_by,omitempty"`
+}
+
+type SubmitReviewRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+	Verdict       string `json:"verdict"`
+	Comment       string `json:"comment"`
+}
+
+type ReviewResponse struct {
+	PullRequestID string     `json:"pull_request_id"`
+	Review        PREventDTO `json:"review"`
+}
+
+type PREventDTO struct {
+	EventID    int64     `json:"event_id"`
+	Type       string    `json:"type"`
+	ActorID    string    `json:"actor_id,omitempty"`
+	UserID     string    `json:"user_id,omitempty"`
+	ReplacedBy string    `json:"replaced_by,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Comment    string    `json:"comment,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type FeedEventDTO struct {
+	PREventDTO
+	PullRequestID string `json:"pull_request_id"`
+	AuthorID      string `json:"author_id"`
+	TeamName      string `json:"team_name"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+type APIKeyIDRequest struct {
+	KeyID string `json:"key_id"`
+}
+
+type APIKeyDTO struct {
+	KeyID      string     `json:"key_id"`
+	TenantID   string     `json:"tenant_id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RotatedAt  *time.Time `json:"rotated_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type APIKeyResponse struct {
+	Key APIKeyDTO `json:"key"`
+}
+
+// APIKeySecretResponse is the only response that contains the secret of a key.
+type APIKeySecretResponse struct {
+	Key    APIKeyDTO `json:"key"`
+	Secret string    `json:"secret"`
+}
+
+type APIKeysResponse struct {
+	Keys []APIKeyDTO `json:"keys"`
+}
+
+func mapAPIKeyToDTO(k *domain.APIKey) APIKeyDTO {
+	scopes := make([]string, len(k.Scopes))
+	for i, s := range k.Scopes {
+		scopes[i] = string(s)
+	}
+	return APIKeyDTO{
+		KeyID:      k.KeyID,
+		TenantID:   k.TenantID,
+		Name:       k.Name,
+		Scopes:     scopes,
+		CreatedAt:  k.CreatedAt,
+		RotatedAt:  k.RotatedAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
+
+type TenantDTO struct {
+	TenantID  string    `json:"tenant_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TenantsResponse struct {
+	Tenants []TenantDTO `json:"tenants"`
+}
+
+func mapTenantToDTO(t *domain.Tenant) TenantDTO {
+	return TenantDTO{
+		TenantID:  t.TenantID,
+		Name:      t.Name,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+type RoleAssignmentDTO struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name,omitempty"`
+	Role     string `json:"role"`
+}
+
+type RemoveRoleRequest struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+}
+
+type RolesResponse struct {
+	Roles []RoleAssignmentDTO `json:"roles"`
+}
+
+func mapRoleAssignmentToDTO(a *domain.RoleAssignment) RoleAssignmentDTO {
+	return RoleAssignmentDTO{
+		UserID:   a.UserID,
+		TeamName: a.TeamName,
+		Role:     string(a.Role),
+	}
+}
+
+type AuditEntryDTO struct {
+	EntryID    int64          `json:"entry_id"`
+	Actor      string         `json:"actor,omitempty"`
+	Action     string         `json:"action"`
+	TargetType string         `json:"target_type"`
+	TargetID   string         `json:"target_id"`
+	Before     map[string]any `json:"before"`
+	After      map[string]any `json:"after"`
+	RequestID  string         `json:"request_id,omitempty"`
+	SourceIP   string         `json:"source_ip,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
+
+// AuditLogResponse is one page of the audit log. NextAfterID is the after_id of the
+// next page and is left out when the page is empty.
+type AuditLogResponse struct {
+	Entries     []AuditEntryDTO `json:"entries"`
+	NextAfterID *int64          `json:"next_after_id,omitempty"`
+}
+
+func mapAuditEntryToDTO(e *domain.AuditEntry) AuditEntryDTO {
+	return AuditEntryDTO{
+		EntryID:    e.EntryID,
+		Actor:      e.Actor,
+		Action:     string(e.Action),
+		TargetType: string(e.TargetType),
+		TargetID:   e.TargetID,
+		Before:     e.Before,
+		After:      e.After,
+		RequestID:  e.RequestID,
+		SourceIP:   e.SourceIP,
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+// ReviewQueueAuthMessage is the first message a /ws/reviews client sends.
+type ReviewQueueAuthMessage struct {
+	Type  string `json:"type"`
+	Token string `json:"token"`
+}
+
+// ReviewQueueSnapshotMessage carries the whole review queue right after authentication.
+type ReviewQueueSnapshotMessage struct {
+	Type         string                `json:"type"`
+	UserID       string                `json:"user_id"`
+	PullRequests []ReviewAssignmentDTO `json:"pull_requests"`
+}
+
+type ReviewQueueAddedMessage struct {
+	Type        string              `json:"type"`
+	EventID     int64               `json:"event_id"`
+	PullRequest ReviewAssignmentDTO `json:"pull_request"`
+}
+
+type ReviewQueueRemovedMessage struct {
+	Type          string `json:"type"`
+	EventID       int64  `json:"event_id"`
+	PullRequestID string `json:"pull_request_id"`
+	Reason        string `json:"reason"`
+}
+
+type ReviewQueueErrorMessage struct {
+	Type  string      `json:"type"`
+	Error ErrorDetail `json:"error"`
+}
+
+type TimelineResponse struct {
+	PullRequestID string       `json:"pull_request_id"`
+	Version       int64        `json:"version"`
+	Events        []PREventDTO `json:"events"`
+}
+
+type DeclineStatDTO struct {
+	UserID      string         `json:"user_id"`
+	Assignments int            `json:"assignments"`
+	Declines    int            `json:"declines"`
+	DeclineRate float64        `json:"decline_rate"`
+	ByReason    map[string]int `json:"by_reason"`
+}
+
+type DeclineStatsResponse struct {
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Reviewers []DeclineStatDTO `json:"reviewers"`
+}
+
+func mapFeedEventToDTO(e *domain.FeedEvent) FeedEventDTO {
+	return FeedEventDTO{
+		PREventDTO:    mapPREventToDTO(e.PREvent),
+		PullRequestID: e.PRID,
+		AuthorID:      e.AuthorID,
+		TeamName:      e.TeamName,
+	}
+}
+
+func mapPREventToDTO(e *domain.PREvent) PREventDTO {
+	return PREventDTO{
+		EventID:    e.EventID,
+		Type:       string(e.Type),
+		ActorID:    e.ActorID,
+		UserID:     e.UserID,
+		ReplacedBy: e.ReplacedBy,
+		Reason:     e.Reason,
+		Comment:    e.Comment,
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+func mapDeclineStatToDTO(s *domain.DeclineStat) DeclineStatDTO {
+	byReason := make(map[string]int, len(s.ByReason))
+	for reason, count := range s.ByReason {
+		byReason[string(reason)] = count
+	}
+	return DeclineStatDTO{
+		UserID:      s.UserID,
+		Assignments: s.Assignments,
+		Declines:    s.Declines,
+		DeclineRate: s.Rate(),
+		ByReason:    byReason,
+	}
+}
+
+type ReviewerOverrideRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+	ActorID       string `json:"actor_id"`
+}
+
+type SetReviewersRequest struct {
+	PullRequestID string   `json:"pull_request_id"`
+	ReviewerIDs   []string `json:"reviewer_ids"`
+	ActorID       string   `json:"actor_id"`
+}
+
+type MemberFairnessDTO struct {
+	UserID        string  `json:"user_id"`
+	ActiveDays    float64 `json:"active_days"`
+	Assignments   int     `json:"assignments"`
+	ExpectedShare float64 `json:"expected_share"`
+	ActualShare   float64 `json:"actual_share"`
+}
+
+type FairnessResponse struct {
+	TeamName         string              `json:"team_name"`
+	From             time.Time           `json:"from"`
+	To               time.Time           `json:"to"`
+	TotalAssignments int                 `json:"total_assignments"`
+	MeanAssignments  float64             `json:"assignments_per_member"`
+	StdDev           float64             `json:"std_dev"`
+	Gini             float64             `json:"gini"`
+	MaxMinRatio      *float64            `json:"max_min_ratio"`
+	Members          []MemberFairnessDTO `json:"members"`
+}
+
+func mapFairnessToDTO(teamName string, from, to time.Time, report *domain.FairnessReport) FairnessResponse {
+	members := make([]MemberFairnessDTO, len(report.Members))
+	for i, m := range report.Members {
+		members[i] = MemberFairnessDTO{
+			UserID:        m.UserID,
+			ActiveDays:    m.ActiveDays,
+			Assignments:   m.Assignments,
+			ExpectedShare: m.ExpectedShare,
+			ActualShare:   m.ActualShare,
+		}
+	}
+	return FairnessResponse{
+		TeamName:         teamName,
+		From:             from,
+		To:               to,
+		TotalAssignments: report.TotalAssignments,
+		MeanAssignments:  report.MeanAssignments,
+		StdDev:           report.StdDev,
+		Gini:             report.Gini,
+		MaxMinRatio:      report.MaxMinRatio,
+		Members:          members,
+	}
+}
+
+// statsPeriodLayout formats the start of a day, week or month bucket.
+const statsPeriodLayout = "2006-01-02"
+
+func mapReviewerSeriesToDTO(points []domain.ReviewerStatPoint) []ReviewerStatPointDTO {
+	series := make([]ReviewerStatPointDTO, len(points))
+	for i, p := range points {
+		series[i] = ReviewerStatPointDTO{
+			Period:           p.Period.Format(statsPeriodLayout),
+			UserID:           p.UserID,
+			AssignmentsCount: p.Assignments,
+		}
+	}
+	return series
+}
+
+func mapPRSeriesToDTO(points []domain.PRStatPoint) []PRStatPointDTO {
+	series := make([]PRStatPointDTO, len(points))
+	for i, p := range points {
+		series[i] = PRStatPointDTO{
+			Period:  p.Period.Format(statsPeriodLayout),
+			Created: p.Created,
+			Merged:  p.Merged,
+		}
+	}
+	return series
+}
+
+type PercentilesDTO struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+}
+
+type CycleTimeSummaryDTO struct {
+	PullRequests           int            `json:"pull_requests"`
+	TimeToFirstReviewHours PercentilesDTO `json:"time_to_first_review_hours"`
+	TimeToApprovalHours    PercentilesDTO `json:"time_to_approval_hours"`
+	TimeToMergeHours       PercentilesDTO `json:"time_to_merge_hours"`
+	Reassignments          PercentilesDTO `json:"reassignments"`
+}
+
+type CycleTimeGroupDTO struct {
+	Key string `json:"key"`
+	CycleTimeSummaryDTO
+}
+
+type CycleTimeResponse struct {
+	From    time.Time           `json:"from"`
+	To      time.Time           `json:"to"`
+	By      string              `json:"by"`
+	Overall CycleTimeSummaryDTO `json:"overall"`
+	Groups  []CycleTimeGroupDTO `json:"groups"`
+}
+
+type PRCycleTimeResponse struct {
+	PullRequestID          string     `json:"pull_request_id"`
+	TeamName               string     `json:"team_name"`
+	Repository             string     `json:"repository,omitempty"`
+	CreatedAt              time.Time  `json:"createdAt"`
+	FirstReviewAt          *time.Time `json:"first_review_at"`
+	ApprovedAt             *time.Time `json:"approved_at"`
+	MergedAt               *time.Time `json:"mergedAt"`
+	TimeToFirstReviewHours *float64   `json:"time_to_first_review_hours"`
+	TimeToApprovalHours    *float64   `json:"time_to_approval_hours"`
+	TimeToMergeHours       *float64   `json:"time_to_merge_hours"`
+	Reassignments          int        `json:"reassignments"`
+}
+
+func mapPercentilesToDTO(p domain.Percentiles) PercentilesDTO {
+	return PercentilesDTO{Count: p.Count, P50: p.P50, P90: p.P90, P99: p.P99}
+}
+
+func mapCycleTimeSummaryToDTO(s domain.CycleTimeSummary) CycleTimeSummaryDTO {
+	return CycleTimeSummaryDTO{
+		PullRequests:           s.PullRequests,
+		TimeToFirstReviewHours: mapPercentilesToDTO(s.TimeToFirstReview),
+		TimeToApprovalHours:    mapPercentilesToDTO(s.TimeToApproval),
+		TimeToMergeHours:       mapPercentilesToDTO(s.TimeToMerge),
+		Reassignments:          mapPercentilesToDTO(s.Reassignments),
+	}
+}
+
+func mapCycleTimeReportToDTO(from, to time.Time, by domain.CycleTimeDimension, report *domain.CycleTimeReport) CycleTimeResponse {
+	groups := make([]CycleTimeGroupDTO, len(report.Groups))
+	for i, g := range report.Groups {
+		groups[i] = CycleTimeGroupDTO{
+			Key:                 g.Key,
+			CycleTimeSummaryDTO: mapCycleTimeSummaryToDTO(g.CycleTimeSummary),
+		}
+	}
+	return CycleTimeResponse{
+		From:    from,
+		To:      to,
+		By:      string(by),
+		Overall: mapCycleTimeSummaryToDTO(report.Overall),
+		Groups:  groups,
+	}
+}
+
+func mapPRCycleTimeToDTO(c *domain.PRCycleTime) PRCycleTimeResponse {
+	hoursSince := func(at *time.Time) *float64 {
+		if at == nil {
+			return nil
+		}
+		h := at.Sub(c.CreatedAt).Hours()
+		return &h
+	}
+	return PRCycleTimeResponse{
+		PullRequestID:          c.PRID,
+		TeamName:               c.TeamName,
+		Repository:             c.Repository,
+		CreatedAt:              c.CreatedAt,
+		FirstReviewAt:          c.FirstReviewAt,
+		ApprovedAt:             c.ApprovedAt,
+		MergedAt:               c.MergedAt,
+		TimeToFirstReviewHours: hoursSince(c.FirstReviewAt),
+		TimeToApprovalHours:    hoursSince(c.ApprovedAt),
+		TimeToMergeHours:       hoursSince(c.MergedAt),
+		Reassignments:          c.Reassignments,
+	}
+}
+
+type TeamSettingsDTO struct {
+	TeamName            string `json:"team_name"`
+	ReviewSLAMinutes    int    `json:"review_sla_minutes"`
+	StaleTimeoutMinutes int    `json:"stale_timeout_minutes"`
+	Timezone            string `json:"timezone"`
+	WorkDayStart        string `json:"work_day_start"`
+	WorkDayEnd          string `json:"work_day_end"`
+	// Version is only set in responses, requests pass it in If-Match.
+	Version int64 `json:"version"`
+}
+
+func mapTeamSettingsToDTO(s *domain.TeamSettings) TeamSettingsDTO {
+	clock := func(minutes int) string {
+		return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+	}
+	return TeamSettingsDTO{
+		TeamName:            s.TeamName,
+		ReviewSLAMinutes:    int(s.ReviewSLA / time.Minute),
+		StaleTimeoutMinutes: int(s.StaleTimeout / time.Minute),
+		Timezone:            s.Timezone,
+		WorkDayStart:        clock(s.WorkDayStart),
+		WorkDayEnd:          clock(s.WorkDayEnd),
+		Version:             s.Version,
+	}
+}
+
+type ReminderPreferencesRequest struct {
+	UserID           string `json:"user_id"`
+	Enabled          *bool  `json:"enabled"`
+	FrequencyMinutes int    `json:"frequency_minutes"`
+	QuietStart       string `json:"quiet_start"`
+	QuietEnd         string `json:"quiet_end"`
+	Channel          string `json:"channel"`
+}
+
+type ReminderPreferencesDTO struct {
+	UserID           string `json:"user_id"`
+	Enabled          bool   `json:"enabled"`
+	FrequencyMinutes int    `json:"frequency_minutes"`
+	QuietStart       string `json:"quiet_start"`
+	QuietEnd         string `json:"quiet_end"`
+	Channel          string `json:"channel"`
+}
+
+func mapReminderPreferencesToDTO(p *domain.ReminderPreferences) ReminderPreferencesDTO {
+	clock := func(minutes int) string {
+		return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+	}
+	return ReminderPreferencesDTO{
+		UserID:           p.UserID,
+		Enabled:          p.Enabled,
+		FrequencyMinutes: int(p.Frequency / time.Minute),
+		QuietStart:       clock(p.QuietStart),
+		QuietEnd:         clock(p.QuietEnd),
+		Channel:          string(p.Channel),
+	}
+}
+
+type MessageTemplateDTO struct {
+	TeamName string `json:"team_name"`
+	Kind     string `json:"kind"`
+	Template string `json:"template"`
+}
+
+type MessageTemplatesResponse struct {
+	TeamName  string               `json:"team_name"`
+	Templates []MessageTemplateDTO `json:"templates"`
+}
+
+func mapMessageTemplateToDTO(t *domain.MessageTemplate) MessageTemplateDTO {
+	return MessageTemplateDTO{
+		TeamName: t.TeamName,
+		Kind:     string(t.Kind),
+		Template: t.Body,
+	}
+}
+
+func mapReviewAssignmentToDTO(a *domain.ReviewAssignment) ReviewAssignmentDTO {
+	return ReviewAssignmentDTO{
+		PullRequestShortDTO: mapPRToShortDTO(a.PullRequest),
+		ReviewDeadline:      a.Deadline,
+		Overdue:             a.Overdue,
+	}
+}
+
+type SLAStatDTO struct {
+	Assignments int     `json:"assignments"`
+	Breaches    int     `json:"breaches"`
+	BreachRate  float64 `json:"breach_rate"`
+}
+
+type TeamSLAStatDTO struct {
+	TeamName string `json:"team_name"`
+	SLAStatDTO
+}
+
+type UserSLAStatDTO struct {
+	UserID string `json:"user_id"`
+	SLAStatDTO
+}
+
+type SLABreachDTO struct {
+	PullRequestID string     `json:"pull_request_id"`
+	UserID        string     `json:"user_id"`
+	AssignedAt    time.Time  `json:"assigned_at"`
+	Deadline      time.Time  `json:"deadline"`
+	RespondedAt   *time.Time `json:"responded_at"`
+	Open          bool       `json:"open"`
+}
+
+type SLAReportResponse struct {
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	Teams    []TeamSLAStatDTO `json:"teams"`
+	Users    []UserSLAStatDTO `json:"users"`
+	Breaches []SLABreachDTO   `json:"breaches"`
+}
+
+func mapSLAStatToDTO(s *domain.SLAStat) SLAStatDTO {
+	return SLAStatDTO{
+		Assignments: s.Assignments,
+		Breaches:    s.Breaches,
+		BreachRate:  s.BreachRate(),
+	}
+}
+
+func mapSLAReportToDTO(from, to time.Time, report *domain.SLAReport) SLAReportResponse {
+	teams := make([]TeamSLAStatDTO, len(report.Teams))
+	for i, s := range report.Teams {
+		teams[i] = TeamSLAStatDTO{TeamName: s.Key, SLAStatDTO: mapSLAStatToDTO(s)}
+	}
+	users := make([]UserSLAStatDTO, len(report.Users))
+	for i, s := range report.Users {
+		users[i] = UserSLAStatDTO{UserID: s.Key, SLAStatDTO: mapSLAStatToDTO(s)}
+	}
+	breaches := make([]SLABreachDTO, len(report.Breaches))
+	for i, a := range report.Breaches {
+		breaches[i] = SLABreachDTO{
+			PullRequestID: a.PRID,
+			UserID:        a.ReviewerID,
+			AssignedAt:    a.AssignedAt,
+			Deadline:      a.Deadline,
+			RespondedAt:   a.RespondedAt,
+			Open:          a.IsOpen(),
+		}
+	}
+	return SLAReportResponse{
+		From:     from,
+		To:       to,
+		Teams:    teams,
+		Users:    users,
+		Breaches: breaches,
+	}
+}
@@ -42,15 +42,32 @@ func respondError(w http.ResponseWriter, err error, logger *slog.Logger) {
 
 func mapDomainErrorToHTTP(code domain.ErrorCode) int {
 	switch code {
-	case domain.ErrCodeTeamExists:
+	case domain.ErrCodeTeamExists,
+		domain.ErrCodeNotTeamMember,
+		domain.ErrCodeInvalidReviewer,
+		domain.ErrCodeInvalidSettings,
+		domain.ErrCodeInvalidRole,
+		domain.ErrCodeInvalidTenant:
 		return http.StatusBadRequest
 	case domain.ErrCodePRExists,
 		domain.ErrCodePRMerged,
 		domain.ErrCodeNotAssigned,
-		domain.ErrCodeNoCandidate:
+		domain.ErrCodeNoCandidate,
+		domain.ErrCodeUnassignable,
+		domain.ErrCodeDeclineQuotaExceeded,
+		domain.ErrCodeAlreadyAssigned,
+		domain.ErrCodeTooManyReviewers,
+		domain.ErrCodeAPIKeyRevoked,
+		domain.ErrCodeTenantExists:
 		return http.StatusConflict
+	case domain.ErrCodeUnauthorized:
+		return http.StatusUnauthorized
+	case domain.ErrCodeForbidden:
+		return http.StatusForbidden
 	case domain.ErrCodeNotFound:
 		return http.StatusNotFound
+	case domain.ErrCodeVersionMismatch:
+		return http.StatusPreconditionFailed
 	default:
 		return http.StatusInternalServerError
 	}
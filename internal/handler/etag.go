@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mivihan/Pull_Request_service/internal/service"
+)
+
+// setETag exposes the version of the pull request or team in the response as a strong
+// entity tag, for clients to send back in If-Match.
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// ifMatch makes the change conditional on the If-Match header: it only goes through
+// when the pull request or team is still at one of the listed versions, otherwise it
+// fails with 412. "*" and a missing header match any version. Weak or malformed tags
+// never match, as If-Match compares strongly.
+func ifMatch(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := strings.TrimSpace(r.Header.Get("If-Match"))
+		if header == "" || header == "*" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var versions []int64
+		for _, tag := range strings.Split(header, ",") {
+			tag = strings.TrimSpace(tag)
+			if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+				continue
+			}
+			if version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64); err == nil {
+				versions = append(versions, version)
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(service.WithExpectedVersions(r.Context(), versions)))
+	})
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/middleware"
+)
+
+// IdempotencyConfig enables Idempotency-Key support when Store is set and TTL positive.
+// Keys are scoped to the client as named by rateLimitKey.
+type IdempotencyConfig struct {
+	Store middleware.IdempotencyStore
+	TTL   time.Duration
+}
+
+func (c IdempotencyConfig) enabled() bool {
+	return c.Store != nil && c.TTL > 0
+}
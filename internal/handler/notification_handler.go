@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/service"
+)
+
+type NotificationHandler struct {
+	notificationService service.NotificationService
+	logger              *slog.Logger
+}
+
+func NewNotificationHandler(notificationService service.NotificationService, logger *slog.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+func (h *NotificationHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "team_name query parameter is required",
+			},
+		})
+		return
+	}
+
+	templates, err := h.notificationService.GetTemplates(r.Context(), teamName)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	result := make([]MessageTemplateDTO, len(templates))
+	for i, t := range templates {
+		result[i] = mapMessageTemplateToDTO(t)
+	}
+
+	respondJSON(w, http.StatusOK, MessageTemplatesResponse{
+		TeamName:  teamName,
+		Templates: result,
+	})
+}
+
+func (h *NotificationHandler) SetTemplate(w http.ResponseWriter, r *http.Request) {
+	var req MessageTemplateDTO
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if req.TeamName == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "team_name is required",
+			},
+		})
+		return
+	}
+
+	kind, err := domain.ParseNotificationKind(req.Kind)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "kind must be one of REVIEW_REMINDER, REVIEWER_ASSIGNED, REVIEWER_REPLACED, PR_MERGED",
+			},
+		})
+		return
+	}
+
+	tmpl, err := h.notificationService.SetTemplate(r.Context(), &domain.MessageTemplate{
+		TeamName: req.TeamName,
+		Kind:     kind,
+		Body:     req.Template,
+	})
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, mapMessageTemplateToDTO(tmpl))
+}
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/mivihan/Pull_Request_service/internal/domain"
 	"github.com/mivihan/Pull_Request_service/internal/service"
 )
 
@@ -35,12 +36,13 @@ func (h *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, err := h.prService.CreatePR(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID)
+	pr, err := h.prService.CreatePR(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID, req.Repository)
 	if err != nil {
 		respondError(w, err, h.logger)
 		return
 	}
 
+	setETag(w, pr.Version)
 	respondJSON(w, http.StatusCreated, PRResponse{
 		PR: mapPRToDTO(pr),
 	})
@@ -68,6 +70,7 @@ func (h *PRHandler) MergePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, pr.Version)
 	respondJSON(w, http.StatusOK, PRResponse{
 		PR: mapPRToDTO(pr),
 	})
@@ -95,8 +98,208 @@ func (h *PRHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, pr.Version)
 	respondJSON(w, http.StatusOK, ReassignResponse{
 		PR:         mapPRToDTO(pr),
 		ReplacedBy: replacedBy,
 	})
 }
+
+func (h *PRHandler) DeclineReview(w http.ResponseWriter, r *http.Request) {
+	var req DeclineReviewRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+	req.UserID = callerUserID(r, req.UserID)
+
+	if req.PullRequestID == "" || req.UserID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "pull_request_id and user_id are required",
+			},
+		})
+		return
+	}
+
+	reason, err := domain.ParseDeclineReason(req.Reason)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "reason must be one of BUSY, NO_CONTEXT, CONFLICT",
+			},
+		})
+		return
+	}
+
+	pr, replacedBy, err := h.prService.DeclineReview(r.Context(), req.PullRequestID, req.UserID, reason, req.Comment)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	setETag(w, pr.Version)
+	respondJSON(w, http.StatusOK, DeclineResponse{
+		PR:         mapPRToDTO(pr),
+		ReplacedBy: replacedBy,
+	})
+}
+
+func (h *PRHandler) SubmitReview(w http.ResponseWriter, r *http.Request) {
+	var req SubmitReviewRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+	req.UserID = callerUserID(r, req.UserID)
+
+	if req.PullRequestID == "" || req.UserID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "pull_request_id and user_id are required",
+			},
+		})
+		return
+	}
+
+	verdict, err := domain.ParseReviewVerdict(req.Verdict)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "verdict must be one of APPROVED, COMMENTED, CHANGES_REQUESTED",
+			},
+		})
+		return
+	}
+
+	event, err := h.prService.SubmitReview(r.Context(), req.PullRequestID, req.UserID, verdict, req.Comment)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, ReviewResponse{
+		PullRequestID: req.PullRequestID,
+		Review:        mapPREventToDTO(event),
+	})
+}
+
+func (h *PRHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "pull_request_id query parameter is required",
+			},
+		})
+		return
+	}
+
+	pr, events, err := h.prService.GetTimeline(r.Context(), prID)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	eventDTOs := make([]PREventDTO, len(events))
+	for i, e := range events {
+		eventDTOs[i] = mapPREventToDTO(e)
+	}
+
+	setETag(w, pr.Version)
+	respondJSON(w, http.StatusOK, TimelineResponse{
+		PullRequestID: prID,
+		Version:       pr.Version,
+		Events:        eventDTOs,
+	})
+}
+
+func (h *PRHandler) AddReviewer(w http.ResponseWriter, r *http.Request) {
+	var req ReviewerOverrideRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+	req.ActorID = callerUserID(r, req.ActorID)
+
+	if req.PullRequestID == "" || req.UserID == "" || req.ActorID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "pull_request_id, user_id and actor_id are required",
+			},
+		})
+		return
+	}
+
+	pr, err := h.prService.AddReviewer(r.Context(), req.PullRequestID, req.UserID, req.ActorID)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	setETag(w, pr.Version)
+	respondJSON(w, http.StatusOK, PRResponse{
+		PR: mapPRToDTO(pr),
+	})
+}
+
+func (h *PRHandler) RemoveReviewer(w http.ResponseWriter, r *http.Request) {
+	var req ReviewerOverrideRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+	req.ActorID = callerUserID(r, req.ActorID)
+
+	if req.PullRequestID == "" || req.UserID == "" || req.ActorID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "pull_request_id, user_id and actor_id are required",
+			},
+		})
+		return
+	}
+
+	pr, err := h.prService.RemoveReviewer(r.Context(), req.PullRequestID, req.UserID, req.ActorID)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	setETag(w, pr.Version)
+	respondJSON(w, http.StatusOK, PRResponse{
+		PR: mapPRToDTO(pr),
+	})
+}
+
+func (h *PRHandler) SetReviewers(w http.ResponseWriter, r *http.Request) {
+	var req SetReviewersRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+	req.ActorID = callerUserID(r, req.ActorID)
+
+	if req.PullRequestID == "" || req.ActorID == "" || req.ReviewerIDs == nil {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "pull_request_id, reviewer_ids and actor_id are required",
+			},
+		})
+		return
+	}
+
+	pr, err := h.prService.SetReviewers(r.Context(), req.PullRequestID, req.ReviewerIDs, req.ActorID)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	setETag(w, pr.Version)
+	respondJSON(w, http.StatusOK, PRResponse{
+		PR: mapPRToDTO(pr),
+	})
+}
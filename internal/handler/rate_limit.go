@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/mivihan/Pull_Request_service/internal/auth"
+	"github.com/mivihan/Pull_Request_service/internal/middleware"
+	"github.com/mivihan/Pull_Request_service/internal/ratelimit"
+)
+
+// RateLimitConfig enables rate limiting when Limiter is set and Policy limits any route.
+// AddressPolicy limits client addresses before authentication when it limits any route.
+type RateLimitConfig struct {
+	Limiter       ratelimit.Limiter
+	Policy        *ratelimit.Policy
+	AddressPolicy *ratelimit.Policy
+}
+
+func (c RateLimitConfig) enabled() bool {
+	return c.Limiter != nil && c.Policy != nil && !c.Policy.Empty()
+}
+
+func (c RateLimitConfig) limitsAddresses() bool {
+	return c.Limiter != nil && c.AddressPolicy != nil && !c.AddressPolicy.Empty()
+}
+
+// rateLimitKey names the client a request is counted against: its credential when it
+// authenticated, its address otherwise.
+func rateLimitKey(r *http.Request) string {
+	if principal, ok := auth.PrincipalFrom(r.Context()); ok {
+		return principal.Subject
+	}
+	return "ip:" + middleware.ClientIP(r)
+}
+
+// addressKey names the client by its address alone, apart from the buckets of
+// rateLimitKey.
+func addressKey(r *http.Request) string {
+	return "addr:" + middleware.ClientIP(r)
+}
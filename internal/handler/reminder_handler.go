@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/service"
+)
+
+type ReminderHandler struct {
+	reminderService service.ReminderService
+	logger          *slog.Logger
+}
+
+func NewReminderHandler(reminderService service.ReminderService, logger *slog.Logger) *ReminderHandler {
+	return &ReminderHandler{
+		reminderService: reminderService,
+		logger:          logger,
+	}
+}
+
+func (h *ReminderHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "user_id query parameter is required",
+			},
+		})
+		return
+	}
+
+	prefs, err := h.reminderService.GetPreferences(r.Context(), userID)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, mapReminderPreferencesToDTO(prefs))
+}
+
+func (h *ReminderHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	var req ReminderPreferencesRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if req.UserID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "user_id is required",
+			},
+		})
+		return
+	}
+
+	prefs := domain.DefaultReminderPreferences(req.UserID)
+	if req.Enabled != nil {
+		prefs.Enabled = *req.Enabled
+	}
+	if req.FrequencyMinutes != 0 {
+		prefs.Frequency = time.Duration(req.FrequencyMinutes) * time.Minute
+	}
+	if req.Channel != "" {
+		prefs.Channel = domain.NotificationChannel(req.Channel)
+	}
+
+	var err error
+	if req.QuietStart != "" {
+		if prefs.QuietStart, err = parseClock(req.QuietStart); err != nil {
+			respondInvalidClock(w, "quiet_start")
+			return
+		}
+	}
+	if req.QuietEnd != "" {
+		if prefs.QuietEnd, err = parseClock(req.QuietEnd); err != nil {
+			respondInvalidClock(w, "quiet_end")
+			return
+		}
+	}
+
+	updated, err := h.reminderService.UpdatePreferences(r.Context(), prefs)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, mapReminderPreferencesToDTO(updated))
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/auth"
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/service"
+	"github.com/mivihan/Pull_Request_service/internal/stream"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+	"github.com/mivihan/Pull_Request_service/internal/websocket"
+)
+
+const (
+	queueAuthTimeout  = 10 * time.Second
+	queuePingInterval = 30 * time.Second
+	queuePongWait     = 2 * queuePingInterval
+	queueWriteWait    = 10 * time.Second
+)
+
+type ReviewQueueHandler struct {
+	userService   service.UserService
+	broker        *stream.Broker
+	authenticator auth.Authenticator
+	logger        *slog.Logger
+}
+
+func NewReviewQueueHandler(userService service.UserService, broker *stream.Broker, authenticator auth.Authenticator, logger *slog.Logger) *ReviewQueueHandler {
+	return &ReviewQueueHandler{
+		userService:   userService,
+		broker:        broker,
+		authenticator: authenticator,
+		logger:        logger,
+	}
+}
+
+// Serve keeps a WebSocket client in sync with its review queue. The client
+// authenticates with its first message, receives a snapshot of the queue and then an
+// "added" or "removed" message per change. A client that falls behind is disconnected
+// with code 1013 and is expected to reconnect for a fresh snapshot.
+func (h *ReviewQueueHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		h.logger.Debug("websocket upgrade failed", "error", err, "remote_addr", r.RemoteAddr)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	code, reason := h.serve(ctx, conn)
+	conn.Close(code, reason)
+}
+
+func (h *ReviewQueueHandler) serve(ctx context.Context, conn *websocket.Conn) (int, string) {
+	conn.IdleTimeout = queueAuthTimeout
+	id, err := h.authenticate(ctx, conn)
+	if err != nil {
+		h.writeError(conn, "UNAUTHORIZED", "authentication failed")
+		return websocket.ClosePolicyViolation, "authentication failed"
+	}
+	userID := id.UserID
+	ctx = tenant.WithID(ctx, id.TenantID)
+
+	// Subscribing before the snapshot leaves no gap; events the snapshot already
+	// reflects are ignored by the queue.
+	sub, err := h.broker.Subscribe(ctx, domain.EventFilter{TenantID: id.TenantID})
+	if err != nil {
+		if errors.Is(err, stream.ErrClosed) {
+			return websocket.CloseGoingAway, "server is shutting down"
+		}
+		h.logger.Error("failed to subscribe to events", "error", err)
+		return websocket.CloseInternalError, "internal error"
+	}
+	defer sub.Close()
+
+	assignments, err := h.userService.GetReviews(ctx, userID)
+	if err != nil {
+		return h.fail(conn, err)
+	}
+	snapshot := ReviewQueueSnapshotMessage{
+		Type:         "snapshot",
+		UserID:       userID,
+		PullRequests: []ReviewAssignmentDTO{},
+	}
+	var prIDs []string
+	for _, a := range assignments {
+		if a.IsMerged() {
+			continue
+		}
+		prIDs = append(prIDs, a.PullRequestID)
+		snapshot.PullRequests = append(snapshot.PullRequests, mapReviewAssignmentToDTO(a))
+	}
+	queue := domain.NewReviewQueue(userID, prIDs)
+	if err := h.write(conn, snapshot); err != nil {
+		return websocket.CloseGoingAway, ""
+	}
+
+	// Clients have nothing to say after authenticating; reading only answers pings and
+	// notices when the client goes away.
+	conn.IdleTimeout = queuePongWait
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(queuePingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-readDone:
+			return websocket.CloseNormal, ""
+		case <-ping.C:
+			if err := conn.WritePing(time.Now().Add(queueWriteWait)); err != nil {
+				return websocket.CloseGoingAway, ""
+			}
+		case e, ok := <-sub.Events():
+			if !ok {
+				if sub.Lagged() {
+					h.logger.Warn("review queue subscriber too slow, disconnecting", "user_id", userID)
+					return websocket.CloseTryAgainLater, "too slow, reconnect"
+				}
+				return websocket.CloseGoingAway, "server is shutting down"
+			}
+			change := queue.Apply(e.PREvent)
+			if change == nil {
+				continue
+			}
+			msg, err := h.changeMessage(ctx, userID, change)
+			if err != nil {
+				return h.fail(conn, err)
+			}
+			if msg == nil {
+				continue
+			}
+			if err := h.write(conn, msg); err != nil {
+				return websocket.CloseGoingAway, ""
+			}
+		}
+	}
+}
+
+func (h *ReviewQueueHandler) authenticate(ctx context.Context, conn *websocket.Conn) (auth.Identity, error) {
+	op, data, err := conn.ReadMessage()
+	if err != nil {
+		return auth.Identity{}, err
+	}
+	if op != websocket.OpText {
+		return auth.Identity{}, auth.ErrInvalidToken
+	}
+
+	var msg ReviewQueueAuthMessage
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Type != "auth" {
+		return auth.Identity{}, auth.ErrInvalidToken
+	}
+
+	return h.authenticator.Authenticate(ctx, msg.Token)
+}
+
+// changeMessage builds the message for a queue change, or returns nil when there is
+// nothing worth sending.
+func (h *ReviewQueueHandler) changeMessage(ctx context.Context, userID string, change *domain.QueueChange) (any, error) {
+	if !change.Added {
+		return ReviewQueueRemovedMessage{
+			Type:          "removed",
+			EventID:       change.Event.EventID,
+			PullRequestID: change.PRID,
+			Reason:        string(change.Event.Type),
+		}, nil
+	}
+
+	a, err := h.userService.GetReview(ctx, userID, change.PRID)
+	if err != nil {
+		return nil, err
+	}
+	// A later event has already taken the pull request away again and its removal
+	// follows, so the stale assignment is not announced.
+	if a.IsMerged() || !slices.Contains(a.AssignedReviewers, userID) {
+		return nil, nil
+	}
+
+	return ReviewQueueAddedMessage{
+		Type:        "added",
+		EventID:     change.Event.EventID,
+		PullRequest: mapReviewAssignmentToDTO(a),
+	}, nil
+}
+
+// fail reports err to the client and picks the close code for it.
+func (h *ReviewQueueHandler) fail(conn *websocket.Conn, err error) (int, string) {
+	var domainErr *domain.DomainError
+	if errors.As(err, &domainErr) {
+		h.writeError(conn, string(domainErr.Code), domainErr.Message)
+		return websocket.ClosePolicyViolation, domainErr.Message
+	}
+	if errors.Is(err, context.Canceled) {
+		return websocket.CloseGoingAway, ""
+	}
+
+	h.logger.Error("review queue failed", "error", err)
+	h.writeError(conn, "INTERNAL_ERROR", "internal server error")
+	return websocket.CloseInternalError, "internal error"
+}
+
+func (h *ReviewQueueHandler) writeError(conn *websocket.Conn, code, message string) {
+	h.write(conn, ReviewQueueErrorMessage{
+		Type:  "error",
+		Error: ErrorDetail{Code: code, Message: message},
+	})
+}
+
+func (h *ReviewQueueHandler) write(conn *websocket.Conn, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return conn.WriteText(data, time.Now().Add(queueWriteWait))
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/service"
+)
+
+type RoleHandler struct {
+	roleService service.RoleService
+	logger      *slog.Logger
+}
+
+func NewRoleHandler(roleService service.RoleService, logger *slog.Logger) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		logger:      logger,
+	}
+}
+
+// ListRoles lists the team admins of team_name, or every role without it.
+func (h *RoleHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.roleService.ListRoles(r.Context(), r.URL.Query().Get("team_name"))
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	result := make([]RoleAssignmentDTO, len(roles))
+	for i, a := range roles {
+		result[i] = mapRoleAssignmentToDTO(a)
+	}
+
+	respondJSON(w, http.StatusOK, RolesResponse{Roles: result})
+}
+
+func (h *RoleHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	var req RoleAssignmentDTO
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if req.UserID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "user_id is required",
+			},
+		})
+		return
+	}
+
+	role, err := domain.ParseRole(req.Role)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "role must be admin or team_admin",
+			},
+		})
+		return
+	}
+
+	assignment, err := h.roleService.SetRole(r.Context(), &domain.RoleAssignment{
+		UserID:   req.UserID,
+		TeamName: req.TeamName,
+		Role:     role,
+	})
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, mapRoleAssignmentToDTO(assignment))
+}
+
+// RemoveRole revokes the user's role in team_name; an empty team_name revokes admin.
+func (h *RoleHandler) RemoveRole(w http.ResponseWriter, r *http.Request) {
+	var req RemoveRoleRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if req.UserID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "user_id is required",
+			},
+		})
+		return
+	}
+
+	if err := h.roleService.RemoveRole(r.Context(), req.UserID, req.TeamName); err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
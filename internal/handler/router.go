@@ -6,20 +6,37 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/mivihan/Pull_Request_service/internal/auth"
+	"github.com/mivihan/Pull_Request_service/internal/domain"
 	"github.com/mivihan/Pull_Request_service/internal/middleware"
 	"github.com/mivihan/Pull_Request_service/internal/service"
+	"github.com/mivihan/Pull_Request_service/internal/stream"
 )
 
 func NewRouter(
 	teamService service.TeamService,
 	userService service.UserService,
 	prService service.PRService,
+	constraintService service.ConstraintService,
+	statsService service.StatsService,
+	reminderService service.ReminderService,
+	notificationService service.NotificationService,
+	roleService service.RoleService,
+	auditService service.AuditService,
+	tenantService service.TenantService,
+	broker *stream.Broker,
+	authenticator auth.Authenticator,
+	authConfig AuthConfig,
+	rateLimitConfig RateLimitConfig,
+	idempotencyConfig IdempotencyConfig,
 	logger *slog.Logger,
 ) http.Handler {
 	r := chi.NewRouter()
 
+	r.Use(middleware.RequestID)
 	r.Use(middleware.Logging(logger))
 	r.Use(middleware.Recovery(logger))
+	r.Use(auditSource)
 
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -29,21 +46,111 @@ func NewRouter(
 	teamHandler := NewTeamHandler(teamService, logger)
 	userHandler := NewUserHandler(userService, logger)
 	prHandler := NewPRHandler(prService, logger)
-	statsHandler := NewStatsHandler(prService, logger)
+	statsHandler := NewStatsHandler(prService, statsService, logger)
+	constraintHandler := NewConstraintHandler(constraintService, logger)
+	reminderHandler := NewReminderHandler(reminderService, logger)
+	notificationHandler := NewNotificationHandler(notificationService, logger)
+	streamHandler := NewStreamHandler(broker, logger)
+	reviewQueueHandler := NewReviewQueueHandler(userService, broker, authenticator, logger)
+	apiKeyHandler := NewAPIKeyHandler(authConfig.APIKeys, logger)
+	roleHandler := NewRoleHandler(roleService, logger)
+	auditHandler := NewAuditHandler(auditService, logger)
+	tenantHandler := NewTenantHandler(tenantService, logger)
+	authn := &authMiddleware{cfg: authConfig, users: userService, roles: roleService, tenants: tenantService, logger: logger}
 
-	r.Post("/team/add", teamHandler.CreateTeam)
-	r.Get("/team/get", teamHandler.GetTeam)
-	r.Post("/team/deactivateUsers", teamHandler.DeactivateUsers)
+	// The WebSocket queue authenticates with its first message instead.
+	r.Get("/ws/reviews", reviewQueueHandler.Serve)
 
-	r.Post("/users/setIsActive", userHandler.SetIsActive)
-	r.Get("/users/getReview", userHandler.GetReviews)
+	r.Group(func(r chi.Router) {
+		// Counted before authentication, so that failed attempts are throttled and
+		// throttled requests do not reach the credential store.
+		if rateLimitConfig.limitsAddresses() {
+			r.Use(middleware.RateLimit(rateLimitConfig.Limiter, rateLimitConfig.AddressPolicy, addressKey, logger))
+		}
+		r.Use(authn.authenticate)
+		r.Use(authn.resolveTenant)
+		if rateLimitConfig.enabled() {
+			r.Use(middleware.RateLimit(rateLimitConfig.Limiter, rateLimitConfig.Policy, rateLimitKey, logger))
+		}
+		if idempotencyConfig.enabled() {
+			r.Use(middleware.Idempotency(idempotencyConfig.Store, idempotencyConfig.TTL, rateLimitKey, logger))
+		}
 
-	r.Post("/pullRequest/create", prHandler.CreatePR)
-	r.Post("/pullRequest/merge", prHandler.MergePR)
-	r.Post("/pullRequest/reassign", prHandler.ReassignReviewer)
+		r.Group(func(r chi.Router) {
+			r.Use(authn.require(domain.ScopeRead))
 
-	r.Get("/stats/reviewers", statsHandler.GetReviewerStats)
-	r.Get("/stats/pullRequests", statsHandler.GetPRStats)
+			r.Get("/team/get", teamHandler.GetTeam)
+			r.Get("/team/settings/get", teamHandler.GetSettings)
+			r.Get("/team/exclusions/get", constraintHandler.ListExclusions)
+			r.Get("/team/templates/get", notificationHandler.GetTemplates)
+
+			r.Get("/users/getReview", userHandler.GetReviews)
+			r.Get("/users/neverAssign/get", constraintHandler.ListNeverAssign)
+			r.Get("/users/reminders/get", reminderHandler.GetPreferences)
+
+			r.Get("/pullRequest/timeline", prHandler.GetTimeline)
+
+			r.Get("/stats/reviewers", statsHandler.GetReviewerStats)
+			r.Get("/stats/pullRequests", statsHandler.GetPRStats)
+			r.Get("/stats/declines", statsHandler.GetDeclineStats)
+			r.Get("/stats/fairness", statsHandler.GetFairness)
+			r.Get("/stats/cycleTime", statsHandler.GetCycleTime)
+			r.Get("/stats/cycleTime/pullRequest", statsHandler.GetPRCycleTime)
+			r.Get("/stats/sla", statsHandler.GetSLAReport)
+
+			r.Get("/events/stream", streamHandler.Stream)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authn.require(domain.ScopeWritePR))
+
+			r.Post("/pullRequest/create", prHandler.CreatePR)
+			r.With(ifMatch).Post("/pullRequest/merge", prHandler.MergePR)
+			r.With(ifMatch).Post("/pullRequest/reassign", prHandler.ReassignReviewer)
+			r.With(ifMatch).Post("/pullRequest/decline", prHandler.DeclineReview)
+			r.Post("/pullRequest/review", prHandler.SubmitReview)
+			r.With(ifMatch).Post("/pullRequest/reviewers/add", prHandler.AddReviewer)
+			r.With(ifMatch).Post("/pullRequest/reviewers/remove", prHandler.RemoveReviewer)
+			r.With(ifMatch).Post("/pullRequest/reviewers/set", prHandler.SetReviewers)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authn.require(domain.ScopeAdminTeams))
+
+			r.Post("/team/add", teamHandler.CreateTeam)
+			r.With(ifMatch).Post("/team/deactivateUsers", teamHandler.DeactivateUsers)
+			r.With(ifMatch).Post("/team/settings/set", teamHandler.UpdateSettings)
+			r.Post("/team/exclusions/add", constraintHandler.AddExclusion)
+			r.Post("/team/exclusions/remove", constraintHandler.RemoveExclusion)
+			r.Post("/team/templates/set", notificationHandler.SetTemplate)
+
+			r.Post("/users/setIsActive", userHandler.SetIsActive)
+			r.Post("/users/setSlackMemberId", userHandler.SetSlackMemberID)
+			r.Post("/users/setEmail", userHandler.SetEmail)
+			r.Post("/users/neverAssign/add", constraintHandler.AddNeverAssign)
+			r.Post("/users/neverAssign/remove", constraintHandler.RemoveNeverAssign)
+			r.Post("/users/reminders/set", reminderHandler.UpdatePreferences)
+
+			r.Post("/auth/keys/create", apiKeyHandler.CreateKey)
+			r.Post("/auth/keys/rotate", apiKeyHandler.RotateKey)
+			r.Post("/auth/keys/revoke", apiKeyHandler.RevokeKey)
+			r.Get("/auth/keys/list", apiKeyHandler.ListKeys)
+
+			r.Get("/auth/roles/get", roleHandler.ListRoles)
+			r.Post("/auth/roles/set", roleHandler.SetRole)
+			r.Post("/auth/roles/remove", roleHandler.RemoveRole)
+
+			r.Get("/audit/get", auditHandler.ListEntries)
+			r.Get("/audit/export", auditHandler.Export)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authn.requirePlatform)
+
+			r.Post("/tenants/create", tenantHandler.CreateTenant)
+			r.Get("/tenants/list", tenantHandler.ListTenants)
+		})
+	})
 
 	return r
 }
@@ -4,24 +4,33 @@ import (
 	"log/slog"
 	"net/http"
 	"sort"
+	"time"
 
+	"github.com/mivihan/Pull_Request_service/internal/domain"
 	"github.com/mivihan/Pull_Request_service/internal/service"
 )
 
 type StatsHandler struct {
-	prService service.PRService
-	logger    *slog.Logger
+	prService    service.PRService
+	statsService service.StatsService
+	logger       *slog.Logger
 }
 
-func NewStatsHandler(prService service.PRService, logger *slog.Logger) *StatsHandler {
+func NewStatsHandler(prService service.PRService, statsService service.StatsService, logger *slog.Logger) *StatsHandler {
 	return &StatsHandler{
-		prService: prService,
-		logger:    logger,
+		prService:    prService,
+		statsService: statsService,
+		logger:       logger,
 	}
 }
 
 func (h *StatsHandler) GetReviewerStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.prService.GetReviewerStats(r.Context())
+	filter, ok := parseStatsFilter(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.prService.GetReviewerStats(r.Context(), filter)
 	if err != nil {
 		respondError(w, err, h.logger)
 		return
@@ -39,13 +48,29 @@ func (h *StatsHandler) GetReviewerStats(w http.ResponseWriter, r *http.Request)
 		return reviewers[i].AssignmentsCount > reviewers[j].AssignmentsCount
 	})
 
-	respondJSON(w, http.StatusOK, ReviewerStatsResponse{
+	response := ReviewerStatsResponse{
 		Reviewers: reviewers,
-	})
+	}
+
+	if filter.GroupBy != "" {
+		points, err := h.statsService.GetReviewerSeries(r.Context(), filter)
+		if err != nil {
+			respondError(w, err, h.logger)
+			return
+		}
+		response.Series = mapReviewerSeriesToDTO(points)
+	}
+
+	respondJSON(w, http.StatusOK, response)
 }
 
 func (h *StatsHandler) GetPRStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.prService.GetPRStats(r.Context())
+	filter, ok := parseStatsFilter(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.prService.GetPRStats(r.Context(), filter)
 	if err != nil {
 		respondError(w, err, h.logger)
 		return
@@ -56,5 +81,242 @@ func (h *StatsHandler) GetPRStats(w http.ResponseWriter, r *http.Request) {
 		Merged: stats["MERGED"],
 	}
 
+	if filter.GroupBy != "" {
+		points, err := h.statsService.GetPRSeries(r.Context(), filter)
+		if err != nil {
+			respondError(w, err, h.logger)
+			return
+		}
+		response.Series = mapPRSeriesToDTO(points)
+	}
+
 	respondJSON(w, http.StatusOK, response)
-}
\ No newline at end of file
+}
+
+// defaultStatsWindow is used when a stats endpoint is called without "from".
+const defaultStatsWindow = 30 * 24 * time.Hour
+
+func (h *StatsHandler) GetDeclineStats(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseTimeWindow(w, r)
+	if !ok {
+		return
+	}
+
+	filter := domain.StatsFilter{
+		TeamName: r.URL.Query().Get("team_name"),
+		From:     from,
+		To:       to,
+	}
+
+	stats, err := h.prService.GetDeclineStats(r.Context(), filter)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	reviewers := make([]DeclineStatDTO, len(stats))
+	for i, s := range stats {
+		reviewers[i] = mapDeclineStatToDTO(s)
+	}
+
+	sort.Slice(reviewers, func(i, j int) bool {
+		return reviewers[i].DeclineRate > reviewers[j].DeclineRate
+	})
+
+	respondJSON(w, http.StatusOK, DeclineStatsResponse{
+		From:      from,
+		To:        to,
+		Reviewers: reviewers,
+	})
+}
+
+func (h *StatsHandler) GetFairness(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "team_name query parameter is required",
+			},
+		})
+		return
+	}
+
+	from, to, ok := parseTimeWindow(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.statsService.GetFairness(r.Context(), teamName, from, to)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, mapFairnessToDTO(teamName, from, to, report))
+}
+
+func (h *StatsHandler) GetCycleTime(w http.ResponseWriter, r *http.Request) {
+	by, ok := domain.ParseCycleTimeDimension(r.URL.Query().Get("by"))
+	if !ok {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "by must be one of team, repository, reviewer",
+			},
+		})
+		return
+	}
+
+	from, to, ok := parseTimeWindow(w, r)
+	if !ok {
+		return
+	}
+
+	filter := domain.StatsFilter{
+		TeamName: r.URL.Query().Get("team_name"),
+		From:     from,
+		To:       to,
+	}
+
+	report, err := h.statsService.GetCycleTime(r.Context(), filter, r.URL.Query().Get("repository"), by)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, mapCycleTimeReportToDTO(from, to, by, report))
+}
+
+func (h *StatsHandler) GetPRCycleTime(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "pull_request_id query parameter is required",
+			},
+		})
+		return
+	}
+
+	cycle, err := h.statsService.GetPRCycleTime(r.Context(), prID)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, mapPRCycleTimeToDTO(cycle))
+}
+
+func (h *StatsHandler) GetSLAReport(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseTimeWindow(w, r)
+	if !ok {
+		return
+	}
+
+	filter := domain.StatsFilter{
+		TeamName: r.URL.Query().Get("team_name"),
+		From:     from,
+		To:       to,
+	}
+
+	report, err := h.statsService.GetSLAReport(r.Context(), filter)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, mapSLAReportToDTO(from, to, report))
+}
+
+// parseStatsFilter reads the optional team_name, from, to and group_by query parameters.
+// Unlike parseTimeWindow, a missing bound leaves that side of the window open.
+func parseStatsFilter(w http.ResponseWriter, r *http.Request) (domain.StatsFilter, bool) {
+	filter := domain.StatsFilter{
+		TeamName: r.URL.Query().Get("team_name"),
+	}
+
+	groupBy, ok := domain.ParseStatsGrouping(r.URL.Query().Get("group_by"))
+	if !ok {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "group_by must be one of day, week, month",
+			},
+		})
+		return domain.StatsFilter{}, false
+	}
+	filter.GroupBy = groupBy
+
+	if filter.From, ok = parseTimeParam(w, r, "from"); !ok {
+		return domain.StatsFilter{}, false
+	}
+	if filter.To, ok = parseTimeParam(w, r, "to"); !ok {
+		return domain.StatsFilter{}, false
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		respondInvalidWindow(w)
+		return domain.StatsFilter{}, false
+	}
+
+	return filter, true
+}
+
+// parseTimeWindow reads the optional "from" and "to" RFC 3339 query parameters.
+// Without them the window is the last defaultStatsWindow up to now.
+func parseTimeWindow(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	to, ok := parseTimeParam(w, r, "to")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	from, ok := parseTimeParam(w, r, "from")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultStatsWindow)
+	}
+
+	if !from.Before(to) {
+		respondInvalidWindow(w)
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
+
+// parseTimeParam returns the zero time when the parameter is absent.
+func parseTimeParam(w http.ResponseWriter, r *http.Request, name string) (time.Time, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return time.Time{}, true
+	}
+
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: name + " must be an RFC 3339 timestamp",
+			},
+		})
+		return time.Time{}, false
+	}
+
+	return parsed, true
+}
+
+func respondInvalidWindow(w http.ResponseWriter) {
+	respondJSON(w, http.StatusBadRequest, ErrorResponse{
+		Error: ErrorDetail{
+			Code:    "INVALID_REQUEST",
+			Message: "from must be before to",
+		},
+	})
+}
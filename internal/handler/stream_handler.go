@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/stream"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+const (
+	streamHeartbeat  = 15 * time.Second
+	streamRetryDelay = 3 * time.Second
+)
+
+type StreamHandler struct {
+	broker *stream.Broker
+	logger *slog.Logger
+}
+
+func NewStreamHandler(broker *stream.Broker, logger *slog.Logger) *StreamHandler {
+	return &StreamHandler{
+		broker: broker,
+		logger: logger,
+	}
+}
+
+// Stream sends timeline events as Server-Sent Events. A client resumes after a
+// disconnect with the Last-Event-ID header or the last_event_id query parameter. Slow
+// clients are disconnected and are expected to reconnect the same way.
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.EventFilter{
+		TenantID: tenant.ID(r.Context()),
+		TeamName: query.Get("team_name"),
+		UserID:   query.Get("user_id"),
+		PRID:     query.Get("pull_request_id"),
+	}
+
+	// EventSource cannot set headers on the first connection, hence the query fallback.
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = query.Get("last_event_id")
+	}
+	var lastEventID int64 = -1
+	if raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id < 0 {
+			respondJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error: ErrorDetail{
+					Code:    "INVALID_REQUEST",
+					Message: "last event id must be a non-negative integer",
+				},
+			})
+			return
+		}
+		lastEventID = id
+	}
+
+	rc := http.NewResponseController(w)
+	// Streams outlive the server write timeout.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	ctx := r.Context()
+	sub, err := h.broker.Subscribe(ctx, filter)
+	if err != nil {
+		if errors.Is(err, stream.ErrClosed) {
+			respondJSON(w, http.StatusServiceUnavailable, ErrorResponse{
+				Error: ErrorDetail{
+					Code:    "SERVICE_UNAVAILABLE",
+					Message: "server is shutting down",
+				},
+			})
+			return
+		}
+		respondError(w, err, h.logger)
+		return
+	}
+	defer sub.Close()
+
+	var backlog []*domain.FeedEvent
+	if lastEventID >= 0 {
+		if backlog, err = h.broker.Replay(ctx, sub, lastEventID); err != nil {
+			respondError(w, err, h.logger)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetryDelay.Milliseconds()); err != nil {
+		return
+	}
+	for _, e := range backlog {
+		if err := writeStreamEvent(w, e); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-sub.Events():
+			if !ok {
+				if sub.Lagged() {
+					h.logger.Warn("event stream subscriber too slow, disconnecting", "remote_addr", r.RemoteAddr)
+				}
+				return
+			}
+			if err := writeStreamEvent(w, e); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, e *domain.FeedEvent) error {
+	data, err := json.Marshal(mapFeedEventToDTO(e))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.EventID, e.Type, data)
+	return err
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/mivihan/Pull_Request_service/internal/domain"
 	"github.com/mivihan/Pull_Request_service/internal/service"
 )
 
@@ -50,6 +53,7 @@ func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, team.Version)
 	respondJSON(w, http.StatusCreated, TeamResponse{
 		Team: mapTeamWithMembersToDTO(team),
 	})
@@ -73,6 +77,7 @@ func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, team.Version)
 	respondJSON(w, http.StatusOK, mapTeamWithMembersToDTO(team))
 }
 
@@ -103,8 +108,99 @@ func (h *TeamHandler) DeactivateUsers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	respondJSON(w, http.StatusOK, DeactivateUsersResponse{
-		TeamName:        result.TeamName,
+		TeamName:         result.TeamName,
 		DeactivatedCount: result.DeactivatedCount,
 		AffectedPRCount:  result.AffectedPRCount,
 	})
-}
\ No newline at end of file
+}
+func (h *TeamHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "team_name query parameter is required",
+			},
+		})
+		return
+	}
+
+	settings, err := h.teamService.GetSettings(r.Context(), teamName)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	setETag(w, settings.Version)
+	respondJSON(w, http.StatusOK, mapTeamSettingsToDTO(settings))
+}
+
+func (h *TeamHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var req TeamSettingsDTO
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if req.TeamName == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "team_name is required",
+			},
+		})
+		return
+	}
+
+	settings := domain.DefaultTeamSettings(req.TeamName)
+	settings.ReviewSLA = time.Duration(req.ReviewSLAMinutes) * time.Minute
+	settings.StaleTimeout = time.Duration(req.StaleTimeoutMinutes) * time.Minute
+	if req.Timezone != "" {
+		settings.Timezone = req.Timezone
+	}
+
+	var err error
+	if req.WorkDayStart != "" {
+		if settings.WorkDayStart, err = parseClock(req.WorkDayStart); err != nil {
+			respondInvalidClock(w, "work_day_start")
+			return
+		}
+	}
+	if req.WorkDayEnd != "" {
+		if settings.WorkDayEnd, err = parseClock(req.WorkDayEnd); err != nil {
+			respondInvalidClock(w, "work_day_end")
+			return
+		}
+	}
+
+	updated, err := h.teamService.UpdateSettings(r.Context(), settings)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	setETag(w, updated.Version)
+	respondJSON(w, http.StatusOK, mapTeamSettingsToDTO(updated))
+}
+
+// parseClock converts "HH:MM" into minutes after midnight; "24:00" is allowed as the end
+// of a day.
+func parseClock(raw string) (int, error) {
+	var hours, minutes int
+	if _, err := fmt.Sscanf(raw, "%d:%d", &hours, &minutes); err != nil {
+		return 0, err
+	}
+	total := hours*60 + minutes
+	if hours < 0 || minutes < 0 || minutes > 59 || total > 24*60 {
+		return 0, fmt.Errorf("clock out of range: %s", raw)
+	}
+	return total, nil
+}
+
+func respondInvalidClock(w http.ResponseWriter, field string) {
+	respondJSON(w, http.StatusBadRequest, ErrorResponse{
+		Error: ErrorDetail{
+			Code:    "INVALID_REQUEST",
+			Message: field + " must be in HH:MM format",
+		},
+	})
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/service"
+)
+
+type TenantHandler struct {
+	tenantService service.TenantService
+	logger        *slog.Logger
+}
+
+func NewTenantHandler(tenantService service.TenantService, logger *slog.Logger) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+		logger:        logger,
+	}
+}
+
+func (h *TenantHandler) CreateTenant(w http.ResponseWriter, r *http.Request) {
+	var req TenantDTO
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if req.TenantID == "" || req.Name == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "tenant_id and name are required",
+			},
+		})
+		return
+	}
+
+	t, err := h.tenantService.CreateTenant(r.Context(), &domain.Tenant{
+		TenantID: req.TenantID,
+		Name:     req.Name,
+	})
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, mapTenantToDTO(t))
+}
+
+func (h *TenantHandler) ListTenants(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.tenantService.ListTenants(r.Context())
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	result := make([]TenantDTO, len(tenants))
+	for i, t := range tenants {
+		result[i] = mapTenantToDTO(t)
+	}
+
+	respondJSON(w, http.StatusOK, TenantsResponse{Tenants: result})
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"log/slog"
 	"net/http"
+	"net/mail"
+	"strings"
 
 	"github.com/mivihan/Pull_Request_service/internal/service"
 )
@@ -46,6 +48,75 @@ func (h *UserHandler) SetIsActive(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *UserHandler) SetSlackMemberID(w http.ResponseWriter, r *http.Request) {
+	var req SetSlackMemberIDRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if req.UserID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "user_id is required",
+			},
+		})
+		return
+	}
+
+	user, err := h.userService.SetSlackMemberID(r.Context(), req.UserID, strings.TrimSpace(req.SlackMemberID))
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, UserResponse{
+		User: mapUserToDTO(user),
+	})
+}
+
+// SetEmail stores the address used for email notifications; an empty email clears it.
+func (h *UserHandler) SetEmail(w http.ResponseWriter, r *http.Request) {
+	var req SetEmailRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		return
+	}
+
+	if req.UserID == "" {
+		respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INVALID_REQUEST",
+				Message: "user_id is required",
+			},
+		})
+		return
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if email != "" {
+		addr, err := mail.ParseAddress(email)
+		if err != nil || addr.Address != email {
+			respondJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error: ErrorDetail{
+					Code:    "INVALID_REQUEST",
+					Message: "email must be a plain address like user@example.com",
+				},
+			})
+			return
+		}
+	}
+
+	user, err := h.userService.SetEmail(r.Context(), req.UserID, email)
+	if err != nil {
+		respondError(w, err, h.logger)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, UserResponse{
+		User: mapUserToDTO(user),
+	})
+}
+
 func (h *UserHandler) GetReviews(w http.ResponseWriter, r *http.Request) {
 	userID := r.URL.Query().Get("user_id")
 	if userID == "" {
@@ -64,9 +135,9 @@ func (h *UserHandler) GetReviews(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	prDTOs := make([]PullRequestShortDTO, len(prs))
+	prDTOs := make([]ReviewAssignmentDTO, len(prs))
 	for i, pr := range prs {
-		prDTOs[i] = mapPRToShortDTO(pr)
+		prDTOs[i] = mapReviewAssignmentToDTO(pr)
 	}
 
 	respondJSON(w, http.StatusOK, UserReviewsResponse{
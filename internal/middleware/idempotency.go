@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed from an earlier request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength bounds idempotency keys taken from clients.
+const maxIdempotencyKeyLength = 255
+
+// idempotencyLease is how long a request holds its key. A retry arriving later takes
+// the key over, so that a key is not stuck when a replica dies mid-request; it is well
+// above the server's write timeout.
+const idempotencyLease = time.Minute
+
+// IdempotencyStore keeps the requests made with an idempotency key;
+// repository.IdempotencyRepository implements it. Claim returns nil when the key was
+// free and is now held by the request, or the record of the request holding it.
+type IdempotencyStore interface {
+	Claim(ctx context.Context, key, fingerprint string, ttl, lease time.Duration) (*domain.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, record *domain.IdempotencyRecord) error
+	Release(ctx context.Context, key, fingerprint string) error
+}
+
+// Idempotency makes POST requests carrying an Idempotency-Key safe to retry: the first
+// response for a key is kept for ttl and replayed to later requests of the same client
+// with the same key and body. Reusing a key with another request is refused with 422,
+// and retrying while the first request is still running with 409. Server errors are
+// not kept, so a retry after one is handled anew. client names the client, keys of
+// different clients never collide.
+func Idempotency(store IdempotencyStore, ttl time.Duration, client func(*http.Request) string, logger *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !validIdempotencyKey(key) {
+				writeError(w, http.StatusBadRequest, "INVALID_REQUEST",
+					fmt.Sprintf("%s must be 1 to %d printable ASCII characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "failed to read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := r.Context()
+			storeKey := client(r) + " " + key
+			fingerprint := requestFingerprint(r, body)
+
+			held, err := store.Claim(ctx, storeKey, fingerprint, ttl, idempotencyLease)
+			if err != nil {
+				logger.Error("idempotency store failed", "error", err, "path", r.URL.Path)
+				writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+				return
+			}
+			if held != nil {
+				switch {
+				case held.Fingerprint != fingerprint:
+					writeError(w, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED",
+						IdempotencyKeyHeader+" was already used for a different request")
+				case !held.Completed():
+					w.Header().Set("Retry-After", "1")
+					writeError(w, http.StatusConflict, "IDEMPOTENCY_IN_PROGRESS",
+						"a request with this "+IdempotencyKeyHeader+" is still being processed")
+				default:
+					replay(w, held)
+				}
+				return
+			}
+
+			// The key is released unless the response is stored, also when the handler
+			// panics or the client goes away.
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				if err := store.Release(context.WithoutCancel(ctx), storeKey, fingerprint); err != nil {
+					logger.Error("failed to release idempotency key", "error", err, "path", r.URL.Path)
+				}
+			}()
+
+			rec := &recordingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.statusCode >= http.StatusInternalServerError {
+				return
+			}
+
+			err = store.Complete(context.WithoutCancel(ctx), storeKey, &domain.IdempotencyRecord{
+				Fingerprint: fingerprint,
+				StatusCode:  rec.statusCode,
+				ContentType: rec.Header().Get("Content-Type"),
+				ETag:        rec.Header().Get("ETag"),
+				Body:        rec.body.Bytes(),
+			})
+			if err != nil {
+				logger.Error("failed to store idempotent response", "error", err, "path", r.URL.Path)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+func replay(w http.ResponseWriter, record *domain.IdempotencyRecord) {
+	h := w.Header()
+	if record.ContentType != "" {
+		h.Set("Content-Type", record.ContentType)
+	}
+	if record.ETag != "" {
+		h.Set("ETag", record.ETag)
+	}
+	h.Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+// requestFingerprint identifies a request by its method, path, query, If-Match and body:
+// a change made on another version is another request.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	// Left out when absent, so that fingerprints of requests without it stay the same.
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		io.WriteString(h, "If-Match: "+ifMatch+"\n")
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return key != ""
+}
+
+// recordingWriter passes the response through and keeps a copy of it.
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.statusCode = code
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"error":{"code":%s,"message":%s}}`, strconv.Quote(code), strconv.Quote(message))
+}
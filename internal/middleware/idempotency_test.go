@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+type fakeIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*domain.IdempotencyRecord
+}
+
+func newFakeIdempotencyStore() *fakeIdempotencyStore {
+	return &fakeIdempotencyStore{records: make(map[string]*domain.IdempotencyRecord)}
+}
+
+func (s *fakeIdempotencyStore) Claim(ctx context.Context, key, fingerprint string, ttl, lease time.Duration) (*domain.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[key]; ok {
+		copied := *record
+		return &copied, nil
+	}
+	s.records[key] = &domain.IdempotencyRecord{Fingerprint: fingerprint}
+	return nil, nil
+}
+
+func (s *fakeIdempotencyStore) Complete(ctx context.Context, key string, record *domain.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = record
+	return nil
+}
+
+func (s *fakeIdempotencyStore) Release(ctx context.Context, key, fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+type countingHandler struct {
+	calls  int
+	status int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(h.status)
+	fmt.Fprintf(w, `{"call":%d,"echo":%s}`, h.calls, body)
+}
+
+func serveIdempotent(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func newIdempotencyHandler(store IdempotencyStore, next http.Handler) http.Handler {
+	client := func(r *http.Request) string { return r.Header.Get("X-Client") }
+	return Idempotency(store, time.Hour, client, slog.New(slog.NewTextHandler(io.Discard, nil)))(next)
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	next := &countingHandler{status: http.StatusCreated}
+	h := newIdempotencyHandler(newFakeIdempotencyStore(), next)
+
+	first := serveIdempotent(h, "k1", `{"pull_request_id":"pr-1"}`)
+	second := serveIdempotent(h, "k1", `{"pull_request_id":"pr-1"}`)
+
+	if next.calls != 1 {
+		t.Fatalf("expected the handler to run once, ran %d times", next.calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("expected the first response to be replayed, got %d %s", second.Code, second.Body)
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("expected only the replay to be marked")
+	}
+	if second.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected the content type to be replayed, got %q", second.Header().Get("Content-Type"))
+	}
+}
+
+func TestIdempotency_RejectsKeyReuseWithDifferentRequest(t *testing.T) {
+	next := &countingHandler{status: http.StatusCreated}
+	h := newIdempotencyHandler(newFakeIdempotencyStore(), next)
+
+	serveIdempotent(h, "k1", `{"pull_request_id":"pr-1"}`)
+	rec := serveIdempotent(h, "k1", `{"pull_request_id":"pr-2"}`)
+
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "IDEMPOTENCY_KEY_REUSED") {
+		t.Errorf("expected 422 IDEMPOTENCY_KEY_REUSED, got %d %s", rec.Code, rec.Body)
+	}
+	if next.calls != 1 {
+		t.Errorf("expected the second request not to run, ran %d times", next.calls)
+	}
+}
+
+func TestIdempotency_ReplaysVersionAndKeysOnIfMatch(t *testing.T) {
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("ETag", `"2"`)
+		w.WriteHeader(http.StatusOK)
+	})
+	h := newIdempotencyHandler(newFakeIdempotencyStore(), next)
+
+	merge := func(ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/merge", strings.NewReader(`{"pull_request_id":"pr-1"}`))
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+		req.Header.Set("If-Match", ifMatch)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	merge(`"1"`)
+	replayed := merge(`"1"`)
+	if calls != 1 || replayed.Header().Get("ETag") != `"2"` {
+		t.Errorf("expected the ETag to be replayed, got %d calls and %q", calls, replayed.Header().Get("ETag"))
+	}
+
+	if rec := merge(`"2"`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected another If-Match to be another request, got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestIdempotency_RejectsRetryWhileInProgress(t *testing.T) {
+	store := newFakeIdempotencyStore()
+	next := &countingHandler{status: http.StatusCreated}
+	h := newIdempotencyHandler(store, next)
+
+	var retry *httptest.ResponseRecorder
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		retry = serveIdempotent(h, "k1", `{}`)
+		w.WriteHeader(http.StatusCreated)
+	})
+	serveIdempotent(newIdempotencyHandler(store, slow), "k1", `{}`)
+
+	if retry.Code != http.StatusConflict || !strings.Contains(retry.Body.String(), "IDEMPOTENCY_IN_PROGRESS") {
+		t.Errorf("expected 409 IDEMPOTENCY_IN_PROGRESS, got %d %s", retry.Code, retry.Body)
+	}
+	if retry.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After to be set")
+	}
+	if next.calls != 0 {
+		t.Errorf("expected the retry not to run, ran %d times", next.calls)
+	}
+}
+
+func TestIdempotency_RetriesAfterServerError(t *testing.T) {
+	next := &countingHandler{status: http.StatusInternalServerError}
+	h := newIdempotencyHandler(newFakeIdempotencyStore(), next)
+
+	serveIdempotent(h, "k1", `{}`)
+	next.status = http.StatusCreated
+	rec := serveIdempotent(h, "k1", `{}`)
+
+	if next.calls != 2 || rec.Code != http.StatusCreated {
+		t.Errorf("expected the retry to run after a server error, got %d calls and %d", next.calls, rec.Code)
+	}
+}
+
+func TestIdempotency_ScopesKeysToClients(t *testing.T) {
+	next := &countingHandler{status: http.StatusCreated}
+	h := newIdempotencyHandler(newFakeIdempotencyStore(), next)
+
+	for _, client := range []string{"a", "b"} {
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+		req.Header.Set("X-Client", client)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if next.calls != 2 {
+		t.Errorf("expected the same key of two clients to run twice, ran %d times", next.calls)
+	}
+}
+
+func TestIdempotency_PassesThrough(t *testing.T) {
+	next := &countingHandler{status: http.StatusOK}
+	h := newIdempotencyHandler(newFakeIdempotencyStore(), next)
+
+	serveIdempotent(h, "", `{}`)
+	serveIdempotent(h, "", `{}`)
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+	req.Header.Set(IdempotencyKeyHeader, "k1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if next.calls != 4 {
+		t.Errorf("expected requests without a key and GETs to run every time, ran %d times", next.calls)
+	}
+
+	rec := serveIdempotent(h, strings.Repeat("k", 256), `{}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an overlong key, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/ratelimit"
+)
+
+// RateLimit takes a token for every request from the bucket of its client and route,
+// answering 429 when the bucket is empty. key names the client. When the limiter fails
+// the request is let through: an outage of the shared store must not take the API down.
+func RateLimit(limiter ratelimit.Limiter, policy *ratelimit.Policy, key func(*http.Request) string, logger *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit, scope, ok := policy.Match(r.URL.Path)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			client := key(r)
+			d, err := limiter.Take(r.Context(), client+" "+scope, limit)
+			if err != nil {
+				logger.Error("rate limiter failed", "error", err, "path", r.URL.Path)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(d.Limit.Capacity()))
+			h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d", d.Limit.Requests, ceilSeconds(d.Limit.Period), d.Limit.Capacity()))
+
+			if !d.Allowed {
+				logger.Warn("rate limit exceeded", "client", client, "path", r.URL.Path)
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(d.RetryAfter)))
+				h.Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				fmt.Fprintf(w, `{"error":{"code":"RATE_LIMITED","message":"rate limit of %d requests per %s exceeded"}}`,
+					d.Limit.Requests, d.Limit.Period)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ceilSeconds rounds up so that a client waiting that long is not refused again.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,326 @@
+// Package migrate applies the SQL migrations built into the binary. It keeps the schema
+// version in the schema_migrations table the way golang-migrate does, so a database
+// migrated by either tool can be taken over by the other.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockKey is the advisory lock held while migrating, so that replicas starting together
+// apply migrations one at a time: the first one migrates, the others wait for it and
+// find nothing left to do.
+const lockKey = "schema_migrations"
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is one step of the schema. Down is empty for steps that cannot be undone.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is the state of the database against the known migrations.
+type Status struct {
+	// Version is the last applied migration, zero when none was applied.
+	Version uint64
+	// Dirty is set when a migration failed half-way and the schema needs fixing by
+	// hand. Migrations applied here run in a transaction and never leave it set.
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+// Load reads the migrations named <version>_<name>.up.sql and .down.sql from the root
+// of fsys, ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return migrations, nil
+}
+
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+	logger     *slog.Logger
+}
+
+func New(pool *pgxpool.Pool, fsys fs.FS, logger *slog.Logger) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migrations, logger: logger}, nil
+}
+
+// Up applies all pending migrations and returns how many were applied. A database
+// already ahead of the known migrations is left alone.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.locked(ctx, func(conn *pgx.Conn) error {
+		version, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if latest := m.latest(); version > latest {
+			m.logger.Warn("database schema is newer than the known migrations", "version", version, "latest", latest)
+			return nil
+		}
+
+		for _, migration := range pending(m.migrations, version) {
+			if err := m.apply(ctx, conn, migration.Version, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			m.logger.Info("applied migration", "version", migration.Version, "name", migration.Name)
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations and returns how many were reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.locked(ctx, func(conn *pgx.Conn) error {
+		version, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+		plan, err := rollback(m.migrations, version, steps)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range plan {
+			if err := m.apply(ctx, conn, migration.Version, migration.Down, before(m.migrations, migration.Version)); err != nil {
+				return fmt.Errorf("revert migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			m.logger.Info("reverted migration", "version", migration.Version, "name", migration.Name)
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status reports the schema version and which migrations are applied and pending.
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var status Status
+	status.Version, status.Dirty, err = readVersion(ctx, conn.Conn())
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range m.migrations {
+		if migration.Version <= status.Version {
+			status.Applied = append(status.Applied, migration)
+		} else {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return &status, nil
+}
+
+func (m *Migrator) latest() uint64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// locked runs fn on one connection holding the migration lock, waiting for other
+// replicas to release it first.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock(hashtext($1))`, lockKey); err != nil {
+		return fmt.Errorf("lock migrations: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock(hashtext($1))`, lockKey); err != nil {
+			m.logger.Error("failed to unlock migrations", "error", err)
+		}
+	}()
+
+	_, err = conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`)
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	return fn(conn.Conn())
+}
+
+// version returns the schema version, refusing to go on from a dirty one.
+func (m *Migrator) version(ctx context.Context, conn *pgx.Conn) (uint64, error) {
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("database is dirty at version %d: a migration failed half-way, fix the schema and schema_migrations by hand", version)
+	}
+	return version, nil
+}
+
+// apply runs the script of migration and records version as the schema version in one
+// transaction, so that a failing script leaves the schema as it was.
+func (m *Migrator) apply(ctx context.Context, conn *pgx.Conn, migration uint64, script string, version uint64) error {
+	if script == "" {
+		return fmt.Errorf("migration %d cannot be reverted", migration)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Without arguments the script runs over the simple protocol, which allows several
+	// statements at once.
+	if _, err := tx.Exec(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return fmt.Errorf("clear schema version: %w", err)
+	}
+	if version > 0 {
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, int64(version)); err != nil {
+			return fmt.Errorf("set schema version: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// readVersion returns zero for a database without migrations, also when
+// schema_migrations does not exist yet.
+func readVersion(ctx context.Context, conn *pgx.Conn) (uint64, bool, error) {
+	var exists bool
+	if err := conn.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, false, fmt.Errorf("check schema_migrations: %w", err)
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	var version int64
+	var dirty bool
+	err := conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read schema version: %w", err)
+	}
+	return uint64(version), dirty, nil
+}
+
+// pending returns the migrations after version, in the order to apply them.
+func pending(migrations []Migration, version uint64) []Migration {
+	var result []Migration
+	for _, migration := range migrations {
+		if migration.Version > version {
+			result = append(result, migration)
+		}
+	}
+	return result
+}
+
+// before returns the version preceding the known migration version, zero for the
+// first one.
+func before(migrations []Migration, version uint64) uint64 {
+	idx := slices.IndexFunc(migrations, func(m Migration) bool { return m.Version == version })
+	if idx <= 0 {
+		return 0
+	}
+	return migrations[idx-1].Version
+}
+
+// rollback returns the last steps migrations up to version, in the order to revert
+// them. Fewer are returned when fewer were applied.
+func rollback(migrations []Migration, version uint64, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	if version == 0 {
+		return nil, nil
+	}
+
+	idx := slices.IndexFunc(migrations, func(m Migration) bool { return m.Version == version })
+	if idx < 0 {
+		return nil, fmt.Errorf("database is at version %d, which is not a known migration", version)
+	}
+
+	var result []Migration
+	for i := idx; i >= 0 && len(result) < steps; i-- {
+		result = append(result, migrations[i])
+	}
+	return result, nil
+}
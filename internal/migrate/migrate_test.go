@@ -0,0 +1,105 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/mivihan/Pull_Request_service/migrations"
+)
+
+func TestLoad_OrdersAndPairsFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000010_later.up.sql":   {Data: []byte("CREATE TABLE later ();")},
+		"000002_first.up.sql":   {Data: []byte("CREATE TABLE first ();")},
+		"000002_first.down.sql": {Data: []byte("DROP TABLE first;")},
+		"README.md":             {Data: []byte("not a migration")},
+	}
+
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(got) != 2 || got[0].Version != 2 || got[1].Version != 10 {
+		t.Fatalf("expected migrations 2 and 10 in order, got %+v", got)
+	}
+	if got[0].Name != "first" || got[0].Down != "DROP TABLE first;" {
+		t.Errorf("expected the down file to be paired with its up file, got %+v", got[0])
+	}
+	if got[1].Down != "" {
+		t.Errorf("expected migration 10 to have no down script, got %q", got[1].Down)
+	}
+}
+
+func TestLoad_RejectsInconsistentFiles(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"missing up": {
+			"000001_init.down.sql": {Data: []byte("DROP TABLE t;")},
+		},
+		"two names": {
+			"000001_init.up.sql":  {Data: []byte("CREATE TABLE t ();")},
+			"000001_other.up.sql": {Data: []byte("CREATE TABLE u ();")},
+		},
+		"version zero": {
+			"000000_init.up.sql": {Data: []byte("CREATE TABLE t ();")},
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoad_EmbeddedMigrations(t *testing.T) {
+	got, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	for i, m := range got {
+		if m.Version != uint64(i+1) {
+			t.Errorf("expected migration %d to follow without gaps, got %d_%s", i+1, m.Version, m.Name)
+		}
+		if strings.TrimSpace(m.Down) == "" {
+			t.Errorf("expected migration %d_%s to have a down script", m.Version, m.Name)
+		}
+	}
+}
+
+func TestPendingAndRollback(t *testing.T) {
+	all := []Migration{{Version: 1}, {Version: 2}, {Version: 5}}
+
+	if got := pending(all, 2); len(got) != 1 || got[0].Version != 5 {
+		t.Errorf("expected only migration 5 to be pending, got %+v", got)
+	}
+	if got := pending(all, 0); len(got) != 3 {
+		t.Errorf("expected every migration to be pending on an empty database, got %+v", got)
+	}
+
+	plan, err := rollback(all, 5, 2)
+	if err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	if len(plan) != 2 || plan[0].Version != 5 || plan[1].Version != 2 {
+		t.Errorf("expected to revert 5 then 2, got %+v", plan)
+	}
+	if got := before(all, 5); got != 2 {
+		t.Errorf("expected 2 to precede 5, got %d", got)
+	}
+	if got := before(all, 1); got != 0 {
+		t.Errorf("expected nothing to precede the first migration, got %d", got)
+	}
+
+	if plan, err := rollback(all, 2, 10); err != nil || len(plan) != 2 {
+		t.Errorf("expected to revert what was applied, got %+v, %v", plan, err)
+	}
+	if plan, err := rollback(all, 0, 1); err != nil || len(plan) != 0 {
+		t.Errorf("expected nothing to revert on an empty database, got %+v, %v", plan, err)
+	}
+	if _, err := rollback(all, 3, 1); err == nil {
+		t.Error("expected an error for an unknown version")
+	}
+	if _, err := rollback(all, 5, 0); err == nil {
+		t.Error("expected an error for zero steps")
+	}
+}
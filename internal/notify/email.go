@@ -0,0 +1,267 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+//go:embed templates/email/*.tmpl
+var defaultEmailTemplates embed.FS
+
+// SMTPConfig describes the outgoing mail server. TemplateDir may hold files named like
+// the embedded defaults, e.g. reviewer_assigned.html.tmpl; each file found there
+// replaces its default.
+type SMTPConfig struct {
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	From        string
+	StartTLS    bool
+	TLSConfig   *tls.Config
+	TemplateDir string
+	Timeout     time.Duration
+}
+
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != ""
+}
+
+type emailTemplates struct {
+	subject *template.Template
+	text    *template.Template
+	html    *htmltemplate.Template
+}
+
+// EmailNotifier sends notifications as multipart emails with plain-text and HTML bodies.
+type EmailNotifier struct {
+	cfg       SMTPConfig
+	sender    *mail.Address
+	templates map[domain.NotificationKind]*emailTemplates
+}
+
+func NewEmailNotifier(cfg SMTPConfig) (*EmailNotifier, error) {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	sender, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp sender address: %w", err)
+	}
+
+	var overrides fs.FS
+	if cfg.TemplateDir != "" {
+		overrides = os.DirFS(cfg.TemplateDir)
+	}
+
+	templates := make(map[domain.NotificationKind]*emailTemplates)
+	for _, kind := range []domain.NotificationKind{
+		domain.NotificationReviewReminder,
+		domain.NotificationReviewerAssigned,
+		domain.NotificationReviewerReplaced,
+		domain.NotificationPRMerged,
+	} {
+		t, err := loadEmailTemplates(kind, overrides)
+		if err != nil {
+			return nil, err
+		}
+		templates[kind] = t
+	}
+
+	return &EmailNotifier{cfg: cfg, sender: sender, templates: templates}, nil
+}
+
+func loadEmailTemplates(kind domain.NotificationKind, overrides fs.FS) (*emailTemplates, error) {
+	base := strings.ToLower(string(kind))
+	read := func(suffix string) (string, string, error) {
+		name := base + suffix
+		if overrides != nil {
+			data, err := fs.ReadFile(overrides, name)
+			if err == nil {
+				return name, string(data), nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", "", fmt.Errorf("read email template %s: %w", name, err)
+			}
+		}
+		data, err := defaultEmailTemplates.ReadFile("templates/email/" + name)
+		if err != nil {
+			return "", "", fmt.Errorf("read default email template %s: %w", name, err)
+		}
+		return name, string(data), nil
+	}
+
+	var t emailTemplates
+	name, body, err := read(".subject.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	if t.subject, err = template.New(name).Option("missingkey=error").Parse(body); err != nil {
+		return nil, fmt.Errorf("parse email template: %w", err)
+	}
+	if name, body, err = read(".txt.tmpl"); err != nil {
+		return nil, err
+	}
+	if t.text, err = template.New(name).Option("missingkey=error").Parse(body); err != nil {
+		return nil, fmt.Errorf("parse email template: %w", err)
+	}
+	if name, body, err = read(".html.tmpl"); err != nil {
+		return nil, err
+	}
+	if t.html, err = htmltemplate.New(name).Option("missingkey=error").Parse(body); err != nil {
+		return nil, fmt.Errorf("parse email template: %w", err)
+	}
+	return &t, nil
+}
+
+func (e *EmailNotifier) Send(ctx context.Context, n *Notification) error {
+	if n.Recipient.Email == "" {
+		return fmt.Errorf("user %s has no email address", n.Recipient.UserID)
+	}
+	t, ok := e.templates[n.Kind]
+	if !ok {
+		return fmt.Errorf("no email template for %s", n.Kind)
+	}
+
+	msg, err := e.buildMessage(t, n)
+	if err != nil {
+		return err
+	}
+	return e.deliver(ctx, n.Recipient.Email, msg)
+}
+
+func (e *EmailNotifier) buildMessage(t *emailTemplates, n *Notification) ([]byte, error) {
+	data := newTemplateData(n, n.Recipient.Username)
+
+	var subject, text, html bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("render email subject: %w", err)
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("render email text: %w", err)
+	}
+	if err := t.html.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("render email html: %w", err)
+	}
+
+	var msg bytes.Buffer
+	mw := multipart.NewWriter(&msg)
+
+	headers := []string{
+		"From: " + e.sender.String(),
+		"To: " + n.Recipient.Email,
+		"Subject: " + mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String())),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/alternative; boundary="` + mw.Boundary() + `"`,
+	}
+	msg.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create email part: %w", err)
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write(part.body); err != nil {
+			return nil, fmt.Errorf("encode email part: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("encode email part: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("finish email: %w", err)
+	}
+
+	return msg.Bytes(), nil
+}
+
+func (e *EmailNotifier) deliver(ctx context.Context, to string, msg []byte) error {
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	dialer := net.Dialer{Timeout: e.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("connect to smtp server: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(e.cfg.Timeout))
+
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake: %w", err)
+	}
+	defer c.Close()
+
+	if e.cfg.StartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+		tlsConfig := e.cfg.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: e.cfg.Host}
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+
+	if e.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(e.sender.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM: %w", err)
+	}
+	if err := c.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp RCPT TO: %w", err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA: %w", err)
+	}
+	if _, err := io.Copy(w, bytes.NewReader(msg)); err != nil {
+		return fmt.Errorf("write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	return c.Quit()
+}
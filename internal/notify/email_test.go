@@ -0,0 +1,277 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+// smtpStandIn is a minimal in-process SMTP server that keeps received messages.
+type smtpStandIn struct {
+	listener net.Listener
+	username string
+	password string
+
+	mu       sync.Mutex
+	messages []receivedMail
+}
+
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+func startSMTPStandIn(t *testing.T, username, password string) *smtpStandIn {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStandIn{listener: l, username: username, password: password}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+	return s
+}
+
+func (s *smtpStandIn) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) received() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMail(nil), s.messages...)
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP stand-in")
+	var current receivedMail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			if s.username != "" {
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			} else {
+				reply("250 localhost")
+			}
+		case strings.HasPrefix(cmd, "AUTH PLAIN "):
+			creds, _ := base64.StdEncoding.DecodeString(line[len("AUTH PLAIN "):])
+			parts := strings.Split(string(creds), "\x00")
+			if len(parts) == 3 && parts[1] == s.username && parts[2] == s.password {
+				reply("235 authenticated")
+			} else {
+				reply("535 authentication failed")
+			}
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			current = receivedMail{from: strings.Trim(line[len("MAIL FROM:"):], "<> ")}
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			current.to = append(current.to, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			reply("250 ok")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			current.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+// readParts returns the decoded parts of a multipart/alternative message by content type.
+func readParts(t *testing.T, raw string) (*mail.Message, map[string]string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %q", msg.Header.Get("Content-Type"))
+	}
+
+	parts := make(map[string]string)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(p))
+		if err != nil {
+			t.Fatalf("decode part: %v", err)
+		}
+		contentType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+	return msg, parts
+}
+
+func emailNotification(kind domain.NotificationKind, email string) *Notification {
+	return &Notification{
+		Kind:      kind,
+		Recipient: &domain.User{UserID: "u2", Username: "bob", TeamName: "backend", Email: email},
+		PullRequests: []*domain.PullRequest{
+			{PullRequestID: "pr-1", PullRequestName: "Fix <script> escaping", AuthorID: "u1"},
+			{PullRequestID: "pr-2", PullRequestName: "Add search", AuthorID: "u3"},
+		},
+	}
+}
+
+func TestEmailNotifier_SendsMultipartMessage(t *testing.T) {
+	server := startSMTPStandIn(t, "bot", "secret")
+	notifier, err := NewEmailNotifier(SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Username: "bot",
+		Password: "secret",
+		From:     "PR Reviewer <reviews@example.com>",
+	})
+	if err != nil {
+		t.Fatalf("NewEmailNotifier failed: %v", err)
+	}
+
+	if err := notifier.Send(context.Background(), emailNotification(domain.NotificationReviewReminder, "bob@example.com")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	received := server.received()
+	if len(received) != 1 {
+		t.Fatalf("expected one message, got %d", len(received))
+	}
+	if received[0].from != "reviews@example.com" || len(received[0].to) != 1 || received[0].to[0] != "bob@example.com" {
+		t.Errorf("unexpected envelope: %+v", received[0])
+	}
+
+	msg, parts := readParts(t, received[0].data)
+	if subject := msg.Header.Get("Subject"); subject != "2 pull request(s) are waiting for your review" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+	if text := parts["text/plain"]; !strings.Contains(text, "Fix <script> escaping (pr-1)") || !strings.Contains(text, "Add search (pr-2)") {
+		t.Errorf("unexpected text body: %q", text)
+	}
+	if html := parts["text/html"]; !strings.Contains(html, "Fix &lt;script&gt; escaping") {
+		t.Errorf("expected escaped HTML body, got %q", html)
+	}
+}
+
+func TestEmailNotifier_TemplateOverrides(t *testing.T) {
+	dir := t.TempDir()
+	override := "[review] {{.PullRequest.PullRequestID}} from {{.PullRequest.AuthorID}}"
+	if err := os.WriteFile(filepath.Join(dir, "reviewer_assigned.subject.tmpl"), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := startSMTPStandIn(t, "", "")
+	notifier, err := NewEmailNotifier(SMTPConfig{
+		Host:        "127.0.0.1",
+		Port:        server.port(),
+		From:        "reviews@example.com",
+		TemplateDir: dir,
+	})
+	if err != nil {
+		t.Fatalf("NewEmailNotifier failed: %v", err)
+	}
+
+	if err := notifier.Send(context.Background(), emailNotification(domain.NotificationReviewerAssigned, "bob@example.com")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	msg, parts := readParts(t, server.received()[0].data)
+	if subject := msg.Header.Get("Subject"); subject != "[review] pr-1 from u1" {
+		t.Errorf("expected overridden subject, got %q", subject)
+	}
+	if !strings.Contains(parts["text/plain"], "you were assigned to review") {
+		t.Errorf("expected default text body, got %q", parts["text/plain"])
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "pr_merged.html.tmpl"), []byte("{{.Broken"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEmailNotifier(SMTPConfig{Host: "127.0.0.1", From: "reviews@example.com", TemplateDir: dir}); err == nil {
+		t.Error("expected invalid override to be rejected at startup")
+	}
+}
+
+func TestEmailNotifier_Errors(t *testing.T) {
+	server := startSMTPStandIn(t, "bot", "secret")
+	cfg := SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "reviews@example.com"}
+
+	notifier, _ := NewEmailNotifier(cfg)
+	if err := notifier.Send(context.Background(), emailNotification(domain.NotificationReviewerAssigned, "")); err == nil {
+		t.Error("expected error for a user without email")
+	}
+
+	cfg.StartTLS = true
+	notifier, _ = NewEmailNotifier(cfg)
+	if err := notifier.Send(context.Background(), emailNotification(domain.NotificationReviewerAssigned, "bob@example.com")); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("expected STARTTLS error, got %v", err)
+	}
+
+	cfg.StartTLS = false
+	cfg.Username, cfg.Password = "bot", "wrong"
+	notifier, _ = NewEmailNotifier(cfg)
+	if err := notifier.Send(context.Background(), emailNotification(domain.NotificationReviewerAssigned, "bob@example.com")); err == nil {
+		t.Error("expected authentication error")
+	}
+
+	if len(server.received()) != 0 {
+		t.Errorf("no message should have been accepted, got %d", len(server.received()))
+	}
+}
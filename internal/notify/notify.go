@@ -0,0 +1,81 @@
+// Package notify delivers notifications to users over pluggable channels.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+// Notification is addressed to a single user. Event notifications carry one pull
+// request, reminders a digest of them; ReplacedUserID is the reviewer taken off the
+// pull request for NotificationReviewerReplaced.
+type Notification struct {
+	Kind           domain.NotificationKind
+	Recipient      *domain.User
+	PullRequests   []*domain.PullRequest
+	ReplacedUserID string
+}
+
+// Notifier sends a notification over one channel.
+type Notifier interface {
+	Send(ctx context.Context, n *Notification) error
+}
+
+// Dispatcher routes notifications to the notifier registered for a channel.
+type Dispatcher struct {
+	mu        sync.RWMutex
+	notifiers map[domain.NotificationChannel]Notifier
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{notifiers: make(map[domain.NotificationChannel]Notifier)}
+}
+
+func (d *Dispatcher) Register(channel domain.NotificationChannel, notifier Notifier) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.notifiers[channel] = notifier
+}
+
+func (d *Dispatcher) Has(channel domain.NotificationChannel) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, ok := d.notifiers[channel]
+	return ok
+}
+
+func (d *Dispatcher) Send(ctx context.Context, channel domain.NotificationChannel, n *Notification) error {
+	d.mu.RLock()
+	notifier, ok := d.notifiers[channel]
+	d.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no notifier for channel %q", channel)
+	}
+	return notifier.Send(ctx, n)
+}
+
+// LogNotifier writes notifications to the service log.
+type LogNotifier struct {
+	logger *slog.Logger
+}
+
+func NewLogNotifier(logger *slog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (l *LogNotifier) Send(ctx context.Context, n *Notification) error {
+	prIDs := make([]string, len(n.PullRequests))
+	for i, pr := range n.PullRequests {
+		prIDs[i] = pr.PullRequestID
+	}
+	l.logger.InfoContext(ctx, "notification",
+		"kind", n.Kind,
+		"user_id", n.Recipient.UserID,
+		"pull_requests", prIDs,
+	)
+	return nil
+}
@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSlackAPIURL     = "https://slack.com/api"
+	defaultSlackMaxRetries = 3
+	// maxRetryAfter bounds how long a single rate-limit pause may take.
+	maxRetryAfter = time.Minute
+)
+
+// SlackConfig selects how messages are posted. With a Token the notifier calls
+// chat.postMessage and writes to the recipient's Slack member ID directly, falling back
+// to Channel; otherwise messages go to the WebhookURL.
+type SlackConfig struct {
+	WebhookURL string
+	Token      string
+	APIURL     string
+	Channel    string
+	MaxRetries int
+	HTTPClient *http.Client
+}
+
+func (c SlackConfig) Enabled() bool {
+	return c.WebhookURL != "" || c.Token != ""
+}
+
+// SlackNotifier posts notifications to Slack-compatible chats. Requests rejected with
+// 429 are retried after the delay from Retry-After.
+type SlackNotifier struct {
+	cfg       SlackConfig
+	templates TemplateStore
+}
+
+func NewSlackNotifier(cfg SlackConfig, templates TemplateStore) *SlackNotifier {
+	if cfg.APIURL == "" {
+		cfg.APIURL = defaultSlackAPIURL
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultSlackMaxRetries
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &SlackNotifier{cfg: cfg, templates: templates}
+}
+
+func (s *SlackNotifier) Send(ctx context.Context, n *Notification) error {
+	mention := n.Recipient.Username
+	if n.Recipient.SlackMemberID != "" {
+		mention = "<@" + n.Recipient.SlackMemberID + ">"
+	}
+
+	text, err := renderChat(ctx, s.templates, n, mention)
+	if err != nil {
+		return err
+	}
+
+	if s.cfg.Token == "" {
+		return s.post(ctx, s.cfg.WebhookURL, map[string]string{"text": text})
+	}
+
+	channel := n.Recipient.SlackMemberID
+	if channel == "" {
+		channel = s.cfg.Channel
+	}
+	if channel == "" {
+		return fmt.Errorf("user %s has no Slack member ID and no default channel is configured", n.Recipient.UserID)
+	}
+	return s.post(ctx, strings.TrimRight(s.cfg.APIURL, "/")+"/chat.postMessage", map[string]string{
+		"channel": channel,
+		"text":    text,
+	})
+}
+
+func (s *SlackNotifier) post(ctx context.Context, url string, payload map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode slack message: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("create slack request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		if s.cfg.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+		}
+
+		resp, err := s.cfg.HTTPClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("post slack message: %w", err)
+		}
+		respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("read slack response: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < s.cfg.MaxRetries {
+			select {
+			case <-time.After(retryAfter(resp.Header.Get("Retry-After"))):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("slack responded with %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+		}
+
+		// The Web API reports failures in the body of a 200 response.
+		if s.cfg.Token != "" {
+			var result struct {
+				OK    bool   `json:"ok"`
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(respBody, &result); err != nil {
+				return fmt.Errorf("decode slack response: %w", err)
+			}
+			if !result.OK {
+				return fmt.Errorf("slack API error: %s", result.Error)
+			}
+		}
+		return nil
+	}
+}
+
+// retryAfter reads the delay in seconds; Slack does not send HTTP dates here.
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(header))
+	if err != nil || seconds < 0 {
+		return time.Second
+	}
+	if d := time.Duration(seconds) * time.Second; d < maxRetryAfter {
+		return d
+	}
+	return maxRetryAfter
+}
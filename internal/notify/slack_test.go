@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+// fakeSlack imitates incoming webhooks and chat.postMessage. The first rateLimited
+// requests are rejected with 429.
+type fakeSlack struct {
+	mu          sync.Mutex
+	rateLimited int
+	requests    int
+	messages    []map[string]string
+	auth        []string
+	apiError    string
+}
+
+func (f *fakeSlack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests++
+	if f.rateLimited > 0 {
+		f.rateLimited--
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	var msg map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+		return
+	}
+	f.messages = append(f.messages, msg)
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+
+	if r.URL.Path == "/chat.postMessage" {
+		w.Header().Set("Content-Type", "application/json")
+		if f.apiError != "" {
+			json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": f.apiError})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"ok": true})
+		return
+	}
+	w.Write([]byte("ok"))
+}
+
+type staticTemplates map[domain.NotificationKind]string
+
+func (s staticTemplates) GetTemplate(ctx context.Context, teamName string, kind domain.NotificationKind) (string, error) {
+	return s[kind], nil
+}
+
+func assignedNotification(memberID string) *Notification {
+	return &Notification{
+		Kind:      domain.NotificationReviewerAssigned,
+		Recipient: &domain.User{UserID: "u2", Username: "bob", TeamName: "backend", SlackMemberID: memberID},
+		PullRequests: []*domain.PullRequest{
+			{PullRequestID: "pr-1", PullRequestName: "Add search", AuthorID: "u1"},
+		},
+	}
+}
+
+func TestSlackNotifier_Webhook(t *testing.T) {
+	fake := &fakeSlack{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	notifier := NewSlackNotifier(SlackConfig{WebhookURL: srv.URL + "/hook"}, nil)
+	if err := notifier.Send(context.Background(), assignedNotification("")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if len(fake.messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(fake.messages))
+	}
+	text := fake.messages[0]["text"]
+	if !strings.HasPrefix(text, "bob,") || !strings.Contains(text, "Add search (pr-1)") {
+		t.Errorf("unexpected message: %q", text)
+	}
+}
+
+func TestSlackNotifier_PostMessage(t *testing.T) {
+	fake := &fakeSlack{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	templates := staticTemplates{
+		domain.NotificationReviewerAssigned: "{{.Mention}} please look at {{.PullRequest.PullRequestID}}",
+	}
+	notifier := NewSlackNotifier(SlackConfig{Token: "xoxb-test", APIURL: srv.URL, Channel: "#reviews"}, templates)
+
+	if err := notifier.Send(context.Background(), assignedNotification("U123")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := notifier.Send(context.Background(), assignedNotification("")); err != nil {
+		t.Fatalf("Send without member ID failed: %v", err)
+	}
+
+	if fake.auth[0] != "Bearer xoxb-test" {
+		t.Errorf("expected bot token, got %q", fake.auth[0])
+	}
+	if got := fake.messages[0]; got["channel"] != "U123" || got["text"] != "<@U123> please look at pr-1" {
+		t.Errorf("expected direct message to the member, got %v", got)
+	}
+	if got := fake.messages[1]["channel"]; got != "#reviews" {
+		t.Errorf("expected fallback channel, got %q", got)
+	}
+
+	fake.apiError = "channel_not_found"
+	if err := notifier.Send(context.Background(), assignedNotification("U123")); err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("expected API error, got %v", err)
+	}
+}
+
+func TestSlackNotifier_RetriesRateLimited(t *testing.T) {
+	fake := &fakeSlack{rateLimited: 2}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	notifier := NewSlackNotifier(SlackConfig{WebhookURL: srv.URL, MaxRetries: 2}, nil)
+	if err := notifier.Send(context.Background(), assignedNotification("")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if fake.requests != 3 || len(fake.messages) != 1 {
+		t.Errorf("expected two retries and one delivered message, got %d requests, %d messages", fake.requests, len(fake.messages))
+	}
+
+	fake.rateLimited = 3
+	if err := notifier.Send(context.Background(), assignedNotification("")); err == nil {
+		t.Error("expected error once retries are exhausted")
+	}
+}
+
+func TestValidateChatTemplate(t *testing.T) {
+	if err := ValidateChatTemplate(domain.NotificationPRMerged, "{{.Mention}}: {{.PullRequest.PullRequestName}} merged"); err != nil {
+		t.Errorf("valid template rejected: %v", err)
+	}
+	if err := ValidateChatTemplate(domain.NotificationPRMerged, "{{.Mention"); err == nil {
+		t.Error("expected parse error")
+	}
+	if err := ValidateChatTemplate(domain.NotificationPRMerged, "{{.Nope}}"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+// TemplateStore returns the chat template a team configured for a kind of notification,
+// or an empty string to use the default one.
+type TemplateStore interface {
+	GetTemplate(ctx context.Context, teamName string, kind domain.NotificationKind) (string, error)
+}
+
+// TemplateData is what chat templates are executed with. Mention addresses the
+// recipient in the target chat.
+type TemplateData struct {
+	Recipient      *domain.User
+	Mention        string
+	PullRequest    *domain.PullRequest
+	PullRequests   []*domain.PullRequest
+	ReplacedUserID string
+}
+
+var defaultChatTemplates = map[domain.NotificationKind]string{
+	domain.NotificationReviewReminder: `{{.Mention}}, {{len .PullRequests}} pull request(s) are waiting for your review:
+{{range .PullRequests}}• {{.PullRequestName}} ({{.PullRequestID}})
+{{end}}`,
+	domain.NotificationReviewerAssigned: `{{.Mention}}, you were assigned to review {{.PullRequest.PullRequestName}} ({{.PullRequest.PullRequestID}}) by {{.PullRequest.AuthorID}}`,
+	domain.NotificationReviewerReplaced: `{{.Mention}}, you replaced {{.ReplacedUserID}} as a reviewer of {{.PullRequest.PullRequestName}} ({{.PullRequest.PullRequestID}})`,
+	domain.NotificationPRMerged:         `{{.Mention}}, {{.PullRequest.PullRequestName}} ({{.PullRequest.PullRequestID}}) was merged, no review is needed anymore`,
+}
+
+// ValidateChatTemplate checks that body parses and renders for kind.
+func ValidateChatTemplate(kind domain.NotificationKind, body string) error {
+	sample := &domain.PullRequest{PullRequestID: "pr-1", PullRequestName: "Sample", AuthorID: "u1"}
+	data := TemplateData{
+		Recipient:      &domain.User{UserID: "u2", Username: "reviewer"},
+		Mention:        "reviewer",
+		PullRequest:    sample,
+		PullRequests:   []*domain.PullRequest{sample},
+		ReplacedUserID: "u3",
+	}
+	_, err := renderTemplate(string(kind), body, data)
+	return err
+}
+
+func renderChat(ctx context.Context, store TemplateStore, n *Notification, mention string) (string, error) {
+	body := ""
+	if store != nil {
+		var err error
+		body, err = store.GetTemplate(ctx, n.Recipient.TeamName, n.Kind)
+		if err != nil {
+			return "", err
+		}
+	}
+	if body == "" {
+		body = defaultChatTemplates[n.Kind]
+	}
+	return renderTemplate(string(n.Kind), body, newTemplateData(n, mention))
+}
+
+func newTemplateData(n *Notification, mention string) TemplateData {
+	data := TemplateData{
+		Recipient:      n.Recipient,
+		Mention:        mention,
+		PullRequests:   n.PullRequests,
+		ReplacedUserID: n.ReplacedUserID,
+	}
+	if len(n.PullRequests) > 0 {
+		data.PullRequest = n.PullRequests[0]
+	}
+	return data
+}
+
+func renderTemplate(name, body string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("parse %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render %s template: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
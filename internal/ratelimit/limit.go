@@ -0,0 +1,164 @@
+// Package ratelimit limits how fast clients may call the API with token buckets: a
+// bucket holds up to Burst tokens, refills at Requests per Period and every request
+// takes one token.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	// Burst is the bucket size; zero means Requests.
+	Burst int
+}
+
+// ParseLimit reads "<requests>/<period>[:<burst>]", for example "30/m", "100/10s" or
+// "30/m:60". A bare unit stands for one of it.
+func ParseLimit(s string) (Limit, error) {
+	spec, burstSpec, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+	requestsSpec, periodSpec, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<period>", s)
+	}
+
+	var l Limit
+	var err error
+	if l.Requests, err = strconv.Atoi(requestsSpec); err != nil || l.Requests <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive integer", s)
+	}
+	if periodSpec != "" && !strings.ContainsAny(periodSpec[:1], "0123456789") {
+		periodSpec = "1" + periodSpec
+	}
+	if l.Period, err = time.ParseDuration(periodSpec); err != nil || l.Period <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: period must be a positive duration", s)
+	}
+	if hasBurst {
+		if l.Burst, err = strconv.Atoi(burstSpec); err != nil || l.Burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit %q: burst must be a positive integer", s)
+		}
+	}
+	return l, nil
+}
+
+func (l Limit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// perSecond is the refill rate in tokens per second.
+func (l Limit) perSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// refillTime is how long an empty bucket takes to fill up.
+func (l Limit) refillTime() time.Duration {
+	return seconds(float64(l.Capacity()) / l.perSecond())
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s:%d", l.Requests, l.Period, l.Capacity())
+}
+
+// Decision is the outcome of taking a token.
+type Decision struct {
+	Allowed   bool
+	Limit     Limit
+	Remaining int
+	// Reset is how long until the bucket is full again. RetryAfter is how long until
+	// the next request is allowed and is zero when this one was.
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// decide builds the decision from the tokens left in the bucket after the request.
+func decide(l Limit, tokens float64, allowed bool) Decision {
+	rate := l.perSecond()
+	d := Decision{
+		Allowed:   allowed,
+		Limit:     l,
+		Remaining: max(int(math.Floor(tokens)), 0),
+		Reset:     seconds((float64(l.Capacity()) - tokens) / rate),
+	}
+	if !allowed {
+		d.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return d
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Max(s, 0) * float64(time.Second))
+}
+
+// Limiter takes a token from the bucket of key.
+type Limiter interface {
+	Take(ctx context.Context, key string, limit Limit) (Decision, error)
+}
+
+// Policy maps request paths to limits. Paths without a limit of their own share one
+// bucket per client under Default; without Default they are not limited.
+type Policy struct {
+	Default *Limit
+	Routes  map[string]Limit
+}
+
+// ParsePolicy reads the default limit and "<path>=<limit>" route entries; an empty
+// defaultLimit leaves other routes unlimited.
+func ParsePolicy(defaultLimit string, routes []string) (*Policy, error) {
+	p := &Policy{Routes: make(map[string]Limit, len(routes))}
+	if defaultLimit != "" {
+		l, err := ParseLimit(defaultLimit)
+		if err != nil {
+			return nil, err
+		}
+		p.Default = &l
+	}
+	for _, route := range routes {
+		path, spec, ok := strings.Cut(route, "=")
+		if !ok || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid route rate limit %q: expected <path>=<limit>", route)
+		}
+		l, err := ParseLimit(spec)
+		if err != nil {
+			return nil, err
+		}
+		p.Routes[path] = l
+	}
+	return p, nil
+}
+
+func (p *Policy) Empty() bool {
+	return p.Default == nil && len(p.Routes) == 0
+}
+
+// Match returns the limit of path and the scope its bucket is kept under.
+func (p *Policy) Match(path string) (Limit, string, bool) {
+	if l, ok := p.Routes[path]; ok {
+		return l, path, true
+	}
+	if p.Default != nil {
+		return *p.Default, "*", true
+	}
+	return Limit{}, "", false
+}
+
+// RefillTime is the longest time any bucket of the policy takes to fill up. A bucket
+// left alone for longer is full and may be forgotten.
+func (p *Policy) RefillTime() time.Duration {
+	var longest time.Duration
+	if p.Default != nil {
+		longest = p.Default.refillTime()
+	}
+	for _, l := range p.Routes {
+		longest = max(longest, l.refillTime())
+	}
+	return longest
+}
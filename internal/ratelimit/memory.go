@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often full buckets are dropped from a MemoryLimiter.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// take refills the bucket for the time elapsed since its last use and takes a token.
+func (b *bucket) take(now time.Time) (float64, bool) {
+	capacity := float64(b.limit.Capacity())
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = min(capacity, b.tokens+elapsed*b.limit.perSecond())
+		b.updated = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return b.tokens, true
+	}
+	return b.tokens, false
+}
+
+// MemoryLimiter keeps buckets in process memory, so each replica limits on its own.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryLimiter) Take(ctx context.Context, key string, limit Limit) (Decision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{tokens: float64(limit.Capacity()), updated: now, limit: limit}
+		m.buckets[key] = b
+	}
+	tokens, allowed := b.take(now)
+	return decide(limit, tokens, allowed), nil
+}
+
+// sweep drops buckets that have refilled completely; a new bucket starts out full anyway.
+func (m *MemoryLimiter) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if now.Sub(b.updated) >= b.limit.refillTime() {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = now
+}
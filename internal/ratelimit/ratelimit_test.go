@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "30/m", want: Limit{Requests: 30, Period: time.Minute}},
+		{in: "100/10s", want: Limit{Requests: 100, Period: 10 * time.Second}},
+		{in: "30/m:60", want: Limit{Requests: 30, Period: time.Minute, Burst: 60}},
+		{in: " 5/h ", want: Limit{Requests: 5, Period: time.Hour}},
+		{in: "30", wantErr: true},
+		{in: "0/m", wantErr: true},
+		{in: "x/m", wantErr: true},
+		{in: "30/fortnight", wantErr: true},
+		{in: "30/m:0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseLimit(%q): expected an error, got %v", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLimit(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestPolicy_Match(t *testing.T) {
+	policy, err := ParsePolicy("100/m", []string{"/pullRequest/create=10/m"})
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+
+	limit, scope, ok := policy.Match("/pullRequest/create")
+	if !ok || scope != "/pullRequest/create" || limit.Requests != 10 {
+		t.Errorf("expected the route limit, got %v %q %v", limit, scope, ok)
+	}
+	limit, scope, ok = policy.Match("/team/get")
+	if !ok || scope != "*" || limit.Requests != 100 {
+		t.Errorf("expected the shared default limit, got %v %q %v", limit, scope, ok)
+	}
+
+	routesOnly, _ := ParsePolicy("", []string{"/pullRequest/create=10/m"})
+	if _, _, ok := routesOnly.Match("/team/get"); ok {
+		t.Error("expected routes without a limit to be unlimited without a default")
+	}
+
+	if _, err := ParsePolicy("", []string{"pullRequest/create=10/m"}); err == nil {
+		t.Error("expected an error for a route without a leading slash")
+	}
+}
+
+func TestMemoryLimiter_TokenBucket(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter()
+	limiter.now = func() time.Time { return now }
+	limit := Limit{Requests: 2, Period: time.Second, Burst: 3}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		d, _ := limiter.Take(ctx, "ci", limit)
+		if !d.Allowed || d.Remaining != 2-i {
+			t.Fatalf("request %d: expected allowed with %d remaining, got %+v", i, 2-i, d)
+		}
+	}
+
+	d, _ := limiter.Take(ctx, "ci", limit)
+	if d.Allowed {
+		t.Fatal("expected the burst to be exhausted")
+	}
+	if d.RetryAfter != 500*time.Millisecond {
+		t.Errorf("expected to retry after one token, 500ms, got %v", d.RetryAfter)
+	}
+	if d.Reset != 1500*time.Millisecond {
+		t.Errorf("expected the bucket to be full in 1.5s, got %v", d.Reset)
+	}
+
+	if d, _ := limiter.Take(ctx, "other", limit); !d.Allowed {
+		t.Error("expected another client to have its own bucket")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if d, _ := limiter.Take(ctx, "ci", limit); !d.Allowed || d.Remaining != 0 {
+		t.Errorf("expected one refilled token, got %+v", d)
+	}
+
+	now = now.Add(time.Hour)
+	if d, _ := limiter.Take(ctx, "ci", limit); !d.Allowed || d.Remaining != 2 {
+		t.Errorf("expected the bucket to refill only up to the burst, got %+v", d)
+	}
+	if len(limiter.buckets) != 1 {
+		t.Errorf("expected full buckets to be swept, %d left", len(limiter.buckets))
+	}
+}
+
+type fakeStore struct {
+	tokens  float64
+	allowed bool
+}
+
+func (f *fakeStore) Take(ctx context.Context, key string, capacity, perSecond float64) (float64, bool, error) {
+	return f.tokens, f.allowed, nil
+}
+
+func TestSharedLimiter_Decision(t *testing.T) {
+	limiter := NewSharedLimiter(&fakeStore{tokens: 0.25, allowed: false})
+
+	d, err := limiter.Take(context.Background(), "ci", Limit{Requests: 10, Period: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+	if d.Allowed || d.Remaining != 0 || d.RetryAfter != 750*time.Millisecond {
+		t.Errorf("unexpected decision %+v", d)
+	}
+}
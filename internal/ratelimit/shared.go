@@ -0,0 +1,30 @@
+package ratelimit
+
+import (
+	"context"
+)
+
+// BucketStore keeps token buckets in a database shared by every replica;
+// repository.RateLimitRepository implements it. Take refills the bucket of key, takes
+// a token when there is one and returns the tokens left.
+type BucketStore interface {
+	Take(ctx context.Context, key string, capacity, perSecond float64) (float64, bool, error)
+}
+
+// SharedLimiter enforces limits across replicas at the cost of a database round trip
+// per request.
+type SharedLimiter struct {
+	store BucketStore
+}
+
+func NewSharedLimiter(store BucketStore) *SharedLimiter {
+	return &SharedLimiter{store: store}
+}
+
+func (s *SharedLimiter) Take(ctx context.Context, key string, limit Limit) (Decision, error) {
+	tokens, allowed, err := s.store.Take(ctx, key, float64(limit.Capacity()), limit.perSecond())
+	if err != nil {
+		return Decision{}, err
+	}
+	return decide(limit, tokens, allowed), nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+type PostgresAPIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(pool *pgxpool.Pool) APIKeyRepository {
+	return &PostgresAPIKeyRepository{pool: pool}
+}
+
+const apiKeyColumns = `key_id, tenant_id, name, scopes, created_at, rotated_at, last_used_at, revoked_at`
+
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey, hash string) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO api_keys (key_id, tenant_id, name, key_hash, scopes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	if _, err := q.Exec(ctx, query, key.KeyID, key.TenantID, key.Name, hash, scopesToStrings(key.Scopes), key.CreatedAt); err != nil {
+		return fmt.Errorf("insert API key: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID returns the key together with the hash of its secret.
+func (r *PostgresAPIKeyRepository) GetByID(ctx context.Context, keyID string) (*domain.APIKey, string, error) {
+	q := getQuerier(ctx, r.pool)
+
+	var hash string
+	key, err := scanAPIKey(q.QueryRow(ctx,
+		`SELECT `+apiKeyColumns+`, key_hash FROM api_keys WHERE key_id = $1 AND tenant_id = $2`,
+		keyID, tenant.ID(ctx),
+	), &hash)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, hash, nil
+}
+
+// Lookup is GetByID across all tenants. It serves authentication, which learns the
+// tenant from the key.
+func (r *PostgresAPIKeyRepository) Lookup(ctx context.Context, keyID string) (*domain.APIKey, string, error) {
+	q := getQuerier(ctx, r.pool)
+
+	var hash string
+	key, err := scanAPIKey(q.QueryRow(ctx,
+		`SELECT `+apiKeyColumns+`, key_hash FROM api_keys WHERE key_id = $1`,
+		keyID,
+	), &hash)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, hash, nil
+}
+
+func (r *PostgresAPIKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	q := getQuerier(ctx, r.pool)
+
+	rows, err := q.Query(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE tenant_id = $1 ORDER BY created_at, key_id`,
+		tenant.ID(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Rotate replaces the secret hash of a key that has not been revoked.
+func (r *PostgresAPIKeyRepository) Rotate(ctx context.Context, keyID, hash string, at time.Time) (*domain.APIKey, error) {
+	q := getQuerier(ctx, r.pool)
+
+	return scanAPIKey(q.QueryRow(ctx, `
+		UPDATE api_keys SET key_hash = $2, rotated_at = $3
+		WHERE key_id = $1 AND tenant_id = $4 AND revoked_at IS NULL
+		RETURNING `+apiKeyColumns,
+		keyID, hash, at, tenant.ID(ctx),
+	))
+}
+
+// Revoke keeps the original revocation time when the key is revoked already.
+func (r *PostgresAPIKeyRepository) Revoke(ctx context.Context, keyID string, at time.Time) (*domain.APIKey, error) {
+	q := getQuerier(ctx, r.pool)
+
+	return scanAPIKey(q.QueryRow(ctx, `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2)
+		WHERE key_id = $1 AND tenant_id = $3
+		RETURNING `+apiKeyColumns,
+		keyID, at, tenant.ID(ctx),
+	))
+}
+
+// TouchLastUsed records a use of the key. Uses within a minute of the recorded one are
+// skipped so that busy clients do not cause a write per request. Like Lookup it runs
+// before the tenant is known; key IDs are unique across tenants.
+func (r *PostgresAPIKeyRepository) TouchLastUsed(ctx context.Context, keyID string, at time.Time) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		UPDATE api_keys SET last_used_at = $2
+		WHERE key_id = $1 AND (last_used_at IS NULL OR last_used_at < $2 - INTERVAL '1 minute')
+	`
+
+	if _, err := q.Exec(ctx, query, keyID, at); err != nil {
+		return fmt.Errorf("update API key last use: %w", err)
+	}
+
+	return nil
+}
+
+func scanAPIKey(row pgx.Row, extra ...any) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes []string
+	dest := append([]any{
+		&key.KeyID,
+		&key.TenantID,
+		&key.Name,
+		&scopes,
+		&key.CreatedAt,
+		&key.RotatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("scan API key: %w", err)
+	}
+
+	key.Scopes = make([]domain.Scope, len(scopes))
+	for i, s := range scopes {
+		key.Scopes[i] = domain.Scope(s)
+	}
+
+	return &key, nil
+}
+
+func scopesToStrings(scopes []domain.Scope) []string {
+	result := make([]string, len(scopes))
+	for i, s := range scopes {
+		result[i] = string(s)
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+type PostgresAuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) AuditRepository {
+	return &PostgresAuditRepository{pool: pool}
+}
+
+// Record appends an entry. Called with a transaction context, the entry is kept only
+// if the audited change commits.
+func (r *PostgresAuditRepository) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	q := getQuerier(ctx, r.pool)
+
+	before, err := marshalAuditState(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshalAuditState(entry.After)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_log (actor, action, target_type, target_id, before_state, after_state, request_id, source_ip, created_at, tenant_id)
+		VALUES (NULLIF($1, ''), $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10)
+		RETURNING entry_id
+	`
+
+	err = q.QueryRow(ctx, query,
+		entry.Actor,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		before,
+		after,
+		entry.RequestID,
+		entry.SourceIP,
+		entry.CreatedAt,
+		tenant.ID(ctx),
+	).Scan(&entry.EntryID)
+	if err != nil {
+		return fmt.Errorf("insert audit entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresAuditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	q := getQuerier(ctx, r.pool)
+
+	args := []any{filter.AfterID, tenant.ID(ctx)}
+	conds := []string{"entry_id > $1", "tenant_id = $2"}
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		conds = append(conds, fmt.Sprintf("actor = $%d", len(args)))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		conds = append(conds, fmt.Sprintf("action = $%d", len(args)))
+	}
+	if filter.TargetType != "" {
+		args = append(args, filter.TargetType)
+		conds = append(conds, fmt.Sprintf("target_type = $%d", len(args)))
+	}
+	if filter.TargetID != "" {
+		args = append(args, filter.TargetID)
+		conds = append(conds, fmt.Sprintf("target_id = $%d", len(args)))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conds = append(conds, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conds = append(conds, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT entry_id, COALESCE(actor, ''), action, target_type, target_id,
+			before_state, after_state, COALESCE(request_id, ''), COALESCE(source_ip, ''), created_at
+		FROM audit_log
+		WHERE %s
+		ORDER BY entry_id
+		LIMIT $%d
+	`, strings.Join(conds, " AND "), len(args))
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		var e domain.AuditEntry
+		var before, after []byte
+		if err := rows.Scan(
+			&e.EntryID,
+			&e.Actor,
+			&e.Action,
+			&e.TargetType,
+			&e.TargetID,
+			&before,
+			&after,
+			&e.RequestID,
+			&e.SourceIP,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		if e.Before, err = unmarshalAuditState(before); err != nil {
+			return nil, err
+		}
+		if e.After, err = unmarshalAuditState(after); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// marshalAuditState keeps a nil state as SQL NULL rather than JSON null.
+func marshalAuditState(state map[string]any) ([]byte, error) {
+	if state == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("marshal audit state: %w", err)
+	}
+	return data, nil
+}
+
+func unmarshalAuditState(data []byte) (map[string]any, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var state map[string]any
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("unmarshal audit state: %w", err)
+	}
+	return state, nil
+}
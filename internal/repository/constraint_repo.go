@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+type PostgresConstraintRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewConstraintRepository(pool *pgxpool.Pool) ConstraintRepository {
+	return &PostgresConstraintRepository{pool: pool}
+}
+
+func (r *PostgresConstraintRepository) AddExclusion(ctx context.Context, pair *domain.ExclusionPair) error {
+	if err := pair.Validate(); err != nil {
+		return fmt.Errorf("invalid exclusion pair: %w", err)
+	}
+
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO team_reviewer_exclusions (team_name, user_a, user_b, created_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, team_name, user_a, user_b) DO NOTHING
+	`
+
+	_, err := q.Exec(ctx, query, pair.TeamName, pair.UserA, pair.UserB, pair.CreatedAt, tenant.ID(ctx))
+	if err != nil {
+		return fmt.Errorf("insert exclusion pair: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresConstraintRepository) RemoveExclusion(ctx context.Context, pair *domain.ExclusionPair) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		DELETE FROM team_reviewer_exclusions
+		WHERE team_name = $1 AND user_a = $2 AND user_b = $3 AND tenant_id = $4
+	`
+
+	result, err := q.Exec(ctx, query, pair.TeamName, pair.UserA, pair.UserB, tenant.ID(ctx))
+	if err != nil {
+		return fmt.Errorf("delete exclusion pair: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrExclusionNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresConstraintRepository) ListExclusionsByTeam(ctx context.Context, teamName string) ([]*domain.ExclusionPair, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		SELECT team_name, user_a, user_b, created_at
+		FROM team_reviewer_exclusions
+		WHERE team_name = $1 AND tenant_id = $2
+		ORDER BY user_a, user_b
+	`
+
+	rows, err := q.Query(ctx, query, teamName, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query exclusion pairs: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []*domain.ExclusionPair
+	for rows.Next() {
+		var pair domain.ExclusionPair
+		if err := rows.Scan(&pair.TeamName, &pair.UserA, &pair.UserB, &pair.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan exclusion pair: %w", err)
+		}
+		pairs = append(pairs, &pair)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate exclusion pairs: %w", err)
+	}
+
+	return pairs, nil
+}
+
+func (r *PostgresConstraintRepository) AddNeverAssign(ctx context.Context, entry *domain.NeverAssign) error {
+	if err := entry.Validate(); err != nil {
+		return fmt.Errorf("invalid never-assign entry: %w", err)
+	}
+
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO author_never_assign (author_id, reviewer_id, created_at, tenant_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, author_id, reviewer_id) DO NOTHING
+	`
+
+	_, err := q.Exec(ctx, query, entry.AuthorID, entry.ReviewerID, entry.CreatedAt, tenant.ID(ctx))
+	if err != nil {
+		return fmt.Errorf("insert never-assign entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresConstraintRepository) RemoveNeverAssign(ctx context.Context, authorID, reviewerID string) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `DELETE FROM author_never_assign WHERE author_id = $1 AND reviewer_id = $2 AND tenant_id = $3`
+
+	result, err := q.Exec(ctx, query, authorID, reviewerID, tenant.ID(ctx))
+	if err != nil {
+		return fmt.Errorf("delete never-assign entry: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNeverAssignMissing
+	}
+
+	return nil
+}
+
+func (r *PostgresConstraintRepository) ListNeverAssignByAuthor(ctx context.Context, authorID string) ([]*domain.NeverAssign, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		SELECT author_id, reviewer_id, created_at
+		FROM author_never_assign
+		WHERE author_id = $1 AND tenant_id = $2
+		ORDER BY reviewer_id
+	`
+
+	return r.queryNeverAssign(ctx, q, query, authorID)
+}
+
+// GetForTeam returns the team's exclusion pairs and the never-assign entries of its members.
+func (r *PostgresConstraintRepository) GetForTeam(ctx context.Context, teamName string) (*domain.ReviewConstraints, error) {
+	pairs, err := r.ListExclusionsByTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		SELECT n.author_id, n.reviewer_id, n.created_at
+		FROM author_never_assign n
+		INNER JOIN users u ON u.tenant_id = n.tenant_id AND u.user_id = n.author_id
+		WHERE u.team_name = $1 AND n.tenant_id = $2
+		ORDER BY n.author_id, n.reviewer_id
+	`
+
+	entries, err := r.queryNeverAssign(ctx, q, query, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ReviewConstraints{Pairs: pairs, NeverAssign: entries}, nil
+}
+
+// GetForAuthor returns every constraint that can affect reviewer selection for the author's pull requests.
+func (r *PostgresConstraintRepository) GetForAuthor(ctx context.Context, authorID string) (*domain.ReviewConstraints, error) {
+	q := getQuerier(ctx, r.pool)
+
+	pairsQuery := `
+		SELECT team_name, user_a, user_b, created_at
+		FROM team_reviewer_exclusions
+		WHERE (user_a = $1 OR user_b = $1) AND tenant_id = $2
+	`
+
+	rows, err := q.Query(ctx, pairsQuery, authorID, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query exclusion pairs: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []*domain.ExclusionPair
+	for rows.Next() {
+		var pair domain.ExclusionPair
+		if err := rows.Scan(&pair.TeamName, &pair.UserA, &pair.UserB, &pair.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan exclusion pair: %w", err)
+		}
+		pairs = append(pairs, &pair)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate exclusion pairs: %w", err)
+	}
+
+	entries, err := r.ListNeverAssignByAuthor(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ReviewConstraints{Pairs: pairs, NeverAssign: entries}, nil
+}
+
+// queryNeverAssign runs query with arg as $1 and the tenant of ctx as $2.
+func (r *PostgresConstraintRepository) queryNeverAssign(ctx context.Context, q querier, query string, arg string) ([]*domain.NeverAssign, error) {
+	rows, err := q.Query(ctx, query, arg, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query never-assign entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.NeverAssign
+	for rows.Next() {
+		var entry domain.NeverAssign
+		if err := rows.Scan(&entry.AuthorID, &entry.ReviewerID, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan never-assign entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate never-assign entries: %w", err)
+	}
+
+	return entries, nil
+}
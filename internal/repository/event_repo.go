@@ -0,0 +1,375 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+type PostgresEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewEventRepository(pool *pgxpool.Pool) EventRepository {
+	return &PostgresEventRepository{pool: pool}
+}
+
+func (r *PostgresEventRepository) Record(ctx context.Context, event *domain.PREvent) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO pr_events (pr_id, event_type, actor_id, user_id, replaced_by, reason, comment, created_at, tenant_id)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8, $9)
+		RETURNING event_id
+	`
+
+	err := q.QueryRow(ctx, query,
+		event.PRID,
+		event.Type,
+		event.ActorID,
+		event.UserID,
+		event.ReplacedBy,
+		event.Reason,
+		event.Comment,
+		event.CreatedAt,
+		tenant.ID(ctx),
+	).Scan(&event.EventID)
+	if err != nil {
+		return fmt.Errorf("insert PR event: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresEventRepository) ListByPR(ctx context.Context, prID string) ([]*domain.PREvent, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `SELECT ` + prEventColumns + `
+		FROM pr_events
+		WHERE pr_id = $1 AND tenant_id = $2
+		ORDER BY event_id
+	`
+
+	rows, err := q.Query(ctx, query, prID, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query PR events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPREvents(rows)
+}
+
+func (r *PostgresEventRepository) CountByUser(ctx context.Context, userID string, eventType domain.PREventType, since time.Time) (int, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		SELECT COUNT(*)
+		FROM pr_events
+		WHERE user_id = $1 AND event_type = $2 AND created_at >= $3 AND tenant_id = $4
+	`
+
+	var count int
+	if err := q.QueryRow(ctx, query, userID, eventType, since, tenant.ID(ctx)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count PR events: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetDeclineStats counts assignments and declines per reviewer in the window of filter.
+// Reassignments count as an assignment for the reviewer who took over. The team filter
+// applies to the reviewer.
+func (r *PostgresEventRepository) GetDeclineStats(ctx context.Context, filter domain.StatsFilter) ([]*domain.DeclineStat, error) {
+	q := getQuerier(ctx, r.pool)
+
+	assignedConds, args := statsConditions(ctx, filter, "a.tenant_id", "u.team_name", "a.assigned_at", nil)
+	declinedConds, args := statsConditions(ctx, filter, "e.tenant_id", "u.team_name", "e.created_at", args)
+	query := fmt.Sprintf(`
+		WITH assigned AS (
+			SELECT a.reviewer_id FROM reviewer_assignments a
+			INNER JOIN users u ON u.tenant_id = a.tenant_id AND u.user_id = a.reviewer_id
+			WHERE %s
+		),
+		declined AS (
+			SELECT e.user_id AS reviewer_id, e.reason FROM pr_events e
+			INNER JOIN users u ON u.tenant_id = e.tenant_id AND u.user_id = e.user_id
+			WHERE e.event_type = 'REVIEWER_DECLINED' AND %s
+		)
+		SELECT reviewer_id, 0 AS assignments, reason, COUNT(*) FROM declined GROUP BY reviewer_id, reason
+		UNION ALL
+		SELECT reviewer_id, COUNT(*), NULL, 0 FROM assigned GROUP BY reviewer_id
+	`, assignedConds, declinedConds)
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query decline stats: %w", err)
+	}
+	defer rows.Close()
+
+	statsByUser := make(map[string]*domain.DeclineStat)
+	var order []string
+	for rows.Next() {
+		var userID string
+		var assignments, declines int
+		var reason *string
+		if err := rows.Scan(&userID, &assignments, &reason, &declines); err != nil {
+			return nil, fmt.Errorf("scan decline stat: %w", err)
+		}
+
+		stat, ok := statsByUser[userID]
+		if !ok {
+			stat = &domain.DeclineStat{UserID: userID, ByReason: make(map[domain.DeclineReason]int)}
+			statsByUser[userID] = stat
+			order = append(order, userID)
+		}
+		stat.Assignments += assignments
+		if reason != nil {
+			stat.Declines += declines
+			stat.ByReason[domain.DeclineReason(*reason)] += declines
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate decline stats: %w", err)
+	}
+
+	stats := make([]*domain.DeclineStat, len(order))
+	for i, userID := range order {
+		stats[i] = statsByUser[userID]
+	}
+
+	return stats, nil
+}
+
+// ListOpenByTeam returns the timelines of open pull requests authored in the team,
+// ordered by pull request and event.
+func (r *PostgresEventRepository) ListOpenByTeam(ctx context.Context, teamName string) ([]*domain.PREvent, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `SELECT ` + prEventColumns + `
+		FROM pr_events
+		WHERE tenant_id = $2 AND pr_id IN (
+			SELECT pr.pull_request_id
+			FROM pull_requests pr
+			INNER JOIN users u ON u.tenant_id = pr.tenant_id AND u.user_id = pr.author_id
+			WHERE pr.status = 'OPEN' AND u.team_name = $1 AND pr.tenant_id = $2
+		)
+		ORDER BY pr_id, event_id
+	`
+
+	rows, err := q.Query(ctx, query, teamName, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query open PR events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPREvents(rows)
+}
+
+// ListAfter returns up to limit events with IDs above afterID, in ID order.
+func (r *PostgresEventRepository) ListAfter(ctx context.Context, afterID int64, limit int) ([]*domain.PREvent, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `SELECT ` + prEventColumns + `
+		FROM pr_events
+		WHERE event_id > $1 AND tenant_id = $3
+		ORDER BY event_id
+		LIMIT $2
+	`
+
+	rows, err := q.Query(ctx, query, afterID, limit, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query PR events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPREvents(rows)
+}
+
+// ListByIDs returns the events with the given IDs that exist, in ID order.
+func (r *PostgresEventRepository) ListByIDs(ctx context.Context, ids []int64) ([]*domain.PREvent, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `SELECT ` + prEventColumns + `
+		FROM pr_events
+		WHERE event_id = ANY($1) AND tenant_id = $2
+		ORDER BY event_id
+	`
+
+	rows, err := q.Query(ctx, query, ids, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query PR events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPREvents(rows)
+}
+
+// MissingIDs returns up to limit of the highest IDs between afterID and beforeID that no
+// event of any tenant has yet, in ID order. They belong to transactions that have not
+// committed, or rolled back.
+func (r *PostgresEventRepository) MissingIDs(ctx context.Context, afterID, beforeID int64, limit int) ([]int64, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		SELECT id FROM (
+			SELECT id FROM generate_series($1::bigint + 1, $2::bigint - 1) AS id
+			WHERE NOT EXISTS (SELECT 1 FROM pr_events WHERE event_id = id)
+			ORDER BY id DESC
+			LIMIT $3
+		) missing
+		ORDER BY id
+	`
+
+	rows, err := q.Query(ctx, query, afterID, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query missing PR event IDs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEventIDs(rows)
+}
+
+// ExistingIDs returns the given IDs that an event of any tenant has.
+func (r *PostgresEventRepository) ExistingIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	q := getQuerier(ctx, r.pool)
+
+	rows, err := q.Query(ctx, `SELECT event_id FROM pr_events WHERE event_id = ANY($1) ORDER BY event_id`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("query PR event IDs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEventIDs(rows)
+}
+
+// LatestID spans every tenant: event IDs are global, so the latest one is a valid
+// starting point for a cursor of any tenant.
+func (r *PostgresEventRepository) LatestID(ctx context.Context) (int64, error) {
+	q := getQuerier(ctx, r.pool)
+
+	var id int64
+	if err := q.QueryRow(ctx, `SELECT COALESCE(MAX(event_id), 0) FROM pr_events`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("query latest PR event: %w", err)
+	}
+
+	return id, nil
+}
+
+// ListFeed returns up to limit events of every tenant with IDs above afterID together
+// with the tenant, author and team of their pull requests, in ID order. It feeds the
+// live broker, which filters by tenant for each subscriber.
+func (r *PostgresEventRepository) ListFeed(ctx context.Context, afterID int64, limit int) ([]*domain.FeedEvent, error) {
+	return r.listFeed(ctx, `e.event_id > $1 ORDER BY e.event_id LIMIT $2`, afterID, limit)
+}
+
+// ListFeedByIDs returns the events of every tenant with the given IDs that exist, like
+// ListFeed. The broker uses it to pick up events that committed after higher IDs.
+func (r *PostgresEventRepository) ListFeedByIDs(ctx context.Context, ids []int64) ([]*domain.FeedEvent, error) {
+	return r.listFeed(ctx, `e.event_id = ANY($1)`, ids)
+}
+
+func (r *PostgresEventRepository) listFeed(ctx context.Context, condition string, args ...any) ([]*domain.FeedEvent, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `SELECT ` + prEventColumns + `, tenant_id, author_id, team_name
+		FROM (
+			SELECT e.*, pr.author_id, u.team_name
+			FROM pr_events e
+			INNER JOIN pull_requests pr ON pr.tenant_id = e.tenant_id AND pr.pull_request_id = e.pr_id
+			INNER JOIN users u ON u.tenant_id = pr.tenant_id AND u.user_id = pr.author_id
+			WHERE ` + condition + `
+		) feed
+		ORDER BY event_id
+	`
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query event feed: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.FeedEvent
+	for rows.Next() {
+		e := domain.FeedEvent{PREvent: &domain.PREvent{}}
+		if err := rows.Scan(
+			&e.EventID,
+			&e.PRID,
+			&e.Type,
+			&e.ActorID,
+			&e.UserID,
+			&e.ReplacedBy,
+			&e.Reason,
+			&e.Comment,
+			&e.CreatedAt,
+			&e.TenantID,
+			&e.AuthorID,
+			&e.TeamName,
+		); err != nil {
+			return nil, fmt.Errorf("scan feed event: %w", err)
+		}
+		events = append(events, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate feed events: %w", err)
+	}
+
+	return events, nil
+}
+
+// prEventColumns lists pr_events columns in the order scanPREvents expects.
+const prEventColumns = `
+	event_id, pr_id, event_type,
+	COALESCE(actor_id, ''), COALESCE(user_id, ''), COALESCE(replaced_by, ''),
+	COALESCE(reason, ''), COALESCE(comment, ''), created_at`
+
+func scanPREvents(rows pgx.Rows) ([]*domain.PREvent, error) {
+	var events []*domain.PREvent
+	for rows.Next() {
+		var e domain.PREvent
+		if err := rows.Scan(
+			&e.EventID,
+			&e.PRID,
+			&e.Type,
+			&e.ActorID,
+			&e.UserID,
+			&e.ReplacedBy,
+			&e.Reason,
+			&e.Comment,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan PR event: %w", err)
+		}
+		events = append(events, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate PR events: %w", err)
+	}
+
+	return events, nil
+}
+
+func scanEventIDs(rows pgx.Rows) ([]int64, error) {
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan PR event ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate PR event IDs: %w", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+type PostgresIdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(pool *pgxpool.Pool) IdempotencyRepository {
+	return &PostgresIdempotencyRepository{pool: pool}
+}
+
+// Claim reserves key for a request with the given fingerprint and returns nil, or
+// returns the record of the request that holds it. A key is free when it is new, has
+// expired, or its request was not completed within lease, which happens when a replica
+// dies mid-request. The database clock is used so that replicas agree.
+func (r *PostgresIdempotencyRepository) Claim(ctx context.Context, key, fingerprint string, ttl, lease time.Duration) (*domain.IdempotencyRecord, error) {
+	q := getQuerier(ctx, r.pool)
+
+	claim := `
+		INSERT INTO idempotency_keys AS k
+			(tenant_id, idempotency_key, fingerprint, created_at, locked_until, expires_at)
+		VALUES ($1, $2, $3, clock_timestamp()::timestamp,
+			clock_timestamp()::timestamp + make_interval(secs => $4),
+			clock_timestamp()::timestamp + make_interval(secs => $5))
+		ON CONFLICT (tenant_id, idempotency_key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			status_code = NULL,
+			content_type = NULL,
+			etag = NULL,
+			body = NULL,
+			created_at = EXCLUDED.created_at,
+			locked_until = EXCLUDED.locked_until,
+			expires_at = EXCLUDED.expires_at
+		WHERE k.expires_at <= EXCLUDED.created_at
+		   OR (k.status_code IS NULL AND k.locked_until <= EXCLUDED.created_at)
+		RETURNING TRUE
+	`
+
+	var claimed bool
+	err := q.QueryRow(ctx, claim, tenant.ID(ctx), key, fingerprint, lease.Seconds(), ttl.Seconds()).Scan(&claimed)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("claim idempotency key: %w", err)
+	}
+
+	query := `
+		SELECT fingerprint, COALESCE(status_code, 0), COALESCE(content_type, ''), COALESCE(etag, ''), body
+		FROM idempotency_keys
+		WHERE tenant_id = $1 AND idempotency_key = $2
+	`
+
+	var record domain.IdempotencyRecord
+	err = q.QueryRow(ctx, query, tenant.ID(ctx), key).Scan(
+		&record.Fingerprint,
+		&record.StatusCode,
+		&record.ContentType,
+		&record.ETag,
+		&record.Body,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+
+	return &record, nil
+}
+
+// Complete stores the response of the request that claimed key.
+func (r *PostgresIdempotencyRepository) Complete(ctx context.Context, key string, record *domain.IdempotencyRecord) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $4, content_type = $5, etag = $6, body = $7
+		WHERE tenant_id = $1 AND idempotency_key = $2 AND fingerprint = $3 AND status_code IS NULL
+	`
+
+	_, err := q.Exec(ctx, query,
+		tenant.ID(ctx),
+		key,
+		record.Fingerprint,
+		record.StatusCode,
+		record.ContentType,
+		record.ETag,
+		record.Body,
+	)
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Release frees key claimed by a request with the given fingerprint that was not
+// completed, so that a retry is handled anew.
+func (r *PostgresIdempotencyRepository) Release(ctx context.Context, key, fingerprint string) error {
+	q := getQuerier(ctx, r.pool)
+
+	_, err := q.Exec(ctx,
+		`DELETE FROM idempotency_keys
+		WHERE tenant_id = $1 AND idempotency_key = $2 AND fingerprint = $3 AND status_code IS NULL`,
+		tenant.ID(ctx), key, fingerprint,
+	)
+	if err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes the keys of every tenant whose TTL has passed.
+func (r *PostgresIdempotencyRepository) DeleteExpired(ctx context.Context) (int, error) {
+	q := getQuerier(ctx, r.pool)
+
+	tag, err := q.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= clock_timestamp()::timestamp`)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
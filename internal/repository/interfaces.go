@@ -7,9 +7,19 @@ import (
 	"github.com/mivihan/Pull_Request_service/internal/domain"
 )
 
+// TenantRepository stores the tenants themselves; unlike every other repository it is
+// not scoped to the tenant of the context.
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *domain.Tenant) error
+	GetByID(ctx context.Context, tenantID string) (*domain.Tenant, error)
+	List(ctx context.Context) ([]*domain.Tenant, error)
+}
+
 type TeamRepository interface {
 	Create(ctx context.Context, team *domain.Team) error
 	GetByName(ctx context.Context, teamName string) (*domain.Team, error)
+	GetByNameForUpdate(ctx context.Context, teamName string) (*domain.Team, error)
+	BumpVersion(ctx context.Context, teamName string) (int64, error)
 	Exists(ctx context.Context, teamName string) (bool, error)
 }
 
@@ -17,6 +27,8 @@ type UserRepository interface {
 	Upsert(ctx context.Context, user *domain.User) error
 	GetByID(ctx context.Context, userID string) (*domain.User, error)
 	SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error)
+	SetSlackMemberID(ctx context.Context, userID, memberID string) (*domain.User, error)
+	SetEmail(ctx context.Context, userID, email string) (*domain.User, error)
 	ListByTeam(ctx context.Context, teamName string) ([]*domain.User, error)
 	ListActiveByTeamExcluding(ctx context.Context, teamName string, excludeUserIDs []string) ([]*domain.User, error)
 	DeactivateUsers(ctx context.Context, teamName string, userIDs []string) (int, error)
@@ -25,16 +37,139 @@ type UserRepository interface {
 type PRRepository interface {
 	Create(ctx context.Context, pr *domain.PullRequest) error
 	GetByID(ctx context.Context, prID string) (*domain.PullRequest, error)
+	GetByIDForUpdate(ctx context.Context, prID string) (*domain.PullRequest, error)
 	Exists(ctx context.Context, prID string) (bool, error)
 	UpdateStatus(ctx context.Context, prID string, status domain.PRStatus, mergedAt *time.Time) error
+	BumpVersion(ctx context.Context, prID string) (int64, error)
 	AssignReviewers(ctx context.Context, prID string, userIDs []string) error
 	ReplaceReviewer(ctx context.Context, prID string, oldUserID, newUserID string) error
+	AddReviewer(ctx context.Context, prID, userID string) error
+	RemoveReviewer(ctx context.Context, prID, userID string) error
 	ListByReviewer(ctx context.Context, userID string) ([]*domain.PullRequest, error)
-	GetReviewerStats(ctx context.Context) (map[string]int, error)
-	GetPRStats(ctx context.Context) (map[string]int, error)
+	GetReviewerStats(ctx context.Context, filter domain.StatsFilter) (map[string]int, error)
+	GetPRStats(ctx context.Context, filter domain.StatsFilter) (map[string]int, error)
 	GetOpenPRsByReviewers(ctx context.Context, userIDs []string) ([]*domain.PullRequest, error)
 }
 
+type ConstraintRepository interface {
+	AddExclusion(ctx context.Context, pair *domain.ExclusionPair) error
+	RemoveExclusion(ctx context.Context, pair *domain.ExclusionPair) error
+	ListExclusionsByTeam(ctx context.Context, teamName string) ([]*domain.ExclusionPair, error)
+	AddNeverAssign(ctx context.Context, entry *domain.NeverAssign) error
+	RemoveNeverAssign(ctx context.Context, authorID, reviewerID string) error
+	ListNeverAssignByAuthor(ctx context.Context, authorID string) ([]*domain.NeverAssign, error)
+	GetForTeam(ctx context.Context, teamName string) (*domain.ReviewConstraints, error)
+	GetForAuthor(ctx context.Context, authorID string) (*domain.ReviewConstraints, error)
+}
+
+type EventRepository interface {
+	Record(ctx context.Context, event *domain.PREvent) error
+	ListByPR(ctx context.Context, prID string) ([]*domain.PREvent, error)
+	CountByUser(ctx context.Context, userID string, eventType domain.PREventType, since time.Time) (int, error)
+	GetDeclineStats(ctx context.Context, filter domain.StatsFilter) ([]*domain.DeclineStat, error)
+	ListOpenByTeam(ctx context.Context, teamName string) ([]*domain.PREvent, error)
+	ListAfter(ctx context.Context, afterID int64, limit int) ([]*domain.PREvent, error)
+	ListByIDs(ctx context.Context, ids []int64) ([]*domain.PREvent, error)
+	MissingIDs(ctx context.Context, afterID, beforeID int64, limit int) ([]int64, error)
+	ExistingIDs(ctx context.Context, ids []int64) ([]int64, error)
+	LatestID(ctx context.Context) (int64, error)
+	ListFeed(ctx context.Context, afterID int64, limit int) ([]*domain.FeedEvent, error)
+	ListFeedByIDs(ctx context.Context, ids []int64) ([]*domain.FeedEvent, error)
+}
+
+type SettingsRepository interface {
+	Get(ctx context.Context, teamName string) (*domain.TeamSettings, error)
+	Upsert(ctx context.Context, settings *domain.TeamSettings) error
+	ListWithSLA(ctx context.Context) ([]*domain.TeamSettings, error)
+	ListWithStaleTimeout(ctx context.Context) ([]*domain.TeamSettings, error)
+}
+
+type ReminderRepository interface {
+	GetPreferences(ctx context.Context, userID string) (*domain.ReminderPreferences, error)
+	UpsertPreferences(ctx context.Context, prefs *domain.ReminderPreferences) error
+	ListRecipients(ctx context.Context) ([]*domain.User, error)
+	LastReminded(ctx context.Context, userID string) (map[string]time.Time, error)
+	RecordReminders(ctx context.Context, userID string, prIDs []string, sentAt time.Time) error
+	ForgetReminders(ctx context.Context, userID string, prIDs []string, sentAt time.Time) error
+}
+
+// NotificationRepository keeps team chat templates and the position of event delivery
+// in pr_events, with the event IDs it moved past before they committed.
+type NotificationRepository interface {
+	GetTemplate(ctx context.Context, teamName string, kind domain.NotificationKind) (string, error)
+	SetTemplate(ctx context.Context, tmpl *domain.MessageTemplate) error
+	DeleteTemplate(ctx context.Context, teamName string, kind domain.NotificationKind) error
+	ListTemplates(ctx context.Context, teamName string) ([]*domain.MessageTemplate, error)
+	GetCursor(ctx context.Context, name string) (int64, bool, error)
+	SetCursor(ctx context.Context, name string, eventID int64) error
+	ListGaps(ctx context.Context, name string) ([]int64, error)
+	AddGaps(ctx context.Context, name string, eventIDs []int64, skippedAt time.Time) error
+	DeleteGaps(ctx context.Context, name string, eventIDs []int64) error
+	ExpireGaps(ctx context.Context, name string, before time.Time) error
+}
+
+// APIKeyRepository stores API keys. Only a hash of each secret is kept.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey, hash string) error
+	GetByID(ctx context.Context, keyID string) (*domain.APIKey, string, error)
+	Lookup(ctx context.Context, keyID string) (*domain.APIKey, string, error)
+	List(ctx context.Context) ([]*domain.APIKey, error)
+	Rotate(ctx context.Context, keyID, hash string, at time.Time) (*domain.APIKey, error)
+	Revoke(ctx context.Context, keyID string, at time.Time) (*domain.APIKey, error)
+	TouchLastUsed(ctx context.Context, keyID string, at time.Time) error
+}
+
+// RoleRepository stores roles granted on top of team membership.
+type RoleRepository interface {
+	ListByUser(ctx context.Context, userID string) ([]*domain.RoleAssignment, error)
+	ListByTeam(ctx context.Context, teamName string) ([]*domain.RoleAssignment, error)
+	Set(ctx context.Context, assignment *domain.RoleAssignment) error
+	Delete(ctx context.Context, userID, teamName string) error
+}
+
+// AuditRepository appends to and reads the audit log. Entries are never changed.
+type AuditRepository interface {
+	Record(ctx context.Context, entry *domain.AuditEntry) error
+	List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error)
+}
+
+// RateLimitRepository keeps the token buckets of the shared rate limiter.
+type RateLimitRepository interface {
+	Take(ctx context.Context, key string, capacity, perSecond float64) (float64, bool, error)
+	DeleteIdle(ctx context.Context, idle time.Duration) (int, error)
+}
+
+// IdempotencyRepository keeps the requests made with an Idempotency-Key and their
+// responses.
+type IdempotencyRepository interface {
+	Claim(ctx context.Context, key, fingerprint string, ttl, lease time.Duration) (*domain.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, record *domain.IdempotencyRecord) error
+	Release(ctx context.Context, key, fingerprint string) error
+	DeleteExpired(ctx context.Context) (int, error)
+}
+
+type StatsRepository interface {
+	ListActivityChanges(ctx context.Context, teamName string, before time.Time) ([]domain.ActivityChange, error)
+	CountAssignmentsByTeam(ctx context.Context, teamName string, from, to time.Time) (map[string]int, error)
+	GetReviewerSeries(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerStatPoint, error)
+	GetPRSeries(ctx context.Context, filter domain.StatsFilter) ([]domain.PRStatPoint, error)
+	ListPRCycleTimes(ctx context.Context, filter domain.StatsFilter, repository string) ([]*domain.PRCycleTime, error)
+	ListAssignmentEvents(ctx context.Context, filter domain.StatsFilter) ([]*domain.PREvent, error)
+}
+
+// RotationRepository stores the round-robin cursor of each team. LockCursor takes a row
+// lock and must be called inside WithTx; the lock is held until the transaction ends.
+type RotationRepository interface {
+	LockCursor(ctx context.Context, teamName string) (string, error)
+	SetCursor(ctx context.Context, teamName, userID string) error
+}
+
 type Txer interface {
 	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
+
+// Locker takes locks shared by all service replicas. A lock is held until the
+// surrounding transaction ends, so TryLock must be called inside WithTx.
+type Locker interface {
+	TryLock(ctx context.Context, name string) (bool, error)
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+type PostgresNotificationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewNotificationRepository(pool *pgxpool.Pool) NotificationRepository {
+	return &PostgresNotificationRepository{pool: pool}
+}
+
+// GetTemplate returns an empty string when the team uses the default template.
+func (r *PostgresNotificationRepository) GetTemplate(ctx context.Context, teamName string, kind domain.NotificationKind) (string, error) {
+	q := getQuerier(ctx, r.pool)
+
+	var body string
+	err := q.QueryRow(ctx,
+		`SELECT body FROM team_message_templates WHERE team_name = $1 AND kind = $2 AND tenant_id = $3`,
+		teamName, kind, tenant.ID(ctx),
+	).Scan(&body)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("query message template: %w", err)
+	}
+
+	return body, nil
+}
+
+func (r *PostgresNotificationRepository) SetTemplate(ctx context.Context, tmpl *domain.MessageTemplate) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO team_message_templates (team_name, kind, body, updated_at, tenant_id)
+		VALUES ($1, $2, $3, NOW(), $4)
+		ON CONFLICT (tenant_id, team_name, kind) DO UPDATE SET
+			body = EXCLUDED.body,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := q.Exec(ctx, query, tmpl.TeamName, tmpl.Kind, tmpl.Body, tenant.ID(ctx)); err != nil {
+		return fmt.Errorf("upsert message template: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresNotificationRepository) DeleteTemplate(ctx context.Context, teamName string, kind domain.NotificationKind) error {
+	q := getQuerier(ctx, r.pool)
+
+	_, err := q.Exec(ctx,
+		`DELETE FROM team_message_templates WHERE team_name = $1 AND kind = $2 AND tenant_id = $3`,
+		teamName, kind, tenant.ID(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("delete message template: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresNotificationRepository) ListTemplates(ctx context.Context, teamName string) ([]*domain.MessageTemplate, error) {
+	q := getQuerier(ctx, r.pool)
+
+	rows, err := q.Query(ctx,
+		`SELECT team_name, kind, body FROM team_message_templates WHERE team_name = $1 AND tenant_id = $2 ORDER BY kind`,
+		teamName, tenant.ID(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query message templates: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*domain.MessageTemplate
+	for rows.Next() {
+		var t domain.MessageTemplate
+		if err := rows.Scan(&t.TeamName, &t.Kind, &t.Body); err != nil {
+			return nil, fmt.Errorf("scan message template: %w", err)
+		}
+		result = append(result, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate message templates: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetCursor reports false when the cursor has never been stored.
+func (r *PostgresNotificationRepository) GetCursor(ctx context.Context, name string) (int64, bool, error) {
+	q := getQuerier(ctx, r.pool)
+
+	var eventID int64
+	err := q.QueryRow(ctx,
+		`SELECT last_event_id FROM notification_cursors WHERE name = $1 AND tenant_id = $2`,
+		name, tenant.ID(ctx),
+	).Scan(&eventID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("query notification cursor: %w", err)
+	}
+
+	return eventID, true, nil
+}
+
+func (r *PostgresNotificationRepository) SetCursor(ctx context.Context, name string, eventID int64) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO notification_cursors (name, last_event_id, updated_at, tenant_id)
+		VALUES ($1, $2, NOW(), $3)
+		ON CONFLICT (tenant_id, name) DO UPDATE SET
+			last_event_id = EXCLUDED.last_event_id,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := q.Exec(ctx, query, name, eventID, tenant.ID(ctx)); err != nil {
+		return fmt.Errorf("update notification cursor: %w", err)
+	}
+
+	return nil
+}
+
+// ListGaps returns the event IDs the cursor moved past without seeing them.
+func (r *PostgresNotificationRepository) ListGaps(ctx context.Context, name string) ([]int64, error) {
+	q := getQuerier(ctx, r.pool)
+
+	rows, err := q.Query(ctx,
+		`SELECT event_id FROM notification_gaps WHERE name = $1 AND tenant_id = $2 ORDER BY event_id`,
+		name, tenant.ID(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query notification gaps: %w", err)
+	}
+
+	defer rows.Close()
+
+	return scanEventIDs(rows)
+}
+
+func (r *PostgresNotificationRepository) AddGaps(ctx context.Context, name string, eventIDs []int64, skippedAt time.Time) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO notification_gaps (tenant_id, name, event_id, skipped_at)
+		SELECT $1, $2, id, $4 FROM unnest($3::bigint[]) AS id
+		ON CONFLICT (tenant_id, name, event_id) DO NOTHING
+	`
+
+	if _, err := q.Exec(ctx, query, tenant.ID(ctx), name, eventIDs, skippedAt); err != nil {
+		return fmt.Errorf("insert notification gaps: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresNotificationRepository) DeleteGaps(ctx context.Context, name string, eventIDs []int64) error {
+	q := getQuerier(ctx, r.pool)
+
+	_, err := q.Exec(ctx,
+		`DELETE FROM notification_gaps WHERE name = $1 AND tenant_id = $2 AND event_id = ANY($3)`,
+		name, tenant.ID(ctx), eventIDs,
+	)
+	if err != nil {
+		return fmt.Errorf("delete notification gaps: %w", err)
+	}
+
+	return nil
+}
+
+// ExpireGaps gives up on the gaps skipped before the given time.
+func (r *PostgresNotificationRepository) ExpireGaps(ctx context.Context, name string, before time.Time) error {
+	q := getQuerier(ctx, r.pool)
+
+	_, err := q.Exec(ctx,
+		`DELETE FROM notification_gaps WHERE name = $1 AND tenant_id = $2 AND skipped_at < $3`,
+		name, tenant.ID(ctx), before,
+	)
+	if err != nil {
+		return fmt.Errorf("expire notification gaps: %w", err)
+	}
+
+	return nil
+}
@@ -7,26 +7,68 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
 )
 
 type Repositories struct {
-	Team TeamRepository
-	User UserRepository
-	PR   PRRepository
-	pool *pgxpool.Pool
+	Tenant       TenantRepository
+	Team         TeamRepository
+	User         UserRepository
+	PR           PRRepository
+	Constraint   ConstraintRepository
+	Event        EventRepository
+	Rotation     RotationRepository
+	Stats        StatsRepository
+	Settings     SettingsRepository
+	Reminder     ReminderRepository
+	Notification NotificationRepository
+	APIKey       APIKeyRepository
+	Role         RoleRepository
+	Audit        AuditRepository
+	RateLimit    RateLimitRepository
+	Idempotency  IdempotencyRepository
+	Tx           Txer
+	Lock         Locker
 }
 
 func NewRepositories(pool *pgxpool.Pool) *Repositories {
 	return &Repositories{
-		Team: NewTeamRepository(pool),
-		User: NewUserRepository(pool),
-		PR:   NewPRRepository(pool),
-		pool: pool,
+		Tenant:       NewTenantRepository(pool),
+		Team:         NewTeamRepository(pool),
+		User:         NewUserRepository(pool),
+		PR:           NewPRRepository(pool),
+		Constraint:   NewConstraintRepository(pool),
+		Event:        NewEventRepository(pool),
+		Rotation:     NewRotationRepository(pool),
+		Stats:        NewStatsRepository(pool),
+		Settings:     NewSettingsRepository(pool),
+		Reminder:     NewReminderRepository(pool),
+		Notification: NewNotificationRepository(pool),
+		APIKey:       NewAPIKeyRepository(pool),
+		Role:         NewRoleRepository(pool),
+		Audit:        NewAuditRepository(pool),
+		RateLimit:    NewRateLimitRepository(pool),
+		Idempotency:  NewIdempotencyRepository(pool),
+		Tx:           &postgresTxer{pool: pool},
+		Lock:         &postgresLocker{},
 	}
 }
 
 func (r *Repositories) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
-	tx, err := r.pool.Begin(ctx)
+	return r.Tx.WithTx(ctx, fn)
+}
+
+type postgresTxer struct {
+	pool *pgxpool.Pool
+}
+
+func (t *postgresTxer) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("begin transaction: %w", err)
 	}
@@ -45,6 +87,25 @@ func (r *Repositories) WithTx(ctx context.Context, fn func(ctx context.Context)
 	return nil
 }
 
+// postgresLocker uses transaction-level advisory locks keyed by the hash of the name
+// within the tenant, so that tenants do not wait for each other.
+type postgresLocker struct{}
+
+func (l *postgresLocker) TryLock(ctx context.Context, name string) (bool, error) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	if !ok {
+		return false, fmt.Errorf("advisory lock %q requires a transaction", name)
+	}
+
+	var locked bool
+	key := tenant.ID(ctx) + "/" + name
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext($1))`, key).Scan(&locked); err != nil {
+		return false, fmt.Errorf("try advisory lock %q: %w", name, err)
+	}
+
+	return locked, nil
+}
+
 type txKey struct{}
 
 type querier interface {
@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
 )
 
 type PostgresPRRepository struct {
@@ -28,18 +29,21 @@ func (r *PostgresPRRepository) Create(ctx context.Context, pr *domain.PullReques
 	q := getQuerier(ctx, r.pool)
 
 	query := `
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, merged_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, merged_at, repository, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)
+		RETURNING version
 	`
 
-	_, err := q.Exec(ctx, query,
+	err := q.QueryRow(ctx, query,
 		pr.PullRequestID,
 		pr.PullRequestName,
 		pr.AuthorID,
 		pr.Status,
 		pr.CreatedAt,
 		pr.MergedAt,
-	)
+		pr.Repository,
+		tenant.ID(ctx),
+	).Scan(&pr.Version)
 	if err != nil {
 		if isDuplicateKeyError(err) {
 			return domain.ErrPRExists
@@ -51,22 +55,35 @@ func (r *PostgresPRRepository) Create(ctx context.Context, pr *domain.PullReques
 }
 
 func (r *PostgresPRRepository) GetByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return r.getByID(ctx, prID, "")
+}
+
+// GetByIDForUpdate reads the pull request like GetByID and locks its row until the
+// transaction of ctx ends, so that concurrent changes to it are applied one at a time.
+func (r *PostgresPRRepository) GetByIDForUpdate(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return r.getByID(ctx, prID, "FOR UPDATE")
+}
+
+func (r *PostgresPRRepository) getByID(ctx context.Context, prID, lock string) (*domain.PullRequest, error) {
 	q := getQuerier(ctx, r.pool)
 
 	prQuery := `
-		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at,
+		       COALESCE(repository, ''), version
 		FROM pull_requests
-		WHERE pull_request_id = $1
-	`
+		WHERE pull_request_id = $1 AND tenant_id = $2
+	` + lock
 
 	var pr domain.PullRequest
-	err := q.QueryRow(ctx, prQuery, prID).Scan(
+	err := q.QueryRow(ctx, prQuery, prID, tenant.ID(ctx)).Scan(
 		&pr.PullRequestID,
 		&pr.PullRequestName,
 		&pr.AuthorID,
 		&pr.Status,
 		&pr.CreatedAt,
 		&pr.MergedAt,
+		&pr.Repository,
+		&pr.Version,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -78,11 +95,11 @@ func (r *PostgresPRRepository) GetByID(ctx context.Context, prID string) (*domai
 	reviewersQuery := `
 		SELECT user_id
 		FROM pr_reviewers
-		WHERE pr_id = $1
+		WHERE pr_id = $1 AND tenant_id = $2
 		ORDER BY assigned_at
 	`
 
-	rows, err := q.Query(ctx, reviewersQuery, prID)
+	rows, err := q.Query(ctx, reviewersQuery, prID, tenant.ID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("query reviewers: %w", err)
 	}
@@ -109,10 +126,10 @@ func (r *PostgresPRRepository) GetByID(ctx context.Context, prID string) (*domai
 func (r *PostgresPRRepository) Exists(ctx context.Context, prID string) (bool, error) {
 	q := getQuerier(ctx, r.pool)
 
-	query := `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1 AND tenant_id = $2)`
 
 	var exists bool
-	err := q.QueryRow(ctx, query, prID).Scan(&exists)
+	err := q.QueryRow(ctx, query, prID, tenant.ID(ctx)).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("check PR existence: %w", err)
 	}
@@ -126,10 +143,10 @@ func (r *PostgresPRRepository) UpdateStatus(ctx context.Context, prID string, st
 	query := `
 		UPDATE pull_requests
 		SET status = $2, merged_at = $3
-		WHERE pull_request_id = $1
+		WHERE pull_request_id = $1 AND tenant_id = $4
 	`
 
-	result, err := q.Exec(ctx, query, prID, status, mergedAt)
+	result, err := q.Exec(ctx, query, prID, status, mergedAt, tenant.ID(ctx))
 	if err != nil {
 		return fmt.Errorf("update PR status: %w", err)
 	}
@@ -141,11 +158,36 @@ func (r *PostgresPRRepository) UpdateStatus(ctx context.Context, prID string, st
 	return nil
 }
 
+// BumpVersion increments the version of the pull request and returns the new one.
+func (r *PostgresPRRepository) BumpVersion(ctx context.Context, prID string) (int64, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		UPDATE pull_requests
+		SET version = version + 1
+		WHERE pull_request_id = $1 AND tenant_id = $2
+		RETURNING version
+	`
+
+	var version int64
+	err := q.QueryRow(ctx, query, prID, tenant.ID(ctx)).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrPRNotFound
+		}
+		return 0, fmt.Errorf("bump PR version: %w", err)
+	}
+
+	return version, nil
+}
+
 func (r *PostgresPRRepository) AssignReviewers(ctx context.Context, prID string, userIDs []string) error {
 	q := getQuerier(ctx, r.pool)
 
-	deleteQuery := `DELETE FROM pr_reviewers WHERE pr_id = $1`
-	_, err := q.Exec(ctx, deleteQuery, prID)
+	tenantID := tenant.ID(ctx)
+
+	deleteQuery := `DELETE FROM pr_reviewers WHERE pr_id = $1 AND tenant_id = $2`
+	_, err := q.Exec(ctx, deleteQuery, prID, tenantID)
 	if err != nil {
 		return fmt.Errorf("delete existing reviewers: %w", err)
 	}
@@ -155,13 +197,13 @@ func (r *PostgresPRRepository) AssignReviewers(ctx context.Context, prID string,
 	}
 
 	insertQuery := `
-		INSERT INTO pr_reviewers (pr_id, user_id, assigned_at)
-		VALUES ($1, $2, $3)
+		INSERT INTO pr_reviewers (pr_id, user_id, assigned_at, tenant_id)
+		VALUES ($1, $2, $3, $4)
 	`
 
 	now := time.Now()
 	for _, userID := range userIDs {
-		_, err := q.Exec(ctx, insertQuery, prID, userID, now)
+		_, err := q.Exec(ctx, insertQuery, prID, userID, now, tenantID)
 		if err != nil {
 			return fmt.Errorf("insert reviewer %s: %w", userID, err)
 		}
@@ -176,10 +218,10 @@ func (r *PostgresPRRepository) ReplaceReviewer(ctx context.Context, prID, oldUse
 	query := `
 		UPDATE pr_reviewers
 		SET user_id = $3, assigned_at = $4
-		WHERE pr_id = $1 AND user_id = $2
+		WHERE pr_id = $1 AND user_id = $2 AND tenant_id = $5
 	`
 
-	result, err := q.Exec(ctx, query, prID, oldUserID, newUserID, time.Now())
+	result, err := q.Exec(ctx, query, prID, oldUserID, newUserID, time.Now(), tenant.ID(ctx))
 	if err != nil {
 		return fmt.Errorf("replace reviewer: %w", err)
 	}
@@ -191,18 +233,54 @@ func (r *PostgresPRRepository) ReplaceReviewer(ctx context.Context, prID, oldUse
 	return nil
 }
 
+func (r *PostgresPRRepository) AddReviewer(ctx context.Context, prID, userID string) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO pr_reviewers (pr_id, user_id, assigned_at, tenant_id)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := q.Exec(ctx, query, prID, userID, time.Now(), tenant.ID(ctx))
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return domain.ErrAlreadyAssigned
+		}
+		return fmt.Errorf("add reviewer: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresPRRepository) RemoveReviewer(ctx context.Context, prID, userID string) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `DELETE FROM pr_reviewers WHERE pr_id = $1 AND user_id = $2 AND tenant_id = $3`
+
+	result, err := q.Exec(ctx, query, prID, userID, tenant.ID(ctx))
+	if err != nil {
+		return fmt.Errorf("remove reviewer: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotAssigned
+	}
+
+	return nil
+}
+
 func (r *PostgresPRRepository) ListByReviewer(ctx context.Context, userID string) ([]*domain.PullRequest, error) {
 	q := getQuerier(ctx, r.pool)
 
 	query := `
 		SELECT DISTINCT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at
 		FROM pull_requests pr
-		INNER JOIN pr_reviewers rev ON pr.pull_request_id = rev.pr_id
-		WHERE rev.user_id = $1
+		INNER JOIN pr_reviewers rev ON rev.tenant_id = pr.tenant_id AND pr.pull_request_id = rev.pr_id
+		WHERE rev.user_id = $1 AND rev.tenant_id = $2
 		ORDER BY pr.created_at DESC
 	`
 
-	rows, err := q.Query(ctx, query, userID)
+	rows, err := q.Query(ctx, query, userID, tenant.ID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("query PRs by reviewer: %w", err)
 	}
@@ -231,18 +309,23 @@ func (r *PostgresPRRepository) ListByReviewer(ctx context.Context, userID string
 	return prs, nil
 }
 
-// GetReviewerStats returns how many times each user was assigned as reviewer
-func (r *PostgresPRRepository) GetReviewerStats(ctx context.Context) (map[string]int, error) {
+// GetReviewerStats returns how many times each user was assigned as reviewer, counting
+// assignments from the history so that replaced reviewers keep theirs. The team filter
+// applies to the reviewer, the time window to assigned_at.
+func (r *PostgresPRRepository) GetReviewerStats(ctx context.Context, filter domain.StatsFilter) (map[string]int, error) {
 	q := getQuerier(ctx, r.pool)
 
-	query := `
-		SELECT user_id, COUNT(*) as assignments_count
-		FROM pr_reviewers
-		GROUP BY user_id
+	conds, args := statsConditions(ctx, filter, "a.tenant_id", "u.team_name", "a.assigned_at", nil)
+	query := fmt.Sprintf(`
+		SELECT a.reviewer_id, COUNT(*) as assignments_count
+		FROM reviewer_assignments a
+		INNER JOIN users u ON u.tenant_id = a.tenant_id AND u.user_id = a.reviewer_id
+		WHERE %s
+		GROUP BY a.reviewer_id
 		ORDER BY assignments_count DESC
-	`
+	`, conds)
 
-	rows, err := q.Query(ctx, query)
+	rows, err := q.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query reviewer stats: %w", err)
 	}
@@ -265,16 +348,21 @@ func (r *PostgresPRRepository) GetReviewerStats(ctx context.Context) (map[string
 	return stats, nil
 }
 
-func (r *PostgresPRRepository) GetPRStats(ctx context.Context) (map[string]int, error) {
+// GetPRStats counts pull requests by status. The team filter applies to the
+// author, the time window to created_at.
+func (r *PostgresPRRepository) GetPRStats(ctx context.Context, filter domain.StatsFilter) (map[string]int, error) {
 	q := getQuerier(ctx, r.pool)
 
-	query := `
-		SELECT status, COUNT(*) as count
-		FROM pull_requests
-		GROUP BY status
-	`
+	conds, args := statsConditions(ctx, filter, "pr.tenant_id", "u.team_name", "pr.created_at", nil)
+	query := fmt.Sprintf(`
+		SELECT pr.status, COUNT(*) as count
+		FROM pull_requests pr
+		INNER JOIN users u ON u.tenant_id = pr.tenant_id AND u.user_id = pr.author_id
+		WHERE %s
+		GROUP BY pr.status
+	`, conds)
 
-	rows, err := q.Query(ctx, query)
+	rows, err := q.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query PR stats: %w", err)
 	}
@@ -297,22 +385,28 @@ func (r *PostgresPRRepository) GetPRStats(ctx context.Context) (map[string]int,
 	return stats, nil
 }
 
+// GetOpenPRsByReviewers returns the open pull requests any of the users reviews and,
+// like GetByIDForUpdate, locks them for the rest of the transaction.
 func (r *PostgresPRRepository) GetOpenPRsByReviewers(ctx context.Context, userIDs []string) ([]*domain.PullRequest, error) {
 	if len(userIDs) == 0 {
 		return []*domain.PullRequest{}, nil
 	}
 
 	q := getQuerier(ctx, r.pool)
+	tenantID := tenant.ID(ctx)
 
 	query := `
-		SELECT DISTINCT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at,
+		       pr.version
 		FROM pull_requests pr
-		INNER JOIN pr_reviewers rev ON pr.pull_request_id = rev.pr_id
-		WHERE pr.status = 'OPEN' AND rev.user_id = ANY($1)
-		ORDER BY pr.created_at DESC
+		WHERE pr.status = 'OPEN' AND pr.tenant_id = $2 AND pr.pull_request_id IN (
+			SELECT pr_id FROM pr_reviewers WHERE user_id = ANY($1) AND tenant_id = $2
+		)
+		ORDER BY pr.created_at DESC, pr.pull_request_id
+		FOR UPDATE
 	`
 
-	rows, err := q.Query(ctx, query, userIDs)
+	rows, err := q.Query(ctx, query, userIDs, tenantID)
 	if err != nil {
 		return nil, fmt.Errorf("query open PRs by reviewers: %w", err)
 	}
@@ -328,6 +422,7 @@ func (r *PostgresPRRepository) GetOpenPRsByReviewers(ctx context.Context, userID
 			&pr.Status,
 			&pr.CreatedAt,
 			&pr.MergedAt,
+			&pr.Version,
 		); err != nil {
 			return nil, fmt.Errorf("scan pull request: %w", err)
 		}
@@ -342,11 +437,11 @@ func (r *PostgresPRRepository) GetOpenPRsByReviewers(ctx context.Context, userID
 		reviewersQuery := `
 			SELECT user_id
 			FROM pr_reviewers
-			WHERE pr_id = $1
+			WHERE pr_id = $1 AND tenant_id = $2
 			ORDER BY assigned_at
 		`
 
-		reviewerRows, err := q.Query(ctx, reviewersQuery, pr.PullRequestID)
+		reviewerRows, err := q.Query(ctx, reviewersQuery, pr.PullRequestID, tenantID)
 		if err != nil {
 			return nil, fmt.Errorf("query reviewers for PR %s: %w", pr.PullRequestID, err)
 		}
@@ -370,4 +465,4 @@ func (r *PostgresPRRepository) GetOpenPRsByReviewers(ctx context.Context, userID
 	}
 
 	return prs, nil
-}
\ No newline at end of file
+}
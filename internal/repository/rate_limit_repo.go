@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresRateLimitRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRateLimitRepository(pool *pgxpool.Pool) RateLimitRepository {
+	return &PostgresRateLimitRepository{pool: pool}
+}
+
+// Take refills and takes from the bucket in a single statement, so that concurrent
+// requests of all replicas are serialised by the row lock. The database clock is used
+// so that replicas with skewed clocks agree.
+func (r *PostgresRateLimitRepository) Take(ctx context.Context, key string, capacity, perSecond float64) (float64, bool, error) {
+	q := getQuerier(ctx, r.pool)
+
+	// refilled is the content of the bucket before this request: what was left, plus
+	// what has dripped in since, up to capacity.
+	const refilled = `LEAST($2::float8, b.tokens +
+		GREATEST(EXTRACT(EPOCH FROM EXCLUDED.updated_at - b.updated_at)::float8, 0) * $3::float8)`
+
+	query := `
+		INSERT INTO rate_limit_buckets AS b (bucket_key, tokens, allowed, updated_at)
+		VALUES ($1, $2::float8 - 1, TRUE, clock_timestamp()::timestamp)
+		ON CONFLICT (bucket_key) DO UPDATE SET
+			tokens = ` + refilled + ` - CASE WHEN ` + refilled + ` >= 1 THEN 1 ELSE 0 END,
+			allowed = ` + refilled + ` >= 1,
+			updated_at = GREATEST(b.updated_at, EXCLUDED.updated_at)
+		RETURNING tokens, allowed
+	`
+
+	var tokens float64
+	var allowed bool
+	if err := q.QueryRow(ctx, query, key, capacity, perSecond).Scan(&tokens, &allowed); err != nil {
+		return 0, false, fmt.Errorf("take rate limit token: %w", err)
+	}
+
+	return tokens, allowed, nil
+}
+
+// DeleteIdle removes buckets unused for longer than idle.
+func (r *PostgresRateLimitRepository) DeleteIdle(ctx context.Context, idle time.Duration) (int, error) {
+	q := getQuerier(ctx, r.pool)
+
+	tag, err := q.Exec(ctx,
+		`DELETE FROM rate_limit_buckets WHERE updated_at < clock_timestamp()::timestamp - make_interval(secs => $1)`,
+		idle.Seconds(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delete idle rate limit buckets: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+type PostgresReminderRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewReminderRepository(pool *pgxpool.Pool) ReminderRepository {
+	return &PostgresReminderRepository{pool: pool}
+}
+
+// GetPreferences returns the default preferences when the user has never set them.
+func (r *PostgresReminderRepository) GetPreferences(ctx context.Context, userID string) (*domain.ReminderPreferences, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		SELECT user_id, enabled, frequency_minutes, quiet_start_minute, quiet_end_minute, channel
+		FROM reminder_preferences
+		WHERE user_id = $1 AND tenant_id = $2
+	`
+
+	var p domain.ReminderPreferences
+	var frequencyMinutes int
+	err := q.QueryRow(ctx, query, userID, tenant.ID(ctx)).Scan(
+		&p.UserID,
+		&p.Enabled,
+		&frequencyMinutes,
+		&p.QuietStart,
+		&p.QuietEnd,
+		&p.Channel,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.DefaultReminderPreferences(userID), nil
+		}
+		return nil, fmt.Errorf("query reminder preferences: %w", err)
+	}
+	p.Frequency = time.Duration(frequencyMinutes) * time.Minute
+
+	return &p, nil
+}
+
+func (r *PostgresReminderRepository) UpsertPreferences(ctx context.Context, prefs *domain.ReminderPreferences) error {
+	if err := prefs.Validate(); err != nil {
+		return err
+	}
+
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO reminder_preferences (user_id, enabled, frequency_minutes, quiet_start_minute, quiet_end_minute, channel, updated_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), $7)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			frequency_minutes = EXCLUDED.frequency_minutes,
+			quiet_start_minute = EXCLUDED.quiet_start_minute,
+			quiet_end_minute = EXCLUDED.quiet_end_minute,
+			channel = EXCLUDED.channel,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := q.Exec(ctx, query,
+		prefs.UserID,
+		prefs.Enabled,
+		int(prefs.Frequency/time.Minute),
+		prefs.QuietStart,
+		prefs.QuietEnd,
+		prefs.Channel,
+		tenant.ID(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("upsert reminder preferences: %w", err)
+	}
+
+	return nil
+}
+
+// ListRecipients returns active users that review at least one open pull request.
+func (r *PostgresReminderRepository) ListRecipients(ctx context.Context) ([]*domain.User, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		SELECT DISTINCT u.user_id, u.username, u.team_name, u.is_active, u.slack_member_id, u.email, u.created_at
+		FROM users u
+		INNER JOIN pr_reviewers rev ON rev.tenant_id = u.tenant_id AND rev.user_id = u.user_id
+		INNER JOIN pull_requests pr ON pr.tenant_id = rev.tenant_id AND pr.pull_request_id = rev.pr_id
+		WHERE u.tenant_id = $1 AND u.is_active = true AND pr.status = 'OPEN'
+		ORDER BY u.user_id
+	`
+
+	rows, err := q.Query(ctx, query, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query reminder recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(
+			&user.UserID,
+			&user.Username,
+			&user.TeamName,
+			&user.IsActive,
+			&user.SlackMemberID,
+			&user.Email,
+			&user.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, &user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate users: %w", err)
+	}
+
+	return users, nil
+}
+
+// LastReminded maps pull request IDs to the time the user was last reminded about them.
+func (r *PostgresReminderRepository) LastReminded(ctx context.Context, userID string) (map[string]time.Time, error) {
+	q := getQuerier(ctx, r.pool)
+
+	rows, err := q.Query(ctx, `SELECT pr_id, sent_at FROM reminder_log WHERE user_id = $1 AND tenant_id = $2`, userID, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query reminder log: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]time.Time)
+	for rows.Next() {
+		var prID string
+		var sentAt time.Time
+		if err := rows.Scan(&prID, &sentAt); err != nil {
+			return nil, fmt.Errorf("scan reminder log: %w", err)
+		}
+		result[prID] = sentAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reminder log: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *PostgresReminderRepository) RecordReminders(ctx context.Context, userID string, prIDs []string, sentAt time.Time) error {
+	if len(prIDs) == 0 {
+		return nil
+	}
+
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO reminder_log (tenant_id, user_id, pr_id, sent_at)
+		SELECT $4, $1, unnest($2::varchar[]), $3
+		ON CONFLICT (tenant_id, user_id, pr_id) DO UPDATE SET sent_at = EXCLUDED.sent_at
+	`
+
+	if _, err := q.Exec(ctx, query, userID, prIDs, sentAt, tenant.ID(ctx)); err != nil {
+		return fmt.Errorf("record reminders: %w", err)
+	}
+
+	return nil
+}
+
+// ForgetReminders removes the reminders recorded at sentAt, for a digest that could not
+// be delivered.
+func (r *PostgresReminderRepository) ForgetReminders(ctx context.Context, userID string, prIDs []string, sentAt time.Time) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		DELETE FROM reminder_log
+		WHERE tenant_id = $4 AND user_id = $1 AND pr_id = ANY($2) AND sent_at = $3
+	`
+
+	if _, err := q.Exec(ctx, query, userID, prIDs, sentAt, tenant.ID(ctx)); err != nil {
+		return fmt.Errorf("forget reminders: %w", err)
+	}
+
+	return nil
+}
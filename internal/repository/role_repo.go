@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+type PostgresRoleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRoleRepository(pool *pgxpool.Pool) RoleRepository {
+	return &PostgresRoleRepository{pool: pool}
+}
+
+func (r *PostgresRoleRepository) ListByUser(ctx context.Context, userID string) ([]*domain.RoleAssignment, error) {
+	q := getQuerier(ctx, r.pool)
+
+	rows, err := q.Query(ctx,
+		`SELECT user_id, team_name, role FROM user_roles WHERE user_id = $1 AND tenant_id = $2 ORDER BY team_name`,
+		userID, tenant.ID(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query user roles: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRoleAssignments(rows)
+}
+
+// ListByTeam returns the team admins of a team, or every assignment when teamName is
+// empty.
+func (r *PostgresRoleRepository) ListByTeam(ctx context.Context, teamName string) ([]*domain.RoleAssignment, error) {
+	q := getQuerier(ctx, r.pool)
+
+	rows, err := q.Query(ctx, `
+		SELECT user_id, team_name, role FROM user_roles
+		WHERE tenant_id = $2 AND ($1 = '' OR team_name = $1)
+		ORDER BY team_name, user_id
+	`, teamName, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query team roles: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRoleAssignments(rows)
+}
+
+func (r *PostgresRoleRepository) Set(ctx context.Context, assignment *domain.RoleAssignment) error {
+	if err := assignment.Validate(); err != nil {
+		return err
+	}
+
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO user_roles (user_id, team_name, role, tenant_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, user_id, team_name) DO UPDATE SET role = EXCLUDED.role
+	`
+
+	if _, err := q.Exec(ctx, query, assignment.UserID, assignment.TeamName, assignment.Role, tenant.ID(ctx)); err != nil {
+		return fmt.Errorf("upsert user role: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRoleRepository) Delete(ctx context.Context, userID, teamName string) error {
+	q := getQuerier(ctx, r.pool)
+
+	tag, err := q.Exec(ctx,
+		`DELETE FROM user_roles WHERE user_id = $1 AND team_name = $2 AND tenant_id = $3`,
+		userID, teamName, tenant.ID(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("delete user role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+func scanRoleAssignments(rows pgx.Rows) ([]*domain.RoleAssignment, error) {
+	var result []*domain.RoleAssignment
+	for rows.Next() {
+		var a domain.RoleAssignment
+		if err := rows.Scan(&a.UserID, &a.TeamName, &a.Role); err != nil {
+			return nil, fmt.Errorf("scan user role: %w", err)
+		}
+		result = append(result, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate user roles: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+type PostgresRotationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRotationRepository(pool *pgxpool.Pool) RotationRepository {
+	return &PostgresRotationRepository{pool: pool}
+}
+
+func (r *PostgresRotationRepository) LockCursor(ctx context.Context, teamName string) (string, error) {
+	q := getQuerier(ctx, r.pool)
+
+	insertQuery := `
+		INSERT INTO team_rotation (team_name, tenant_id)
+		VALUES ($1, $2)
+		ON CONFLICT (tenant_id, team_name) DO NOTHING
+	`
+
+	if _, err := q.Exec(ctx, insertQuery, teamName, tenant.ID(ctx)); err != nil {
+		return "", fmt.Errorf("init team rotation: %w", err)
+	}
+
+	selectQuery := `
+		SELECT COALESCE(last_user_id, '')
+		FROM team_rotation
+		WHERE team_name = $1 AND tenant_id = $2
+		FOR UPDATE
+	`
+
+	var cursor string
+	if err := q.QueryRow(ctx, selectQuery, teamName, tenant.ID(ctx)).Scan(&cursor); err != nil {
+		return "", fmt.Errorf("lock team rotation: %w", err)
+	}
+
+	return cursor, nil
+}
+
+func (r *PostgresRotationRepository) SetCursor(ctx context.Context, teamName, userID string) error {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		UPDATE team_rotation
+		SET last_user_id = $2, updated_at = $3
+		WHERE team_name = $1 AND tenant_id = $4
+	`
+
+	if _, err := q.Exec(ctx, query, teamName, userID, time.Now(), tenant.ID(ctx)); err != nil {
+		return fmt.Errorf("update team rotation: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+type PostgresSettingsRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSettingsRepository(pool *pgxpool.Pool) SettingsRepository {
+	return &PostgresSettingsRepository{pool: pool}
+}
+
+// Get returns the default settings when the team has never configured them.
+func (r *PostgresSettingsRepository) Get(ctx context.Context, teamName string) (*domain.TeamSettings, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		SELECT team_name, review_sla_minutes, stale_timeout_minutes, timezone, work_day_start_minute, work_day_end_minute
+		FROM team_settings
+		WHERE team_name = $1 AND tenant_id = $2
+	`
+
+	settings, err := scanTeamSettings(q.QueryRow(ctx, query, teamName, tenant.ID(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.DefaultTeamSettings(teamName), nil
+		}
+		return nil, fmt.Errorf("query team settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *PostgresSettingsRepository) Upsert(ctx context.Context, settings *domain.TeamSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO team_settings (team_name, review_sla_minutes, stale_timeout_minutes, timezone, work_day_start_minute, work_day_end_minute, updated_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), $7)
+		ON CONFLICT (tenant_id, team_name) DO UPDATE SET
+			review_sla_minutes = EXCLUDED.review_sla_minutes,
+			stale_timeout_minutes = EXCLUDED.stale_timeout_minutes,
+			timezone = EXCLUDED.timezone,
+			work_day_start_minute = EXCLUDED.work_day_start_minute,
+			work_day_end_minute = EXCLUDED.work_day_end_minute,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := q.Exec(ctx, query,
+		settings.TeamName,
+		int(settings.ReviewSLA/time.Minute),
+		int(settings.StaleTimeout/time.Minute),
+		settings.Timezone,
+		settings.WorkDayStart,
+		settings.WorkDayEnd,
+		tenant.ID(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("upsert team settings: %w", err)
+	}
+
+	return nil
+}
+
+// ListWithSLA returns settings of the teams that have a review SLA configured.
+func (r *PostgresSettingsRepository) ListWithSLA(ctx context.Context) ([]*domain.TeamSettings, error) {
+	return r.list(ctx, "review_sla_minutes > 0")
+}
+
+// ListWithStaleTimeout returns settings of the teams that reassign stale reviews.
+func (r *PostgresSettingsRepository) ListWithStaleTimeout(ctx context.Context) ([]*domain.TeamSettings, error) {
+	return r.list(ctx, "stale_timeout_minutes > 0")
+}
+
+func (r *PostgresSettingsRepository) list(ctx context.Context, condition string) ([]*domain.TeamSettings, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		SELECT team_name, review_sla_minutes, stale_timeout_minutes, timezone, work_day_start_minute, work_day_end_minute
+		FROM team_settings
+		WHERE tenant_id = $1 AND ` + condition + `
+		ORDER BY team_name
+	`
+
+	rows, err := q.Query(ctx, query, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query team settings: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*domain.TeamSettings
+	for rows.Next() {
+		settings, err := scanTeamSettings(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan team settings: %w", err)
+		}
+		result = append(result, settings)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team settings: %w", err)
+	}
+
+	return result, nil
+}
+
+func scanTeamSettings(row pgx.Row) (*domain.TeamSettings, error) {
+	var s domain.TeamSettings
+	var slaMinutes, staleMinutes int
+	if err := row.Scan(&s.TeamName, &slaMinutes, &staleMinutes, &s.Timezone, &s.WorkDayStart, &s.WorkDayEnd); err != nil {
+		return nil, err
+	}
+	s.ReviewSLA = time.Duration(slaMinutes) * time.Minute
+	s.StaleTimeout = time.Duration(staleMinutes) * time.Minute
+	return &s, nil
+}
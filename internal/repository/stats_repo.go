@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+type PostgresStatsRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewStatsRepository(pool *pgxpool.Pool) StatsRepository {
+	return &PostgresStatsRepository{pool: pool}
+}
+
+// ListActivityChanges returns is_active history of the team's members, ordered by user
+// and time, up to the given moment.
+func (r *PostgresStatsRepository) ListActivityChanges(ctx context.Context, teamName string, before time.Time) ([]domain.ActivityChange, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		SELECT l.user_id, l.is_active, l.changed_at
+		FROM user_activity_log l
+		INNER JOIN users u ON u.tenant_id = l.tenant_id AND u.user_id = l.user_id
+		WHERE u.team_name = $1 AND l.changed_at < $2 AND l.tenant_id = $3
+		ORDER BY l.user_id, l.changed_at, l.id
+	`
+
+	rows, err := q.Query(ctx, query, teamName, before, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query activity changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []domain.ActivityChange
+	for rows.Next() {
+		var c domain.ActivityChange
+		if err := rows.Scan(&c.UserID, &c.IsActive, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scan activity change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate activity changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+func (r *PostgresStatsRepository) CountAssignmentsByTeam(ctx context.Context, teamName string, from, to time.Time) (map[string]int, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		SELECT a.reviewer_id, COUNT(*)
+		FROM reviewer_assignments a
+		INNER JOIN users u ON u.tenant_id = a.tenant_id AND u.user_id = a.reviewer_id
+		WHERE u.team_name = $1 AND a.assigned_at >= $2 AND a.assigned_at < $3 AND a.tenant_id = $4
+		GROUP BY a.reviewer_id
+	`
+
+	rows, err := q.Query(ctx, query, teamName, from, to, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query team assignments: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var userID string
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("scan team assignment: %w", err)
+		}
+		counts[userID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team assignments: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetReviewerSeries buckets reviewer assignments from the history by filter.GroupBy.
+func (r *PostgresStatsRepository) GetReviewerSeries(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerStatPoint, error) {
+	q := getQuerier(ctx, r.pool)
+
+	conds, args := statsConditions(ctx, filter, "a.tenant_id", "u.team_name", "a.assigned_at", []any{string(filter.GroupBy)})
+	query := fmt.Sprintf(`
+		SELECT date_trunc($1, a.assigned_at) AS period, a.reviewer_id, COUNT(*)
+		FROM reviewer_assignments a
+		INNER JOIN users u ON u.tenant_id = a.tenant_id AND u.user_id = a.reviewer_id
+		WHERE %s
+		GROUP BY period, a.reviewer_id
+		ORDER BY period, a.reviewer_id
+	`, conds)
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query reviewer series: %w", err)
+	}
+	defer rows.Close()
+
+	var points []domain.ReviewerStatPoint
+	for rows.Next() {
+		var p domain.ReviewerStatPoint
+		if err := rows.Scan(&p.Period, &p.UserID, &p.Assignments); err != nil {
+			return nil, fmt.Errorf("scan reviewer series point: %w", err)
+		}
+		points = append(points, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reviewer series: %w", err)
+	}
+
+	return points, nil
+}
+
+// GetPRSeries buckets created pull requests by created_at and merged ones by merged_at.
+func (r *PostgresStatsRepository) GetPRSeries(ctx context.Context, filter domain.StatsFilter) ([]domain.PRStatPoint, error) {
+	q := getQuerier(ctx, r.pool)
+
+	createdConds, args := statsConditions(ctx, filter, "pr.tenant_id", "u.team_name", "pr.created_at", []any{string(filter.GroupBy)})
+	mergedConds, args := statsConditions(ctx, filter, "pr.tenant_id", "u.team_name", "pr.merged_at", args)
+	query := fmt.Sprintf(`
+		WITH created AS (
+			SELECT date_trunc($1, pr.created_at) AS period, COUNT(*) AS cnt
+			FROM pull_requests pr
+			INNER JOIN users u ON u.tenant_id = pr.tenant_id AND u.user_id = pr.author_id
+			WHERE %s
+			GROUP BY period
+		),
+		merged AS (
+			SELECT date_trunc($1, pr.merged_at) AS period, COUNT(*) AS cnt
+			FROM pull_requests pr
+			INNER JOIN users u ON u.tenant_id = pr.tenant_id AND u.user_id = pr.author_id
+			WHERE pr.merged_at IS NOT NULL AND %s
+			GROUP BY period
+		)
+		SELECT COALESCE(c.period, m.period) AS period,
+		       COALESCE(c.cnt, 0),
+		       COALESCE(m.cnt, 0)
+		FROM created c
+		FULL OUTER JOIN merged m ON m.period = c.period
+		ORDER BY period
+	`, createdConds, mergedConds)
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query PR series: %w", err)
+	}
+	defer rows.Close()
+
+	var points []domain.PRStatPoint
+	for rows.Next() {
+		var p domain.PRStatPoint
+		if err := rows.Scan(&p.Period, &p.Created, &p.Merged); err != nil {
+			return nil, fmt.Errorf("scan PR series point: %w", err)
+		}
+		points = append(points, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate PR series: %w", err)
+	}
+
+	return points, nil
+}
+
+// ListPRCycleTimes rebuilds the milestones of pull requests created within the filter
+// window. The team filter applies to the author.
+func (r *PostgresStatsRepository) ListPRCycleTimes(ctx context.Context, filter domain.StatsFilter, repository string) ([]*domain.PRCycleTime, error) {
+	q := getQuerier(ctx, r.pool)
+
+	conds, args := statsConditions(ctx, filter, "pr.tenant_id", "u.team_name", "pr.created_at", nil)
+	if repository != "" {
+		args = append(args, repository)
+		conds += fmt.Sprintf(" AND pr.repository = $%d", len(args))
+	}
+	prQuery := fmt.Sprintf(`
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status,
+		       pr.created_at, pr.merged_at, COALESCE(pr.repository, ''), u.team_name
+		FROM pull_requests pr
+		INNER JOIN users u ON u.tenant_id = pr.tenant_id AND u.user_id = pr.author_id
+		WHERE %s
+		ORDER BY pr.created_at
+	`, conds)
+
+	rows, err := q.Query(ctx, prQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query cycle time PRs: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []*domain.PullRequest
+	teams := make(map[string]string)
+	for rows.Next() {
+		var pr domain.PullRequest
+		var teamName string
+		if err := rows.Scan(
+			&pr.PullRequestID,
+			&pr.PullRequestName,
+			&pr.AuthorID,
+			&pr.Status,
+			&pr.CreatedAt,
+			&pr.MergedAt,
+			&pr.Repository,
+			&teamName,
+		); err != nil {
+			return nil, fmt.Errorf("scan cycle time PR: %w", err)
+		}
+		prs = append(prs, &pr)
+		teams[pr.PullRequestID] = teamName
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate cycle time PRs: %w", err)
+	}
+
+	if len(prs) == 0 {
+		return []*domain.PRCycleTime{}, nil
+	}
+
+	prIDs := make([]string, len(prs))
+	for i, pr := range prs {
+		prIDs[i] = pr.PullRequestID
+	}
+
+	eventsQuery := `SELECT ` + prEventColumns + `
+		FROM pr_events
+		WHERE pr_id = ANY($1) AND tenant_id = $2
+		ORDER BY pr_id, event_id
+	`
+
+	eventRows, err := q.Query(ctx, eventsQuery, prIDs, tenant.ID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("query cycle time events: %w", err)
+	}
+	defer eventRows.Close()
+
+	prEvents, err := scanPREvents(eventRows)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(map[string][]*domain.PREvent)
+	for _, e := range prEvents {
+		events[e.PRID] = append(events[e.PRID], e)
+	}
+
+	cycles := make([]*domain.PRCycleTime, len(prs))
+	for i, pr := range prs {
+		cycles[i] = domain.NewPRCycleTime(pr, teams[pr.PullRequestID], events[pr.PullRequestID])
+	}
+
+	return cycles, nil
+}
+
+// ListAssignmentEvents returns the whole timeline of every pull request that had a
+// reviewer assigned within the filter window, ordered by pull request and event. The
+// team filter applies to the reviewer.
+func (r *PostgresStatsRepository) ListAssignmentEvents(ctx context.Context, filter domain.StatsFilter) ([]*domain.PREvent, error) {
+	q := getQuerier(ctx, r.pool)
+
+	conds, args := statsConditions(ctx, filter, "a.tenant_id", "u.team_name", "a.assigned_at", nil)
+	query := fmt.Sprintf(`SELECT `+prEventColumns+`
+		FROM pr_events
+		WHERE (tenant_id, pr_id) IN (
+			SELECT a.tenant_id, a.pr_id
+			FROM reviewer_assignments a
+			INNER JOIN users u ON u.tenant_id = a.tenant_id AND u.user_id = a.reviewer_id
+			WHERE %s
+		)
+		ORDER BY pr_id, event_id
+	`, conds)
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query assignment events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPREvents(rows)
+}
+
+// statsConditions renders the tenant of ctx and the team and time window of filter as a
+// WHERE clause over the given columns. Placeholders are numbered after args, which the
+// returned slice extends.
+func statsConditions(ctx context.Context, filter domain.StatsFilter, tenantColumn, teamColumn, timeColumn string, args []any) (string, []any) {
+	args = append(args, tenant.ID(ctx))
+	conds := []string{fmt.Sprintf("%s = $%d", tenantColumn, len(args))}
+	if filter.TeamName != "" {
+		args = append(args, filter.TeamName)
+		conds = append(conds, fmt.Sprintf("%s = $%d", teamColumn, len(args)))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conds = append(conds, fmt.Sprintf("%s >= $%d", timeColumn, len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conds = append(conds, fmt.Sprintf("%s < $%d", timeColumn, len(args)))
+	}
+	return strings.Join(conds, " AND "), args
+}
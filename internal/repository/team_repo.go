@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
 )
 
 type PostgresTeamRepository struct {
@@ -27,11 +28,12 @@ func (r *PostgresTeamRepository) Create(ctx context.Context, team *domain.Team)
 	q := getQuerier(ctx, r.pool)
 
 	query := `
-		INSERT INTO teams (team_name, created_at)
-		VALUES ($1, $2)
+		INSERT INTO teams (tenant_id, team_name, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING version
 	`
 
-	_, err := q.Exec(ctx, query, team.TeamName, team.CreatedAt)
+	err := q.QueryRow(ctx, query, tenant.ID(ctx), team.TeamName, team.CreatedAt).Scan(&team.Version)
 	if err != nil {
 		if isDuplicateKeyError(err) {
 			return domain.ErrTeamExists
@@ -43,12 +45,22 @@ func (r *PostgresTeamRepository) Create(ctx context.Context, team *domain.Team)
 }
 
 func (r *PostgresTeamRepository) GetByName(ctx context.Context, teamName string) (*domain.Team, error) {
+	return r.getByName(ctx, teamName, "")
+}
+
+// GetByNameForUpdate reads the team like GetByName and locks its row until the
+// transaction of ctx ends.
+func (r *PostgresTeamRepository) GetByNameForUpdate(ctx context.Context, teamName string) (*domain.Team, error) {
+	return r.getByName(ctx, teamName, " FOR UPDATE")
+}
+
+func (r *PostgresTeamRepository) getByName(ctx context.Context, teamName, lock string) (*domain.Team, error) {
 	q := getQuerier(ctx, r.pool)
 
-	query := `SELECT team_name, created_at FROM teams WHERE team_name = $1`
+	query := `SELECT team_name, created_at, version FROM teams WHERE tenant_id = $1 AND team_name = $2` + lock
 
 	var team domain.Team
-	err := q.QueryRow(ctx, query, teamName).Scan(&team.TeamName, &team.CreatedAt)
+	err := q.QueryRow(ctx, query, tenant.ID(ctx), teamName).Scan(&team.TeamName, &team.CreatedAt, &team.Version)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrTeamNotFound
@@ -62,10 +74,10 @@ func (r *PostgresTeamRepository) GetByName(ctx context.Context, teamName string)
 func (r *PostgresTeamRepository) Exists(ctx context.Context, teamName string) (bool, error) {
 	q := getQuerier(ctx, r.pool)
 
-	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM teams WHERE tenant_id = $1 AND team_name = $2)`
 
 	var exists bool
-	err := q.QueryRow(ctx, query, teamName).Scan(&exists)
+	err := q.QueryRow(ctx, query, tenant.ID(ctx), teamName).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("check team existence: %w", err)
 	}
@@ -73,6 +85,24 @@ func (r *PostgresTeamRepository) Exists(ctx context.Context, teamName string) (b
 	return exists, nil
 }
 
+// BumpVersion increments the version of the team and returns the new one.
+func (r *PostgresTeamRepository) BumpVersion(ctx context.Context, teamName string) (int64, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `UPDATE teams SET version = version + 1 WHERE tenant_id = $1 AND team_name = $2 RETURNING version`
+
+	var version int64
+	err := q.QueryRow(ctx, query, tenant.ID(ctx), teamName).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrTeamNotFound
+		}
+		return 0, fmt.Errorf("bump team version: %w", err)
+	}
+
+	return version, nil
+}
+
 func isDuplicateKeyError(err error) bool {
 	var pgErr *pgconn.PgError
 	if errors.As(err, &pgErr) {
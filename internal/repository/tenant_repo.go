@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+type PostgresTenantRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTenantRepository(pool *pgxpool.Pool) TenantRepository {
+	return &PostgresTenantRepository{pool: pool}
+}
+
+func (r *PostgresTenantRepository) Create(ctx context.Context, t *domain.Tenant) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		INSERT INTO tenants (tenant_id, name, created_at)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := q.Exec(ctx, query, t.TenantID, t.Name, t.CreatedAt); err != nil {
+		if isDuplicateKeyError(err) {
+			return domain.ErrTenantExists
+		}
+		return fmt.Errorf("insert tenant: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresTenantRepository) GetByID(ctx context.Context, tenantID string) (*domain.Tenant, error) {
+	q := getQuerier(ctx, r.pool)
+
+	var t domain.Tenant
+	err := q.QueryRow(ctx,
+		`SELECT tenant_id, name, created_at FROM tenants WHERE tenant_id = $1`,
+		tenantID,
+	).Scan(&t.TenantID, &t.Name, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("query tenant: %w", err)
+	}
+
+	return &t, nil
+}
+
+func (r *PostgresTenantRepository) List(ctx context.Context) ([]*domain.Tenant, error) {
+	q := getQuerier(ctx, r.pool)
+
+	rows, err := q.Query(ctx, `SELECT tenant_id, name, created_at FROM tenants ORDER BY tenant_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []*domain.Tenant
+	for rows.Next() {
+		var t domain.Tenant
+		if err := rows.Scan(&t.TenantID, &t.Name, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan tenant: %w", err)
+		}
+		tenants = append(tenants, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tenants: %w", err)
+	}
+
+	return tenants, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
 )
 
 type PostgresUserRepository struct {
@@ -28,9 +29,9 @@ func (r *PostgresUserRepository) Upsert(ctx context.Context, user *domain.User)
 	q := getQuerier(ctx, r.pool)
 
 	query := `
-		INSERT INTO users (user_id, username, team_name, is_active, created_at)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (user_id) 
+		INSERT INTO users (user_id, username, team_name, is_active, created_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id, user_id) 
 		DO UPDATE SET
 			username = EXCLUDED.username,
 			team_name = EXCLUDED.team_name,
@@ -43,6 +44,7 @@ func (r *PostgresUserRepository) Upsert(ctx context.Context, user *domain.User)
 		user.TeamName,
 		user.IsActive,
 		user.CreatedAt,
+		tenant.ID(ctx),
 	)
 	if err != nil {
 		return fmt.Errorf("upsert user: %w", err)
@@ -55,17 +57,19 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, userID string) (*d
 	q := getQuerier(ctx, r.pool)
 
 	query := `
-		SELECT user_id, username, team_name, is_active, created_at
+		SELECT user_id, username, team_name, is_active, slack_member_id, email, created_at
 		FROM users
-		WHERE user_id = $1
+		WHERE user_id = $1 AND tenant_id = $2
 	`
 
 	var user domain.User
-	err := q.QueryRow(ctx, query, userID).Scan(
+	err := q.QueryRow(ctx, query, userID, tenant.ID(ctx)).Scan(
 		&user.UserID,
 		&user.Username,
 		&user.TeamName,
 		&user.IsActive,
+		&user.SlackMemberID,
+		&user.Email,
 		&user.CreatedAt,
 	)
 	if err != nil {
@@ -84,16 +88,18 @@ func (r *PostgresUserRepository) SetIsActive(ctx context.Context, userID string,
 	query := `
 		UPDATE users
 		SET is_active = $2
-		WHERE user_id = $1
-		RETURNING user_id, username, team_name, is_active, created_at
+		WHERE user_id = $1 AND tenant_id = $3
+		RETURNING user_id, username, team_name, is_active, slack_member_id, email, created_at
 	`
 
 	var user domain.User
-	err := q.QueryRow(ctx, query, userID, isActive).Scan(
+	err := q.QueryRow(ctx, query, userID, isActive, tenant.ID(ctx)).Scan(
 		&user.UserID,
 		&user.Username,
 		&user.TeamName,
 		&user.IsActive,
+		&user.SlackMemberID,
+		&user.Email,
 		&user.CreatedAt,
 	)
 	if err != nil {
@@ -106,17 +112,77 @@ func (r *PostgresUserRepository) SetIsActive(ctx context.Context, userID string,
 	return &user, nil
 }
 
+func (r *PostgresUserRepository) SetSlackMemberID(ctx context.Context, userID, memberID string) (*domain.User, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		UPDATE users
+		SET slack_member_id = $2
+		WHERE user_id = $1 AND tenant_id = $3
+		RETURNING user_id, username, team_name, is_active, slack_member_id, email, created_at
+	`
+
+	var user domain.User
+	err := q.QueryRow(ctx, query, userID, memberID, tenant.ID(ctx)).Scan(
+		&user.UserID,
+		&user.Username,
+		&user.TeamName,
+		&user.IsActive,
+		&user.SlackMemberID,
+		&user.Email,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("update user slack_member_id: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (r *PostgresUserRepository) SetEmail(ctx context.Context, userID, email string) (*domain.User, error) {
+	q := getQuerier(ctx, r.pool)
+
+	query := `
+		UPDATE users
+		SET email = $2
+		WHERE user_id = $1 AND tenant_id = $3
+		RETURNING user_id, username, team_name, is_active, slack_member_id, email, created_at
+	`
+
+	var user domain.User
+	err := q.QueryRow(ctx, query, userID, email, tenant.ID(ctx)).Scan(
+		&user.UserID,
+		&user.Username,
+		&user.TeamName,
+		&user.IsActive,
+		&user.SlackMemberID,
+		&user.Email,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("update user email: %w", err)
+	}
+
+	return &user, nil
+}
+
 func (r *PostgresUserRepository) ListByTeam(ctx context.Context, teamName string) ([]*domain.User, error) {
 	q := getQuerier(ctx, r.pool)
 
 	query := `
-		SELECT user_id, username, team_name, is_active, created_at
+		SELECT user_id, username, team_name, is_active, slack_member_id, email, created_at
 		FROM users
-		WHERE team_name = $1
+		WHERE team_name = $1 AND tenant_id = $2
 		ORDER BY user_id
 	`
 
-	rows, err := q.Query(ctx, query, teamName)
+	rows, err := q.Query(ctx, query, teamName, tenant.ID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("query users by team: %w", err)
 	}
@@ -130,6 +196,8 @@ func (r *PostgresUserRepository) ListByTeam(ctx context.Context, teamName string
 			&user.Username,
 			&user.TeamName,
 			&user.IsActive,
+			&user.SlackMemberID,
+			&user.Email,
 			&user.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan user: %w", err)
@@ -151,19 +219,20 @@ func (r *PostgresUserRepository) ListActiveByTeamExcluding(
 ) ([]*domain.User, error) {
 	q := getQuerier(ctx, r.pool)
 	query := `
-		SELECT user_id, username, team_name, is_active, created_at
+		SELECT user_id, username, team_name, is_active, slack_member_id, email, created_at
 		FROM users
 		WHERE team_name = $1 
+		  AND tenant_id = $2
 		  AND is_active = true
 	`
 
-	args := []interface{}{teamName}
+	args := []interface{}{teamName, tenant.ID(ctx)}
 
 	if len(excludeUserIDs) > 0 {
 		placeholders := make([]string, len(excludeUserIDs))
 		for i, id := range excludeUserIDs {
 			args = append(args, id)
-			placeholders[i] = fmt.Sprintf("$%d", i+2)
+			placeholders[i] = fmt.Sprintf("$%d", i+3)
 		}
 		query += fmt.Sprintf(" AND user_id NOT IN (%s)", strings.Join(placeholders, ", "))
 	}
@@ -184,6 +253,8 @@ func (r *PostgresUserRepository) ListActiveByTeamExcluding(
 			&user.Username,
 			&user.TeamName,
 			&user.IsActive,
+			&user.SlackMemberID,
+			&user.Email,
 			&user.CreatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan user: %w", err)
@@ -208,10 +279,10 @@ func (r *PostgresUserRepository) DeactivateUsers(ctx context.Context, teamName s
 	query := `
 		UPDATE users
 		SET is_active = false
-		WHERE team_name = $1 AND user_id = ANY($2)
+		WHERE team_name = $1 AND user_id = ANY($2) AND tenant_id = $3
 	`
 
-	result, err := q.Exec(ctx, query, teamName, userIDs)
+	result, err := q.Exec(ctx, query, teamName, userIDs, tenant.ID(ctx))
 	if err != nil {
 		return 0, fmt.Errorf("deactivate users: %w", err)
 	}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is periodic background work. Run is called every Interval until the scheduler's
+// context is cancelled; an error is logged and the job keeps its schedule.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	jobs   []Job
+	logger *slog.Logger
+	wg     sync.WaitGroup
+}
+
+func New(logger *slog.Logger) *Scheduler {
+	return &Scheduler{logger: logger}
+}
+
+// Add registers a job. Jobs with a non-positive interval are disabled and skipped.
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		s.logger.Info("background job disabled", "job", job.Name)
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start runs every job in its own goroutine until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Wait blocks until all jobs have returned after the context passed to Start is cancelled.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	s.logger.Info("background job started", "job", job.Name, "interval", job.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("background job stopped", "job", job.Name)
+			return
+		case <-ticker.C:
+			start := time.Now()
+			if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error("background job failed", "job", job.Name, "error", err)
+				continue
+			}
+			s.logger.Debug("background job finished", "job", job.Name, "duration", time.Since(start))
+		}
+	}
+}
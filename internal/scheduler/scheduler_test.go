@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler_RunsJobsUntilCancelled(t *testing.T) {
+	s := New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var runs, failures atomic.Int32
+	s.Add(Job{
+		Name:     "count",
+		Interval: time.Millisecond,
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	})
+	s.Add(Job{
+		Name:     "failing",
+		Interval: time.Millisecond,
+		Run: func(ctx context.Context) error {
+			failures.Add(1)
+			return errors.New("boom")
+		},
+	})
+	s.Add(Job{
+		Name:     "disabled",
+		Interval: 0,
+		Run: func(ctx context.Context) error {
+			t.Error("disabled job should not run")
+			return nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+
+	deadline := time.Now().Add(time.Second)
+	for (runs.Load() < 3 || failures.Load() < 3) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	s.Wait()
+
+	if runs.Load() < 3 {
+		t.Errorf("expected job to run repeatedly, ran %d times", runs.Load())
+	}
+	if failures.Load() < 3 {
+		t.Errorf("a failing job should keep its schedule, ran %d times", failures.Load())
+	}
+
+	stopped := runs.Load()
+	time.Sleep(5 * time.Millisecond)
+	if runs.Load() != stopped {
+		t.Error("job kept running after Wait returned")
+	}
+}
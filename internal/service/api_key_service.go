@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+// apiKeyPrefix starts every issued key, which makes leaked keys easy to find by scanners.
+const apiKeyPrefix = "prs_"
+
+// BootstrapKeyID identifies the key configured with WithBootstrapKey.
+const BootstrapKeyID = "bootstrap"
+
+type APIKeyService interface {
+	CreateKey(ctx context.Context, name string, scopes []domain.Scope) (*domain.APIKey, string, error)
+	RotateKey(ctx context.Context, keyID string) (*domain.APIKey, string, error)
+	RevokeKey(ctx context.Context, keyID string) (*domain.APIKey, error)
+	ListKeys(ctx context.Context) ([]*domain.APIKey, error)
+	Authenticate(ctx context.Context, secret string) (*domain.APIKey, error)
+}
+
+type APIKeyServiceOption func(*apiKeyService)
+
+// WithBootstrapKey accepts secret as a key with every scope. It is meant for creating
+// the first keys and should be unset afterwards.
+func WithBootstrapKey(secret string) APIKeyServiceOption {
+	return func(s *apiKeyService) {
+		s.bootstrap = secret
+	}
+}
+
+type apiKeyService struct {
+	repos     *repository.Repositories
+	bootstrap string
+	now       func() time.Time
+}
+
+func NewAPIKeyService(repos *repository.Repositories, opts ...APIKeyServiceOption) APIKeyService {
+	s := &apiKeyService{
+		repos: repos,
+		now:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateKey returns the new key and its secret. The secret is not stored and cannot be
+// retrieved later.
+func (s *apiKeyService) CreateKey(ctx context.Context, name string, scopes []domain.Scope) (*domain.APIKey, string, error) {
+	keyID, err := randomToken(8, hex.EncodeToString)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := newAPIKeySecret(keyID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &domain.APIKey{
+		KeyID:     keyID,
+		TenantID:  tenant.ID(ctx),
+		Name:      name,
+		Scopes:    scopes,
+		CreatedAt: s.now().UTC().Truncate(time.Microsecond),
+	}
+	if err := s.repos.APIKey.Create(ctx, key, hashAPIKey(secret)); err != nil {
+		return nil, "", err
+	}
+
+	return key, secret, nil
+}
+
+// RotateKey issues a new secret for the key; the previous one stops working at once.
+func (s *apiKeyService) RotateKey(ctx context.Context, keyID string) (*domain.APIKey, string, error) {
+	key, _, err := s.repos.APIKey.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, "", err
+	}
+	if key.IsRevoked() {
+		return nil, "", domain.ErrAPIKeyRevoked
+	}
+
+	secret, err := newAPIKeySecret(keyID)
+	if err != nil {
+		return nil, "", err
+	}
+	key, err = s.repos.APIKey.Rotate(ctx, keyID, hashAPIKey(secret), s.now().UTC())
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, secret, nil
+}
+
+func (s *apiKeyService) RevokeKey(ctx context.Context, keyID string) (*domain.APIKey, error) {
+	return s.repos.APIKey.Revoke(ctx, keyID, s.now().UTC())
+}
+
+func (s *apiKeyService) ListKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	return s.repos.APIKey.List(ctx)
+}
+
+// Authenticate resolves a secret to its key and records the use. Keys of every tenant
+// are accepted; the caller acts for the tenant of the key. Unknown, malformed and
+// revoked keys all fail with ErrUnauthorized.
+func (s *apiKeyService) Authenticate(ctx context.Context, secret string) (*domain.APIKey, error) {
+	if s.bootstrap != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(s.bootstrap)) == 1 {
+		return &domain.APIKey{KeyID: BootstrapKeyID, Name: BootstrapKeyID, Scopes: domain.AllScopes}, nil
+	}
+
+	keyID, ok := parseAPIKeyID(secret)
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+
+	key, hash, err := s.repos.APIKey.Lookup(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(hashAPIKey(secret))) != 1 || key.IsRevoked() {
+		return nil, domain.ErrUnauthorized
+	}
+
+	if err := s.repos.APIKey.TouchLastUsed(ctx, keyID, s.now().UTC()); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// newAPIKeySecret builds a key of the form prs_<key id>_<random>, so that the key ID can
+// be looked up before the hash is compared.
+func newAPIKeySecret(keyID string) (string, error) {
+	random, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + keyID + "_" + random, nil
+}
+
+func parseAPIKeyID(secret string) (string, bool) {
+	rest, ok := strings.CutPrefix(secret, apiKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	keyID, _, ok := strings.Cut(rest, "_")
+	return keyID, ok && keyID != ""
+}
+
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
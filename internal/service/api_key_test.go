@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+type mockAPIKeyRepo struct {
+	keys    map[string]*domain.APIKey
+	hashes  map[string]string
+	touches int
+}
+
+func newMockAPIKeyRepo() *mockAPIKeyRepo {
+	return &mockAPIKeyRepo{
+		keys:   make(map[string]*domain.APIKey),
+		hashes: make(map[string]string),
+	}
+}
+
+func (m *mockAPIKeyRepo) Create(ctx context.Context, key *domain.APIKey, hash string) error {
+	m.keys[key.KeyID] = key
+	m.hashes[key.KeyID] = hash
+	return nil
+}
+
+func (m *mockAPIKeyRepo) GetByID(ctx context.Context, keyID string) (*domain.APIKey, string, error) {
+	key, hash, err := m.Lookup(ctx, keyID)
+	if err != nil || key.TenantID != tenant.ID(ctx) {
+		return nil, "", domain.ErrAPIKeyNotFound
+	}
+	return key, hash, nil
+}
+
+func (m *mockAPIKeyRepo) Lookup(ctx context.Context, keyID string) (*domain.APIKey, string, error) {
+	key, ok := m.keys[keyID]
+	if !ok {
+		return nil, "", domain.ErrAPIKeyNotFound
+	}
+	return key, m.hashes[keyID], nil
+}
+
+func (m *mockAPIKeyRepo) List(ctx context.Context) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	for _, key := range m.keys {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeyRepo) Rotate(ctx context.Context, keyID, hash string, at time.Time) (*domain.APIKey, error) {
+	key, ok := m.keys[keyID]
+	if !ok || key.IsRevoked() {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	m.hashes[keyID] = hash
+	key.RotatedAt = &at
+	return key, nil
+}
+
+func (m *mockAPIKeyRepo) Revoke(ctx context.Context, keyID string, at time.Time) (*domain.APIKey, error) {
+	key, ok := m.keys[keyID]
+	if !ok {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+	return key, nil
+}
+
+func (m *mockAPIKeyRepo) TouchLastUsed(ctx context.Context, keyID string, at time.Time) error {
+	m.touches++
+	m.keys[keyID].LastUsedAt = &at
+	return nil
+}
+
+func TestAPIKeyService_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockAPIKeyRepo()
+	svc := NewAPIKeyService(&repository.Repositories{APIKey: repo})
+
+	key, secret, err := svc.CreateKey(ctx, "ci", []domain.Scope{domain.ScopeWritePR})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.hashes[key.KeyID] == secret {
+		t.Fatal("secret must not be stored in plain text")
+	}
+
+	got, err := svc.Authenticate(ctx, secret)
+	if err != nil {
+		t.Fatalf("expected key to authenticate, got %v", err)
+	}
+	if got.KeyID != key.KeyID || got.LastUsedAt == nil {
+		t.Errorf("expected key %s with last use recorded, got %+v", key.KeyID, got)
+	}
+
+	_, rotated, err := svc.RotateKey(ctx, key.KeyID)
+	if err != nil {
+		t.Fatalf("unexpected rotate error: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, secret); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("expected old secret to be rejected after rotation, got %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, rotated); err != nil {
+		t.Errorf("expected rotated secret to authenticate, got %v", err)
+	}
+
+	if _, err := svc.RevokeKey(ctx, key.KeyID); err != nil {
+		t.Fatalf("unexpected revoke error: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, rotated); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("expected revoked key to be rejected, got %v", err)
+	}
+	if _, _, err := svc.RotateKey(ctx, key.KeyID); !errors.Is(err, domain.ErrAPIKeyRevoked) {
+		t.Errorf("expected ErrAPIKeyRevoked, got %v", err)
+	}
+}
+
+func TestAPIKeyService_AuthenticateRejectsUnknownKeys(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockAPIKeyRepo()
+	svc := NewAPIKeyService(&repository.Repositories{APIKey: repo}, WithBootstrapKey("bootstrap-secret"))
+
+	key, secret, err := svc.CreateKey(ctx, "ci", []domain.Scope{domain.ScopeRead})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, candidate := range []string{"", "prs_", "garbage", "prs_unknown_secret", secret + "x", "prs_" + key.KeyID + "_guess"} {
+		if _, err := svc.Authenticate(ctx, candidate); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("expected %q to be rejected, got %v", candidate, err)
+		}
+	}
+
+	bootstrap, err := svc.Authenticate(ctx, "bootstrap-secret")
+	if err != nil {
+		t.Fatalf("expected bootstrap key to authenticate, got %v", err)
+	}
+	if !domain.ScopesAllow(bootstrap.Scopes, domain.ScopeAdminTeams) {
+		t.Error("expected bootstrap key to have every scope")
+	}
+	if repo.touches != 0 {
+		t.Errorf("expected no last-use updates for rejected or bootstrap keys, got %d", repo.touches)
+	}
+}
+
+func TestAPIKeyService_KeysBelongToTheirTenant(t *testing.T) {
+	repo := newMockAPIKeyRepo()
+	svc := NewAPIKeyService(&repository.Repositories{APIKey: repo})
+	acme := tenant.WithID(context.Background(), "acme")
+
+	key, secret, err := svc.CreateKey(acme, "ci", []domain.Scope{domain.ScopeRead})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key.TenantID != "acme" {
+		t.Fatalf("expected the key to belong to acme, got %q", key.TenantID)
+	}
+
+	// Authentication happens before the tenant is known.
+	got, err := svc.Authenticate(context.Background(), secret)
+	if err != nil {
+		t.Fatalf("expected key to authenticate, got %v", err)
+	}
+	if got.TenantID != "acme" {
+		t.Errorf("expected the authenticated key to carry acme, got %q", got.TenantID)
+	}
+
+	if _, _, err := svc.RotateKey(context.Background(), key.KeyID); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("expected another tenant not to see the key, got %v", err)
+	}
+	if _, _, err := svc.RotateKey(acme, key.KeyID); err != nil {
+		t.Errorf("expected the owning tenant to rotate the key, got %v", err)
+	}
+}
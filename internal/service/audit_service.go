@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+)
+
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 1000
+)
+
+// AuditService reads the audit log. Entries are written by the decorators of package
+// audit, in the transaction of the change they describe.
+type AuditService interface {
+	ListEntries(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error)
+}
+
+type auditService struct {
+	repos *repository.Repositories
+}
+
+func NewAuditService(repos *repository.Repositories) AuditService {
+	return &auditService{repos: repos}
+}
+
+// ListEntries returns a page of matching entries; a zero limit means the default page
+// size and larger limits are capped.
+func (s *auditService) ListEntries(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = defaultAuditPageSize
+	case filter.Limit > maxAuditPageSize:
+		filter.Limit = maxAuditPageSize
+	}
+	return s.repos.Audit.List(ctx, filter)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+)
+
+type ConstraintService interface {
+	AddExclusion(ctx context.Context, teamName, userID, otherUserID string) (*domain.ExclusionPair, error)
+	RemoveExclusion(ctx context.Context, teamName, userID, otherUserID string) error
+	ListExclusions(ctx context.Context, teamName string) ([]*domain.ExclusionPair, error)
+	AddNeverAssign(ctx context.Context, authorID, reviewerID string) (*domain.NeverAssign, error)
+	RemoveNeverAssign(ctx context.Context, authorID, reviewerID string) error
+	ListNeverAssign(ctx context.Context, authorID string) ([]*domain.NeverAssign, error)
+}
+
+type constraintService struct {
+	repos *repository.Repositories
+}
+
+func NewConstraintService(repos *repository.Repositories) ConstraintService {
+	return &constraintService{repos: repos}
+}
+
+func (s *constraintService) AddExclusion(ctx context.Context, teamName, userID, otherUserID string) (*domain.ExclusionPair, error) {
+	pair := domain.NewExclusionPair(teamName, userID, otherUserID)
+
+	err := s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		if _, err := s.repos.Team.GetByNameForUpdate(txCtx, teamName); err != nil {
+			return err
+		}
+
+		for _, id := range []string{userID, otherUserID} {
+			user, err := s.repos.User.GetByID(txCtx, id)
+			if err != nil {
+				return err
+			}
+			if user.TeamName != teamName {
+				return domain.ErrNotTeamMember
+			}
+		}
+
+		if err := s.repos.Constraint.AddExclusion(txCtx, pair); err != nil {
+			return err
+		}
+		return s.checkTeamAssignable(txCtx, teamName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+func (s *constraintService) RemoveExclusion(ctx context.Context, teamName, userID, otherUserID string) error {
+	pair := domain.NewExclusionPair(teamName, userID, otherUserID)
+	return s.repos.Constraint.RemoveExclusion(ctx, pair)
+}
+
+func (s *constraintService) ListExclusions(ctx context.Context, teamName string) ([]*domain.ExclusionPair, error) {
+	if _, err := s.repos.Team.GetByName(ctx, teamName); err != nil {
+		return nil, err
+	}
+	return s.repos.Constraint.ListExclusionsByTeam(ctx, teamName)
+}
+
+func (s *constraintService) AddNeverAssign(ctx context.Context, authorID, reviewerID string) (*domain.NeverAssign, error) {
+	entry := &domain.NeverAssign{
+		AuthorID:   authorID,
+		ReviewerID: reviewerID,
+		CreatedAt:  time.Now(),
+	}
+
+	err := s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		author, err := s.repos.User.GetByID(txCtx, authorID)
+		if err != nil {
+			return err
+		}
+		if _, err := s.repos.Team.GetByNameForUpdate(txCtx, author.TeamName); err != nil {
+			return err
+		}
+		if _, err := s.repos.User.GetByID(txCtx, reviewerID); err != nil {
+			return err
+		}
+
+		if err := s.repos.Constraint.AddNeverAssign(txCtx, entry); err != nil {
+			return err
+		}
+		return s.checkTeamAssignable(txCtx, author.TeamName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (s *constraintService) RemoveNeverAssign(ctx context.Context, authorID, reviewerID string) error {
+	return s.repos.Constraint.RemoveNeverAssign(ctx, authorID, reviewerID)
+}
+
+func (s *constraintService) ListNeverAssign(ctx context.Context, authorID string) ([]*domain.NeverAssign, error) {
+	if _, err := s.repos.User.GetByID(ctx, authorID); err != nil {
+		return nil, err
+	}
+	return s.repos.Constraint.ListNeverAssignByAuthor(ctx, authorID)
+}
+
+// checkTeamAssignable runs inside the transaction that added a constraint so that
+// a constraint leaving some author without reviewers is rolled back. The caller locks
+// the team row first, so that constraints added at the same time are checked one after
+// the other and cannot together leave an author without reviewers.
+func (s *constraintService) checkTeamAssignable(ctx context.Context, teamName string) error {
+	members, err := s.repos.User.ListByTeam(ctx, teamName)
+	if err != nil {
+		return err
+	}
+
+	constraints, err := s.repos.Constraint.GetForTeam(ctx, teamName)
+	if err != nil {
+		return err
+	}
+
+	return constraints.CheckAssignable(members)
+}
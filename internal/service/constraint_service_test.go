@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+)
+
+type mockTeamRepo struct {
+	teams map[string]*domain.Team
+}
+
+func (m *mockTeamRepo) Create(ctx context.Context, team *domain.Team) error {
+	team.Version = 1
+	m.teams[team.TeamName] = team
+	return nil
+}
+
+func (m *mockTeamRepo) GetByName(ctx context.Context, teamName string) (*domain.Team, error) {
+	team, ok := m.teams[teamName]
+	if !ok {
+		return nil, domain.ErrTeamNotFound
+	}
+	return team, nil
+}
+
+func (m *mockTeamRepo) GetByNameForUpdate(ctx context.Context, teamName string) (*domain.Team, error) {
+	return m.GetByName(ctx, teamName)
+}
+
+func (m *mockTeamRepo) BumpVersion(ctx context.Context, teamName string) (int64, error) {
+	team, ok := m.teams[teamName]
+	if !ok {
+		return 0, domain.ErrTeamNotFound
+	}
+	team.Version++
+	return team.Version, nil
+}
+
+func (m *mockTeamRepo) Exists(ctx context.Context, teamName string) (bool, error) {
+	_, ok := m.teams[teamName]
+	return ok, nil
+}
+
+func newConstraintTestRepos() (*mockRepos, *repository.Repositories) {
+	mockRepos := newMockRepos()
+	for _, id := range []string{"u1", "u2", "u3"} {
+		mockRepos.userRepo.users[id] = &domain.User{
+			UserID:   id,
+			Username: id,
+			TeamName: "backend",
+			IsActive: true,
+		}
+	}
+
+	repos := &repository.Repositories{}
+	repos.Team = &mockTeamRepo{teams: map[string]*domain.Team{"backend": {TeamName: "backend"}}}
+	repos.User = mockRepos.userRepo
+	repos.PR = mockRepos.prRepo
+	repos.Constraint = mockRepos.constraintRepo
+	repos.Event = mockRepos.eventRepo
+	repos.Tx = mockRepos
+
+	return mockRepos, repos
+}
+
+func TestConstraintService_AddExclusion_Unassignable(t *testing.T) {
+	_, repos := newConstraintTestRepos()
+	service := NewConstraintService(repos)
+	ctx := context.Background()
+
+	if _, err := service.AddExclusion(ctx, "backend", "u1", "u2"); err != nil {
+		t.Fatalf("first exclusion failed: %v", err)
+	}
+
+	_, err := service.AddExclusion(ctx, "backend", "u1", "u3")
+	if err == nil {
+		t.Fatal("expected error for unassignable team, got nil")
+	}
+
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrCodeUnassignable {
+		t.Errorf("expected TEAM_UNASSIGNABLE, got %v", err)
+	}
+}
+
+func TestConstraintService_AddExclusion_NotTeamMember(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	mockRepos.userRepo.users["u9"] = &domain.User{
+		UserID:   "u9",
+		Username: "u9",
+		TeamName: "frontend",
+		IsActive: true,
+	}
+	service := NewConstraintService(repos)
+
+	_, err := service.AddExclusion(context.Background(), "backend", "u1", "u9")
+	if err != domain.ErrNotTeamMember {
+		t.Errorf("expected ErrNotTeamMember, got %v", err)
+	}
+}
+
+func TestPRService_CreatePR_RespectsConstraints(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	mockRepos.constraintRepo.pairs = []*domain.ExclusionPair{
+		domain.NewExclusionPair("backend", "u1", "u2"),
+	}
+	service := NewPRService(repos)
+
+	pr, err := service.CreatePR(context.Background(), "pr-1", "Test PR", "u1", "")
+	if err != nil {
+		t.Fatalf("CreatePR failed: %v", err)
+	}
+
+	if len(pr.AssignedReviewers) != 1 || pr.AssignedReviewers[0] != "u3" {
+		t.Errorf("expected only u3 as reviewer, got %v", pr.AssignedReviewers)
+	}
+}
+
+func TestPRService_ReassignReviewer_RespectsNeverAssign(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	mockRepos.userRepo.users["u4"] = &domain.User{
+		UserID:   "u4",
+		Username: "u4",
+		TeamName: "backend",
+		IsActive: true,
+	}
+	mockRepos.constraintRepo.neverAssign = []*domain.NeverAssign{
+		{AuthorID: "u1", ReviewerID: "u4"},
+	}
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Test",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+	service := NewPRService(repos)
+
+	_, _, err := service.ReassignReviewer(context.Background(), "pr-1", "u2")
+	if err != domain.ErrNoCandidate {
+		t.Errorf("expected ErrNoCandidate, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/auth"
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+func TestPRService_DeclineReview_ReplacesReviewer(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Test",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+	service := NewPRService(repos)
+
+	pr, replacedBy, err := service.DeclineReview(context.Background(), "pr-1", "u2", domain.DeclineReasonBusy, "on vacation")
+	if err != nil {
+		t.Fatalf("DeclineReview failed: %v", err)
+	}
+
+	if replacedBy != "u3" {
+		t.Errorf("expected replacement u3, got %q", replacedBy)
+	}
+	if pr.HasReviewer("u2") {
+		t.Error("declining reviewer should be removed")
+	}
+
+	events := mockRepos.eventRepo.events
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	if events[0].Type != domain.PREventReviewerDeclined || events[0].Reason != string(domain.DeclineReasonBusy) {
+		t.Errorf("unexpected event: %+v", events[0])
+	}
+}
+
+func TestPRService_DeclineReview_NoCandidateRemovesReviewer(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Test",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+	service := NewPRService(repos)
+
+	pr, replacedBy, err := service.DeclineReview(context.Background(), "pr-1", "u2", domain.DeclineReasonNoContext, "")
+	if err != nil {
+		t.Fatalf("DeclineReview failed: %v", err)
+	}
+
+	if replacedBy != "" {
+		t.Errorf("expected no replacement, got %q", replacedBy)
+	}
+	if len(pr.AssignedReviewers) != 1 || pr.AssignedReviewers[0] != "u3" {
+		t.Errorf("expected only u3 to remain, got %v", pr.AssignedReviewers)
+	}
+}
+
+func TestPRService_DeclineReview_QuotaExceeded(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Test",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+
+	old := domain.NewPREvent("pr-0", domain.PREventReviewerDeclined)
+	old.UserID = "u2"
+	old.CreatedAt = time.Now().Add(-48 * time.Hour)
+	recent := domain.NewPREvent("pr-0", domain.PREventReviewerDeclined)
+	recent.UserID = "u2"
+	mockRepos.eventRepo.events = []*domain.PREvent{old, recent}
+
+	service := NewPRService(repos, WithDeclineQuota(1, 24*time.Hour))
+
+	_, _, err := service.DeclineReview(context.Background(), "pr-1", "u2", domain.DeclineReasonBusy, "")
+	if err != domain.ErrDeclineQuotaExceeded {
+		t.Errorf("expected ErrDeclineQuotaExceeded, got %v", err)
+	}
+}
+
+func TestPRService_DeclineReview_OnlyForSignedInCaller(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Test",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+	service := NewPRService(repos)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "jwt:u3", UserID: "u3"})
+
+	_, _, err := service.DeclineReview(ctx, "pr-1", "u2", domain.DeclineReasonBusy, "")
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrCodeForbidden {
+		t.Fatalf("expected FORBIDDEN when declining for another user, got %v", err)
+	}
+	if !mockRepos.prRepo.prs["pr-1"].HasReviewer("u2") || len(mockRepos.eventRepo.events) != 0 {
+		t.Error("expected the pull request to stay unchanged")
+	}
+}
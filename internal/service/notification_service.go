@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/notify"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+)
+
+const (
+	// eventNotificationsLock keeps replicas from delivering the same events twice.
+	eventNotificationsLock   = "event_notifications"
+	eventNotificationsCursor = "events"
+
+	eventNotificationBatch = 200
+
+	// eventGapTimeout is how long an event ID the cursor moved past is looked for: a
+	// transaction may take it before later ones and commit after them. IDs of rolled back
+	// transactions never show up and are given up on after it. At most eventGapLimit IDs
+	// are noted per batch, the highest ones.
+	eventGapTimeout = 10 * time.Minute
+	eventGapLimit   = 1000
+)
+
+type NotificationService interface {
+	GetTemplates(ctx context.Context, teamName string) ([]*domain.MessageTemplate, error)
+	SetTemplate(ctx context.Context, tmpl *domain.MessageTemplate) (*domain.MessageTemplate, error)
+	DeliverEvents(ctx context.Context) (int, error)
+}
+
+type notificationService struct {
+	repos      *repository.Repositories
+	dispatcher *notify.Dispatcher
+	now        func() time.Time
+}
+
+func NewNotificationService(repos *repository.Repositories, dispatcher *notify.Dispatcher) NotificationService {
+	return &notificationService{repos: repos, dispatcher: dispatcher, now: time.Now}
+}
+
+func (s *notificationService) GetTemplates(ctx context.Context, teamName string) ([]*domain.MessageTemplate, error) {
+	if _, err := s.repos.Team.GetByName(ctx, teamName); err != nil {
+		return nil, err
+	}
+	return s.repos.Notification.ListTemplates(ctx, teamName)
+}
+
+// SetTemplate stores a team template; an empty body restores the default one.
+func (s *notificationService) SetTemplate(ctx context.Context, tmpl *domain.MessageTemplate) (*domain.MessageTemplate, error) {
+	if _, err := s.repos.Team.GetByName(ctx, tmpl.TeamName); err != nil {
+		return nil, err
+	}
+	if tmpl.Body == "" {
+		if err := s.repos.Notification.DeleteTemplate(ctx, tmpl.TeamName, tmpl.Kind); err != nil {
+			return nil, err
+		}
+		return tmpl, nil
+	}
+	if err := notify.ValidateChatTemplate(tmpl.Kind, tmpl.Body); err != nil {
+		return nil, domain.NewDomainError(domain.ErrCodeInvalidSettings, err.Error())
+	}
+	if err := s.repos.Notification.SetTemplate(ctx, tmpl); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// DeliverEvents notifies users about reviewer assignments, replacements and merges
+// recorded since the previous run, over the channel from their reminder preferences.
+// Events committed after the cursor moved past their IDs are delivered when they show
+// up. The batch is read and the cursor moved past it in one transaction, and the
+// notifications are sent after it commits, so that slow channels hold neither the lock
+// nor a connection. Delivery is best effort: a notification is never sent twice, but a
+// failed one is reported and not retried. On the very first run the existing history
+// is skipped. It returns how many notifications were sent.
+func (s *notificationService) DeliverEvents(ctx context.Context) (int, error) {
+	var pending []eventNotification
+	err := s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		locked, err := s.repos.Lock.TryLock(txCtx, eventNotificationsLock)
+		if err != nil || !locked {
+			return err
+		}
+
+		cursor, ok, err := s.repos.Notification.GetCursor(txCtx, eventNotificationsCursor)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			latest, err := s.repos.Event.LatestID(txCtx)
+			if err != nil {
+				return err
+			}
+			return s.repos.Notification.SetCursor(txCtx, eventNotificationsCursor, latest)
+		}
+
+		late, err := s.lateEvents(txCtx)
+		if err != nil {
+			return err
+		}
+
+		events, err := s.repos.Event.ListAfter(txCtx, cursor, eventNotificationBatch)
+		if err != nil {
+			return err
+		}
+		if len(events) > 0 {
+			last := events[len(events)-1].EventID
+			skipped, err := s.repos.Event.MissingIDs(txCtx, cursor, last, eventGapLimit)
+			if err != nil {
+				return err
+			}
+			if len(skipped) > 0 {
+				if err := s.repos.Notification.AddGaps(txCtx, eventNotificationsCursor, skipped, s.now()); err != nil {
+					return err
+				}
+			}
+			if err := s.repos.Notification.SetCursor(txCtx, eventNotificationsCursor, last); err != nil {
+				return err
+			}
+		}
+
+		for _, event := range append(late, events...) {
+			notifications, err := s.eventNotifications(txCtx, event)
+			if err != nil {
+				return err
+			}
+			for _, n := range notifications {
+				prefs, err := s.repos.Reminder.GetPreferences(txCtx, n.Recipient.UserID)
+				if err != nil {
+					return err
+				}
+				pending = append(pending, eventNotification{eventID: event.EventID, channel: prefs.Channel, notification: n})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var failures []error
+	for _, p := range pending {
+		if err := s.dispatcher.Send(ctx, p.channel, p.notification); err != nil {
+			failures = append(failures, fmt.Errorf("notify %s about event %d: %w", p.notification.Recipient.UserID, p.eventID, err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(failures...)
+}
+
+// lateEvents returns the events of the tenant that committed after the cursor moved
+// past their IDs, and stops looking for the IDs that have an event now or have timed out.
+func (s *notificationService) lateEvents(ctx context.Context) ([]*domain.PREvent, error) {
+	if err := s.repos.Notification.ExpireGaps(ctx, eventNotificationsCursor, s.now().Add(-eventGapTimeout)); err != nil {
+		return nil, err
+	}
+	gaps, err := s.repos.Notification.ListGaps(ctx, eventNotificationsCursor)
+	if err != nil || len(gaps) == 0 {
+		return nil, err
+	}
+
+	// Event IDs are global, so a gap may be filled by an event of another tenant.
+	filled, err := s.repos.Event.ExistingIDs(ctx, gaps)
+	if err != nil || len(filled) == 0 {
+		return nil, err
+	}
+	if err := s.repos.Notification.DeleteGaps(ctx, eventNotificationsCursor, filled); err != nil {
+		return nil, err
+	}
+	return s.repos.Event.ListByIDs(ctx, filled)
+}
+
+// eventNotification is a notification about an event waiting to be sent.
+type eventNotification struct {
+	eventID      int64
+	channel      domain.NotificationChannel
+	notification *notify.Notification
+}
+
+// eventNotifications maps a timeline event to the notifications it causes. Reviewers
+// that replace someone, also after a decline, get NotificationReviewerReplaced; a merge
+// is announced to the reviewers still assigned.
+func (s *notificationService) eventNotifications(ctx context.Context, event *domain.PREvent) ([]*notify.Notification, error) {
+	var kind domain.NotificationKind
+	var replaced string
+	var recipients []string
+
+	switch event.Type {
+	case domain.PREventReviewerAssigned:
+		kind, recipients = domain.NotificationReviewerAssigned, []string{event.UserID}
+	case domain.PREventReviewerReassigned, domain.PREventReviewerDeclined:
+		if event.ReplacedBy == "" {
+			return nil, nil
+		}
+		kind, recipients, replaced = domain.NotificationReviewerReplaced, []string{event.ReplacedBy}, event.UserID
+	case domain.PREventMerged:
+		kind = domain.NotificationPRMerged
+	default:
+		return nil, nil
+	}
+
+	pr, err := s.repos.PR.GetByID(ctx, event.PRID)
+	if err != nil {
+		return nil, err
+	}
+	if kind == domain.NotificationPRMerged {
+		recipients = pr.AssignedReviewers
+	}
+
+	var result []*notify.Notification
+	for _, userID := range recipients {
+		user, err := s.repos.User.GetByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if !user.IsActive {
+			continue
+		}
+		result = append(result, &notify.Notification{
+			Kind:           kind,
+			Recipient:      user,
+			PullRequests:   []*domain.PullRequest{pr},
+			ReplacedUserID: replaced,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/notify"
+)
+
+type mockNotificationRepo struct {
+	templates map[string]string
+	cursors   map[string]int64
+	gaps      map[int64]time.Time
+}
+
+func (m *mockNotificationRepo) GetTemplate(ctx context.Context, teamName string, kind domain.NotificationKind) (string, error) {
+	return m.templates[teamName+"/"+string(kind)], nil
+}
+
+func (m *mockNotificationRepo) SetTemplate(ctx context.Context, tmpl *domain.MessageTemplate) error {
+	m.templates[tmpl.TeamName+"/"+string(tmpl.Kind)] = tmpl.Body
+	return nil
+}
+
+func (m *mockNotificationRepo) DeleteTemplate(ctx context.Context, teamName string, kind domain.NotificationKind) error {
+	delete(m.templates, teamName+"/"+string(kind))
+	return nil
+}
+
+func (m *mockNotificationRepo) ListTemplates(ctx context.Context, teamName string) ([]*domain.MessageTemplate, error) {
+	return nil, nil
+}
+
+func (m *mockNotificationRepo) GetCursor(ctx context.Context, name string) (int64, bool, error) {
+	id, ok := m.cursors[name]
+	return id, ok, nil
+}
+
+func (m *mockNotificationRepo) SetCursor(ctx context.Context, name string, eventID int64) error {
+	m.cursors[name] = eventID
+	return nil
+}
+
+func (m *mockNotificationRepo) ListGaps(ctx context.Context, name string) ([]int64, error) {
+	return slices.Sorted(maps.Keys(m.gaps)), nil
+}
+
+func (m *mockNotificationRepo) AddGaps(ctx context.Context, name string, eventIDs []int64, skippedAt time.Time) error {
+	for _, id := range eventIDs {
+		m.gaps[id] = skippedAt
+	}
+	return nil
+}
+
+func (m *mockNotificationRepo) DeleteGaps(ctx context.Context, name string, eventIDs []int64) error {
+	for _, id := range eventIDs {
+		delete(m.gaps, id)
+	}
+	return nil
+}
+
+func (m *mockNotificationRepo) ExpireGaps(ctx context.Context, name string, before time.Time) error {
+	maps.DeleteFunc(m.gaps, func(id int64, skippedAt time.Time) bool { return skippedAt.Before(before) })
+	return nil
+}
+
+func newNotificationTestService() (*mockRepos, *mockNotificationRepo, *recordingNotifier, NotificationService) {
+	mockRepos, repos := newConstraintTestRepos()
+	notificationRepo := &mockNotificationRepo{templates: map[string]string{}, cursors: map[string]int64{}, gaps: map[int64]time.Time{}}
+	repos.Notification = notificationRepo
+	repos.Reminder = &mockReminderRepo{prefs: map[string]*domain.ReminderPreferences{}}
+	repos.Lock = &mockLocker{}
+
+	notifier := &recordingNotifier{}
+	dispatcher := notify.NewDispatcher()
+	dispatcher.Register(domain.ChannelLog, notifier)
+
+	return mockRepos, notificationRepo, notifier, NewNotificationService(repos, dispatcher)
+}
+
+func TestNotificationService_DeliverEvents(t *testing.T) {
+	mockRepos, _, notifier, service := newNotificationTestService()
+	ctx := context.Background()
+
+	old := domain.NewPREvent("pr-0", domain.PREventCreated)
+	old.CreatedAt = time.Now().Add(-time.Hour)
+	mockRepos.eventRepo.Record(ctx, old)
+
+	// The first run only remembers where the history ends.
+	if count, err := service.DeliverEvents(ctx); err != nil || count != 0 {
+		t.Fatalf("expected history to be skipped, got %d (err %v)", count, err)
+	}
+
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Test",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusMerged,
+		AssignedReviewers: []string{"u3"},
+	}
+	record := func(eventType domain.PREventType, userID, replacedBy string) {
+		e := domain.NewPREvent("pr-1", eventType)
+		e.UserID = userID
+		e.ReplacedBy = replacedBy
+		e.CreatedAt = time.Now().Add(-time.Minute)
+		mockRepos.eventRepo.Record(ctx, e)
+	}
+	record(domain.PREventReviewerAssigned, "u2", "")
+	record(domain.PREventReviewSubmitted, "u2", "")
+	record(domain.PREventReviewerReassigned, "u2", "u3")
+	record(domain.PREventMerged, "", "")
+
+	count, err := service.DeliverEvents(ctx)
+	if err != nil {
+		t.Fatalf("DeliverEvents failed: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("expected 3 notifications, got %d", count)
+	}
+
+	expected := []struct {
+		kind      domain.NotificationKind
+		recipient string
+	}{
+		{domain.NotificationReviewerAssigned, "u2"},
+		{domain.NotificationReviewerReplaced, "u3"},
+		{domain.NotificationPRMerged, "u3"},
+	}
+	for i, want := range expected {
+		got := notifier.sent[i]
+		if got.Kind != want.kind || got.Recipient.UserID != want.recipient {
+			t.Errorf("notification %d: expected %s for %s, got %s for %s", i, want.kind, want.recipient, got.Kind, got.Recipient.UserID)
+		}
+	}
+	if notifier.sent[1].ReplacedUserID != "u2" {
+		t.Errorf("expected replaced reviewer u2, got %q", notifier.sent[1].ReplacedUserID)
+	}
+
+	if count, err := service.DeliverEvents(ctx); err != nil || count != 0 {
+		t.Errorf("events must not be delivered twice, got %d (err %v)", count, err)
+	}
+}
+
+func TestNotificationService_DeliverEvents_CommittedLate(t *testing.T) {
+	mockRepos, notificationRepo, notifier, service := newNotificationTestService()
+	ctx := context.Background()
+
+	if _, err := service.DeliverEvents(ctx); err != nil {
+		t.Fatalf("DeliverEvents failed: %v", err)
+	}
+
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{PullRequestID: "pr-1", AuthorID: "u1", Status: domain.PRStatusOpen}
+	for _, userID := range []string{"u2", "u3"} {
+		e := domain.NewPREvent("pr-1", domain.PREventReviewerAssigned)
+		e.UserID = userID
+		mockRepos.eventRepo.Record(ctx, e)
+	}
+
+	// The transaction of the first event has not committed when the second is read.
+	uncommitted := mockRepos.eventRepo.events[0]
+	mockRepos.eventRepo.events = mockRepos.eventRepo.events[1:]
+	if count, err := service.DeliverEvents(ctx); err != nil || count != 1 {
+		t.Fatalf("expected the committed event to be delivered, got %d (err %v)", count, err)
+	}
+	if _, ok := notificationRepo.gaps[uncommitted.EventID]; !ok {
+		t.Fatalf("expected event %d to be looked for, gaps %v", uncommitted.EventID, notificationRepo.gaps)
+	}
+
+	mockRepos.eventRepo.events = append(mockRepos.eventRepo.events, uncommitted)
+	if count, err := service.DeliverEvents(ctx); err != nil || count != 1 {
+		t.Fatalf("expected the late event to be delivered, got %d (err %v)", count, err)
+	}
+	if got := notifier.sent[1].Recipient.UserID; got != "u2" {
+		t.Errorf("expected the late notification for u2, got %s", got)
+	}
+	if len(notificationRepo.gaps) != 0 {
+		t.Errorf("expected no gaps left, got %v", notificationRepo.gaps)
+	}
+
+	if count, err := service.DeliverEvents(ctx); err != nil || count != 0 {
+		t.Errorf("events must not be delivered twice, got %d (err %v)", count, err)
+	}
+}
+
+func TestNotificationService_SetTemplate_Invalid(t *testing.T) {
+	_, repos := newConstraintTestRepos()
+	repos.Notification = &mockNotificationRepo{templates: map[string]string{}}
+
+	service := NewNotificationService(repos, notify.NewDispatcher())
+	_, err := service.SetTemplate(context.Background(), &domain.MessageTemplate{
+		TeamName: "backend",
+		Kind:     domain.NotificationReviewerAssigned,
+		Body:     "{{.Unknown}}",
+	})
+
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrCodeInvalidSettings {
+		t.Errorf("expected INVALID_SETTINGS, got %v", err)
+	}
+}
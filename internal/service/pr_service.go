@@ -2,65 +2,135 @@ package service
 
 import (
 	"context"
+	"errors"
 	"math/rand"
 	"time"
 
+	"github.com/mivihan/Pull_Request_service/internal/auth"
 	"github.com/mivihan/Pull_Request_service/internal/domain"
 	"github.com/mivihan/Pull_Request_service/internal/repository"
 )
 
 type PRService interface {
-	CreatePR(ctx context.Context, prID, prName, authorID string) (*domain.PullRequest, error)
+	CreatePR(ctx context.Context, prID, prName, authorID, repository string) (*domain.PullRequest, error)
 	MergePR(ctx context.Context, prID string) (*domain.PullRequest, error)
 	ReassignReviewer(ctx context.Context, prID, oldUserID string) (*domain.PullRequest, string, error)
-	GetReviewerStats(ctx context.Context) (map[string]int, error)
-	GetPRStats(ctx context.Context) (map[string]int, error)
+	DeclineReview(ctx context.Context, prID, userID string, reason domain.DeclineReason, comment string) (*domain.PullRequest, string, error)
+	SubmitReview(ctx context.Context, prID, userID string, verdict domain.ReviewVerdict, comment string) (*domain.PREvent, error)
+	AddReviewer(ctx context.Context, prID, userID, actorID string) (*domain.PullRequest, error)
+	RemoveReviewer(ctx context.Context, prID, userID, actorID string) (*domain.PullRequest, error)
+	SetReviewers(ctx context.Context, prID string, userIDs []string, actorID string) (*domain.PullRequest, error)
+	GetTimeline(ctx context.Context, prID string) (*domain.PullRequest, []*domain.PREvent, error)
+	GetReviewerStats(ctx context.Context, filter domain.StatsFilter) (map[string]int, error)
+	GetPRStats(ctx context.Context, filter domain.StatsFilter) (map[string]int, error)
+	GetDeclineStats(ctx context.Context, filter domain.StatsFilter) ([]*domain.DeclineStat, error)
+	ReassignStaleReviews(ctx context.Context) (int, error)
 }
 
-type prService struct {
-	repos *repository.Repositories
-	rand  *rand.Rand
-}
+const (
+	defaultDeclineQuota  = 3
+	defaultDeclinePeriod = 7 * 24 * time.Hour
+
+	defaultStaleReassignmentCap = 2
+)
+
+// staleReviewsLock keeps replicas from reassigning the same stale reviews concurrently.
+const staleReviewsLock = "stale_reviews"
+
+type PRServiceOption func(*prService)
 
-func NewPRService(repos *repository.Repositories) PRService {
-	return &prService{
-		repos: repos,
-		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+// WithSelector replaces the default random reviewer selection.
+func WithSelector(selector ReviewerSelector) PRServiceOption {
+	return func(s *prService) {
+		s.selector = selector
 	}
 }
 
-func (s *prService) CreatePR(ctx context.Context, prID, prName, authorID string) (*domain.PullRequest, error) {
-	exists, err := s.repos.PR.Exists(ctx, prID)
-	if err != nil {
-		return nil, err
-	}
-	if exists {
-		return nil, domain.ErrPRExists
+// WithDeclineQuota limits how many reviews a user may decline within a rolling period.
+// A non-positive limit disables the quota.
+func WithDeclineQuota(limit int, period time.Duration) PRServiceOption {
+	return func(s *prService) {
+		s.declineQuota = limit
+		s.declinePeriod = period
 	}
+}
 
-	author, err := s.repos.User.GetByID(ctx, authorID)
-	if err != nil {
-		return nil, err
+// WithStaleReassignmentCap limits how many times a single pull request may be
+// reassigned automatically because of stale reviews.
+func WithStaleReassignmentCap(limit int) PRServiceOption {
+	return func(s *prService) {
+		s.staleCap = limit
 	}
+}
 
-	candidates, err := s.repos.User.ListActiveByTeamExcluding(ctx, author.TeamName, []string{authorID})
-	if err != nil {
-		return nil, err
-	}
+type prService struct {
+	repos         *repository.Repositories
+	selector      ReviewerSelector
+	declineQuota  int
+	declinePeriod time.Duration
+	staleCap      int
+}
 
-	reviewers := s.selectRandomReviewers(candidates, 2)
-	reviewerIDs := extractUserIDs(reviewers)
+func NewPRService(repos *repository.Repositories, opts ...PRServiceOption) PRService {
+	s := &prService{
+		repos:         repos,
+		selector:      NewRandomSelector(rand.NewSource(time.Now().UnixNano())),
+		declineQuota:  defaultDeclineQuota,
+		declinePeriod: defaultDeclinePeriod,
+		staleCap:      defaultStaleReassignmentCap,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
 
+func (s *prService) CreatePR(ctx context.Context, prID, prName, authorID, repository string) (*domain.PullRequest, error) {
 	pr := &domain.PullRequest{
-		PullRequestID:     prID,
-		PullRequestName:   prName,
-		AuthorID:          authorID,
-		Status:            domain.PRStatusOpen,
-		AssignedReviewers: reviewerIDs,
-		CreatedAt:         time.Now(),
+		PullRequestID:   prID,
+		PullRequestName: prName,
+		AuthorID:        authorID,
+		Repository:      repository,
+		Status:          domain.PRStatusOpen,
+		CreatedAt:       time.Now(),
 	}
 
-	err = s.repos.WithTx(ctx, func(txCtx context.Context) error {
+	// The existence check only saves work: of concurrent creations of the same ID the
+	// insert lets one through and fails the others with ErrPRExists. Selection runs in
+	// the same transaction so that a round-robin cursor only advances when the pull
+	// request is actually created.
+	err := s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		exists, err := s.repos.PR.Exists(txCtx, prID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return domain.ErrPRExists
+		}
+
+		author, err := s.repos.User.GetByID(txCtx, authorID)
+		if err != nil {
+			return err
+		}
+
+		candidates, err := s.repos.User.ListActiveByTeamExcluding(txCtx, author.TeamName, []string{authorID})
+		if err != nil {
+			return err
+		}
+
+		constraints, err := s.repos.Constraint.GetForAuthor(txCtx, authorID)
+		if err != nil {
+			return err
+		}
+		candidates = filterAllowed(candidates, constraints, authorID)
+
+		reviewers, err := s.selector.Select(txCtx, SelectionKey{PRID: prID, TeamName: author.TeamName}, candidates, domain.MaxReviewers)
+		if err != nil {
+			return err
+		}
+		reviewerIDs := extractUserIDs(reviewers)
+		pr.AssignedReviewers = reviewerIDs
+
 		if err := s.repos.PR.Create(txCtx, pr); err != nil {
 			return err
 		}
@@ -70,6 +140,19 @@ func (s *prService) CreatePR(ctx context.Context, prID, prName, authorID string)
 			}
 		}
 
+		created := domain.NewPREvent(prID, domain.PREventCreated)
+		created.ActorID = authorID
+		if err := s.repos.Event.Record(txCtx, created); err != nil {
+			return err
+		}
+		for _, reviewerID := range reviewerIDs {
+			assigned := domain.NewPREvent(prID, domain.PREventReviewerAssigned)
+			assigned.UserID = reviewerID
+			if err := s.repos.Event.Record(txCtx, assigned); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -79,19 +162,38 @@ func (s *prService) CreatePR(ctx context.Context, prID, prName, authorID string)
 	return pr, nil
 }
 
+// MergePR merges the pull request once: concurrent calls wait for the first one and
+// then see the pull request merged.
 func (s *prService) MergePR(ctx context.Context, prID string) (*domain.PullRequest, error) {
-	pr, err := s.repos.PR.GetByID(ctx, prID)
+	actorID, err := actingUser(ctx, "")
 	if err != nil {
 		return nil, err
 	}
 
-	if pr.IsMerged() {
-		return pr, nil
-	}
+	var pr *domain.PullRequest
+	err = s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		var err error
+		pr, err = s.lockPR(txCtx, prID)
+		if err != nil {
+			return err
+		}
+
+		if pr.IsMerged() {
+			return nil
+		}
 
-	pr.Merge()
+		pr.Merge()
 
-	err = s.repos.PR.UpdateStatus(ctx, prID, pr.Status, pr.MergedAt)
+		if err := s.repos.PR.UpdateStatus(txCtx, prID, pr.Status, pr.MergedAt); err != nil {
+			return err
+		}
+		if err := s.bumpVersion(txCtx, pr); err != nil {
+			return err
+		}
+		event := domain.NewPREvent(prID, domain.PREventMerged)
+		event.ActorID = actorID
+		return s.repos.Event.Record(txCtx, event)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -100,67 +202,496 @@ func (s *prService) MergePR(ctx context.Context, prID string) (*domain.PullReque
 }
 
 func (s *prService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (*domain.PullRequest, string, error) {
-	pr, err := s.repos.PR.GetByID(ctx, prID)
+	var pr *domain.PullRequest
+	var newReviewerID string
+	err := s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		var err error
+		pr, err = s.getModifiablePR(txCtx, prID, oldUserID)
+		if err != nil {
+			return err
+		}
+
+		newReviewerID, err = s.reassign(txCtx, pr, oldUserID, "")
+		return err
+	})
 	if err != nil {
 		return nil, "", err
 	}
 
-	if err := pr.CanModifyReviewers(); err != nil {
-		return nil, "", err
-	}
+	return pr, newReviewerID, nil
+}
+
+// reassign replaces oldUserID on pr with a candidate from findReplacement and records a
+// REVIEWER_REASSIGNED event with the given reason.
+func (s *prService) reassign(ctx context.Context, pr *domain.PullRequest, oldUserID, reason string) (string, error) {
+	var newReviewer *domain.User
+	err := s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		selected, err := s.findReplacement(txCtx, pr, oldUserID)
+		if err != nil {
+			return err
+		}
+		if selected == nil {
+			return domain.ErrNoCandidate
+		}
+		newReviewer = selected
+
+		if err := s.repos.PR.ReplaceReviewer(txCtx, pr.PullRequestID, oldUserID, newReviewer.UserID); err != nil {
+			return err
+		}
+		if err := s.bumpVersion(txCtx, pr); err != nil {
+			return err
+		}
 
-	if !pr.HasReviewer(oldUserID) {
-		return nil, "", domain.ErrNotAssigned
+		event := domain.NewPREvent(pr.PullRequestID, domain.PREventReviewerReassigned)
+		event.UserID = oldUserID
+		event.ReplacedBy = newReviewer.UserID
+		event.Reason = reason
+		return s.repos.Event.Record(txCtx, event)
+	})
+	if err != nil {
+		return "", err
 	}
 
-	oldReviewer, err := s.repos.User.GetByID(ctx, oldUserID)
+	replaceReviewerID(pr, oldUserID, newReviewer.UserID)
+
+	return newReviewer.UserID, nil
+}
+
+// DeclineReview lets a reviewer step down from a pull request. A replacement is picked
+// the same way as in ReassignReviewer; if nobody is available the reviewer is just removed.
+func (s *prService) DeclineReview(
+	ctx context.Context,
+	prID, userID string,
+	reason domain.DeclineReason,
+	comment string,
+) (*domain.PullRequest, string, error) {
+	userID, err := actingUser(ctx, userID)
 	if err != nil {
 		return nil, "", err
 	}
 
-	excludeIDs := append(pr.AssignedReviewers, pr.AuthorID)
-	candidates, err := s.repos.User.ListActiveByTeamExcluding(ctx, oldReviewer.TeamName, excludeIDs)
+	var pr *domain.PullRequest
+	var replacedBy string
+	err = s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		var err error
+		pr, err = s.getModifiablePR(txCtx, prID, userID)
+		if err != nil {
+			return err
+		}
+
+		if s.declineQuota > 0 {
+			declined, err := s.repos.Event.CountByUser(txCtx, userID, domain.PREventReviewerDeclined, time.Now().Add(-s.declinePeriod))
+			if err != nil {
+				return err
+			}
+			if declined >= s.declineQuota {
+				return domain.ErrDeclineQuotaExceeded
+			}
+		}
+
+		newReviewer, err := s.findReplacement(txCtx, pr, userID)
+		if err != nil {
+			return err
+		}
+		if newReviewer != nil {
+			replacedBy = newReviewer.UserID
+		}
+
+		if replacedBy != "" {
+			if err := s.repos.PR.ReplaceReviewer(txCtx, prID, userID, replacedBy); err != nil {
+				return err
+			}
+		} else {
+			if err := s.repos.PR.RemoveReviewer(txCtx, prID, userID); err != nil {
+				return err
+			}
+		}
+		if err := s.bumpVersion(txCtx, pr); err != nil {
+			return err
+		}
+
+		event := domain.NewPREvent(prID, domain.PREventReviewerDeclined)
+		event.ActorID = userID
+		event.UserID = userID
+		event.ReplacedBy = replacedBy
+		event.Reason = string(reason)
+		event.Comment = comment
+		return s.repos.Event.Record(txCtx, event)
+	})
 	if err != nil {
 		return nil, "", err
 	}
 
-	if len(candidates) == 0 {
-		return nil, "", domain.ErrNoCandidate
+	replaceReviewerID(pr, userID, replacedBy)
+
+	return pr, replacedBy, nil
+}
+
+// SubmitReview records a review left by one of the currently assigned reviewers.
+func (s *prService) SubmitReview(
+	ctx context.Context,
+	prID, userID string,
+	verdict domain.ReviewVerdict,
+	comment string,
+) (*domain.PREvent, error) {
+	userID, err := actingUser(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	newReviewer := s.selectRandomReviewers(candidates, 1)[0]
+	event := domain.NewPREvent(prID, domain.PREventReviewSubmitted)
+	event.ActorID = userID
+	event.UserID = userID
+	event.Reason = string(verdict)
+	event.Comment = comment
 
 	err = s.repos.WithTx(ctx, func(txCtx context.Context) error {
-		return s.repos.PR.ReplaceReviewer(txCtx, prID, oldUserID, newReviewer.UserID)
+		if _, err := s.getModifiablePR(txCtx, prID, userID); err != nil {
+			return err
+		}
+		return s.repos.Event.Record(txCtx, event)
 	})
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	for i, id := range pr.AssignedReviewers {
-		if id == oldUserID {
-			pr.AssignedReviewers[i] = newReviewer.UserID
-			break
+	return event, nil
+}
+
+// AddReviewer explicitly assigns a reviewer chosen by actorID instead of a random one.
+func (s *prService) AddReviewer(ctx context.Context, prID, userID, actorID string) (*domain.PullRequest, error) {
+	actorID, err := actingUser(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr *domain.PullRequest
+	err = s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		pr, err = s.lockPR(txCtx, prID)
+		if err != nil {
+			return err
 		}
+		if err := pr.CanModifyReviewers(); err != nil {
+			return err
+		}
+		if pr.HasReviewer(userID) {
+			return domain.ErrAlreadyAssigned
+		}
+		if len(pr.AssignedReviewers) >= domain.MaxReviewers {
+			return domain.ErrTooManyReviewers
+		}
+
+		if err := s.validateManualReviewers(txCtx, pr, []string{userID}); err != nil {
+			return err
+		}
+
+		if err := s.addReviewer(txCtx, pr, userID, actorID); err != nil {
+			return err
+		}
+		return s.bumpVersion(txCtx, pr)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return pr, newReviewer.UserID, nil
+	pr.AssignedReviewers = append(pr.AssignedReviewers, userID)
+
+	return pr, nil
 }
 
-func (s *prService) selectRandomReviewers(users []*domain.User, maxCount int) []*domain.User {
-	if len(users) == 0 {
-		return nil
+func (s *prService) RemoveReviewer(ctx context.Context, prID, userID, actorID string) (*domain.PullRequest, error) {
+	actorID, err := actingUser(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr *domain.PullRequest
+	err = s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		pr, err = s.getModifiablePR(txCtx, prID, userID)
+		if err != nil {
+			return err
+		}
+		if err := s.removeReviewer(txCtx, pr, userID, actorID); err != nil {
+			return err
+		}
+		return s.bumpVersion(txCtx, pr)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	replaceReviewerID(pr, userID, "")
+
+	return pr, nil
+}
+
+// SetReviewers replaces the whole reviewer list. Reviewers present in both the old and
+// the new list keep their original assignment time.
+func (s *prService) SetReviewers(ctx context.Context, prID string, userIDs []string, actorID string) (*domain.PullRequest, error) {
+	actorID, err := actingUser(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(userIDs) > domain.MaxReviewers {
+		return nil, domain.ErrTooManyReviewers
+	}
+
+	wanted := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		if wanted[id] {
+			return nil, domain.ErrDuplicateReviewer
+		}
+		wanted[id] = true
 	}
 
-	count := min(maxCount, len(users))
+	var pr *domain.PullRequest
+	var added []string
+	err = s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		pr, err = s.lockPR(txCtx, prID)
+		if err != nil {
+			return err
+		}
+		if err := pr.CanModifyReviewers(); err != nil {
+			return err
+		}
+
+		for _, id := range userIDs {
+			if !pr.HasReviewer(id) {
+				added = append(added, id)
+			}
+		}
+
+		var removed []string
+		for _, id := range pr.AssignedReviewers {
+			if !wanted[id] {
+				removed = append(removed, id)
+			}
+		}
 
-	shuffled := make([]*domain.User, len(users))
-	copy(shuffled, users)
-	s.rand.Shuffle(len(shuffled), func(i, j int) {
-		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		if err := s.validateManualReviewers(txCtx, pr, added); err != nil {
+			return err
+		}
+
+		for _, id := range removed {
+			if err := s.removeReviewer(txCtx, pr, id, actorID); err != nil {
+				return err
+			}
+		}
+		for _, id := range added {
+			if err := s.addReviewer(txCtx, pr, id, actorID); err != nil {
+				return err
+			}
+		}
+		if len(removed) == 0 && len(added) == 0 {
+			return nil
+		}
+		return s.bumpVersion(txCtx, pr)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]string, 0, len(userIDs))
+	for _, id := range pr.AssignedReviewers {
+		if wanted[id] {
+			kept = append(kept, id)
+		}
+	}
+	pr.AssignedReviewers = append(kept, added...)
+
+	return pr, nil
+}
+
+// validateManualReviewers applies the same rules as automatic selection to reviewers
+// picked by hand: active, in the author's team, not the author and not excluded.
+func (s *prService) validateManualReviewers(ctx context.Context, pr *domain.PullRequest, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	author, err := s.repos.User.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	constraints, err := s.repos.Constraint.GetForAuthor(ctx, pr.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range userIDs {
+		if id == pr.AuthorID {
+			return domain.ErrReviewerIsAuthor
+		}
+
+		user, err := s.repos.User.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !user.CanBeReviewer() {
+			return domain.ErrReviewerInactive
+		}
+		if user.TeamName != author.TeamName {
+			return domain.ErrReviewerOtherTeam
+		}
+		if !constraints.Allows(pr.AuthorID, id) {
+			return domain.ErrReviewerNotAllowed
+		}
+	}
+
+	return nil
+}
+
+func (s *prService) addReviewer(ctx context.Context, pr *domain.PullRequest, userID, actorID string) error {
+	if err := s.repos.PR.AddReviewer(ctx, pr.PullRequestID, userID); err != nil {
+		return err
+	}
+
+	event := domain.NewPREvent(pr.PullRequestID, domain.PREventReviewerAssigned)
+	event.ActorID = actorID
+	event.UserID = userID
+	event.Reason = domain.ManualReason
+	return s.repos.Event.Record(ctx, event)
+}
+
+func (s *prService) removeReviewer(ctx context.Context, pr *domain.PullRequest, userID, actorID string) error {
+	if err := s.repos.PR.RemoveReviewer(ctx, pr.PullRequestID, userID); err != nil {
+		return err
+	}
+
+	event := domain.NewPREvent(pr.PullRequestID, domain.PREventReviewerRemoved)
+	event.ActorID = actorID
+	event.UserID = userID
+	event.Reason = domain.ManualReason
+	return s.repos.Event.Record(ctx, event)
+}
+
+// GetTimeline returns the pull request with its events.
+func (s *prService) GetTimeline(ctx context.Context, prID string) (*domain.PullRequest, []*domain.PREvent, error) {
+	pr, err := s.repos.PR.GetByID(ctx, prID)
+	if err != nil {
+		return nil, nil, err
+	}
+	events, err := s.repos.Event.ListByPR(ctx, prID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pr, events, nil
+}
+
+// actingUser returns the user a manual change is recorded for. Callers signed in as a
+// user always act as themselves.
+func actingUser(ctx context.Context, actorID string) (string, error) {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok || principal.UserID == "" || principal.UserID == actorID {
+		return actorID, nil
+	}
+	if actorID != "" {
+		return "", domain.NewDomainError(domain.ErrCodeForbidden, "cannot act on behalf of another user")
+	}
+	return principal.UserID, nil
+}
+
+// lockPR locks the pull request for the transaction of ctx and checks it against the
+// versions ctx expects.
+func (s *prService) lockPR(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	pr, err := s.repos.PR.GetByIDForUpdate(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(ctx, pr.Version); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// bumpVersion records that pr was changed. It is called once per change, after the
+// change was written.
+func (s *prService) bumpVersion(ctx context.Context, pr *domain.PullRequest) error {
+	version, err := s.repos.PR.BumpVersion(ctx, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	pr.Version = version
+	return nil
+}
 
-	return shuffled[:count]
+// getModifiablePR locks the pull request for the transaction of ctx, checks its version
+// and that reviewerID may still be replaced on it.
+func (s *prService) getModifiablePR(ctx context.Context, prID, reviewerID string) (*domain.PullRequest, error) {
+	pr, err := s.lockPR(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pr.CanModifyReviewers(); err != nil {
+		return nil, err
+	}
+
+	if !pr.HasReviewer(reviewerID) {
+		return nil, domain.ErrNotAssigned
+	}
+
+	return pr, nil
+}
+
+// findReplacement picks a new reviewer from the old reviewer's team, skipping the author,
+// current reviewers and anyone the author's constraints forbid. It returns nil when no
+// candidate is available.
+func (s *prService) findReplacement(ctx context.Context, pr *domain.PullRequest, oldUserID string) (*domain.User, error) {
+	oldReviewer, err := s.repos.User.GetByID(ctx, oldUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	excludeIDs := append([]string{pr.AuthorID}, pr.AssignedReviewers...)
+	candidates, err := s.repos.User.ListActiveByTeamExcluding(ctx, oldReviewer.TeamName, excludeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	constraints, err := s.repos.Constraint.GetForAuthor(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	candidates = filterAllowed(candidates, constraints, pr.AuthorID)
+
+	selected, err := s.selector.Select(ctx, SelectionKey{PRID: pr.PullRequestID, TeamName: oldReviewer.TeamName}, candidates, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+
+	return selected[0], nil
+}
+
+// replaceReviewerID updates the in-memory reviewer list; an empty newUserID removes the reviewer.
+func replaceReviewerID(pr *domain.PullRequest, oldUserID, newUserID string) {
+	for i, id := range pr.AssignedReviewers {
+		if id != oldUserID {
+			continue
+		}
+		if newUserID == "" {
+			pr.AssignedReviewers = append(pr.AssignedReviewers[:i], pr.AssignedReviewers[i+1:]...)
+		} else {
+			pr.AssignedReviewers[i] = newUserID
+		}
+		return
+	}
+}
+
+// filterAllowed drops candidates that the author's exclusion pairs or never-assign list forbid.
+func filterAllowed(users []*domain.User, constraints *domain.ReviewConstraints, authorID string) []*domain.User {
+	allowed := make([]*domain.User, 0, len(users))
+	for _, user := range users {
+		if constraints.Allows(authorID, user.UserID) {
+			allowed = append(allowed, user)
+		}
+	}
+	return allowed
 }
 
 func extractUserIDs(users []*domain.User) []string {
@@ -171,17 +702,105 @@ func extractUserIDs(users []*domain.User) []string {
 	return ids
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+func (s *prService) GetReviewerStats(ctx context.Context, filter domain.StatsFilter) (map[string]int, error) {
+	if err := checkStatsTeam(ctx, s.repos, filter); err != nil {
+		return nil, err
+	}
+	return s.repos.PR.GetReviewerStats(ctx, filter)
+}
+
+func (s *prService) GetPRStats(ctx context.Context, filter domain.StatsFilter) (map[string]int, error) {
+	if err := checkStatsTeam(ctx, s.repos, filter); err != nil {
+		return nil, err
+	}
+	return s.repos.PR.GetPRStats(ctx, filter)
+}
+
+func (s *prService) GetDeclineStats(ctx context.Context, filter domain.StatsFilter) ([]*domain.DeclineStat, error) {
+	if err := checkStatsTeam(ctx, s.repos, filter); err != nil {
+		return nil, err
 	}
-	return b
+	return s.repos.Event.GetDeclineStats(ctx, filter)
 }
 
-func (s *prService) GetReviewerStats(ctx context.Context) (map[string]int, error) {
-	return s.repos.PR.GetReviewerStats(ctx)
+// ReassignStaleReviews hands over reviews that stayed unanswered longer than the team's
+// stale timeout, picking replacements like ReassignReviewer. It returns how many
+// reviewers were replaced. When another replica holds the lock it does nothing.
+func (s *prService) ReassignStaleReviews(ctx context.Context) (int, error) {
+	reassigned := 0
+	err := s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		locked, err := s.repos.Lock.TryLock(txCtx, staleReviewsLock)
+		if err != nil || !locked {
+			return err
+		}
+
+		teams, err := s.repos.Settings.ListWithStaleTimeout(txCtx)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, settings := range teams {
+			events, err := s.repos.Event.ListOpenByTeam(txCtx, settings.TeamName)
+			if err != nil {
+				return err
+			}
+
+			deadline := func(_ string, assignedAt time.Time) (time.Time, bool) {
+				return settings.StaleDeadline(assignedAt)
+			}
+
+			for start := 0; start < len(events); {
+				end := start
+				for end < len(events) && events[end].PRID == events[start].PRID {
+					end++
+				}
+
+				stale := domain.FindStaleReviewers(events[start:end], deadline, now, s.staleCap)
+				count, err := s.reassignStale(txCtx, events[start].PRID, stale)
+				if err != nil {
+					return err
+				}
+				reassigned += count
+				start = end
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return reassigned, nil
 }
 
-func (s *prService) GetPRStats(ctx context.Context) (map[string]int, error) {
-	return s.repos.PR.GetPRStats(ctx)
-}
\ No newline at end of file
+// reassignStale replaces the given reviewers of one pull request. Reviewers without an
+// available replacement stay assigned.
+func (s *prService) reassignStale(ctx context.Context, prID string, reviewerIDs []string) (int, error) {
+	if len(reviewerIDs) == 0 {
+		return 0, nil
+	}
+
+	pr, err := s.repos.PR.GetByIDForUpdate(ctx, prID)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, reviewerID := range reviewerIDs {
+		if !pr.HasReviewer(reviewerID) {
+			continue
+		}
+		_, err := s.reassign(ctx, pr, reviewerID, domain.StaleReason)
+		if errors.Is(err, domain.ErrNoCandidate) {
+			continue
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -3,9 +3,12 @@ package service
 import (
 	"context"
 	"errors"
+	"slices"
+	"sort"
 	"testing"
 	"time"
 
+	"github.com/mivihan/Pull_Request_service/internal/auth"
 	"github.com/mivihan/Pull_Request_service/internal/domain"
 	"github.com/mivihan/Pull_Request_service/internal/repository"
 )
@@ -52,8 +55,43 @@ func (m *mockUserRepo) SetIsActive(ctx context.Context, userID string, isActive
 	return nil, errors.New("not implemented")
 }
 
+func (m *mockUserRepo) SetSlackMemberID(ctx context.Context, userID, memberID string) (*domain.User, error) {
+	user, ok := m.users[userID]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	user.SlackMemberID = memberID
+	return user, nil
+}
+
+func (m *mockUserRepo) SetEmail(ctx context.Context, userID, email string) (*domain.User, error) {
+	user, ok := m.users[userID]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	user.Email = email
+	return user, nil
+}
+
 func (m *mockUserRepo) ListByTeam(ctx context.Context, teamName string) ([]*domain.User, error) {
-	return nil, errors.New("not implemented")
+	var result []*domain.User
+	for _, user := range m.users {
+		if user.TeamName == teamName {
+			result = append(result, user)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockUserRepo) DeactivateUsers(ctx context.Context, teamName string, userIDs []string) (int, error) {
+	count := 0
+	for _, id := range userIDs {
+		if user, ok := m.users[id]; ok && user.TeamName == teamName {
+			user.IsActive = false
+			count++
+		}
+	}
+	return count, nil
 }
 
 type mockPRRepo struct {
@@ -71,6 +109,7 @@ func (m *mockPRRepo) Create(ctx context.Context, pr *domain.PullRequest) error {
 	if _, exists := m.prs[pr.PullRequestID]; exists {
 		return domain.ErrPRExists
 	}
+	pr.Version = 1
 	m.prs[pr.PullRequestID] = pr
 	return nil
 }
@@ -80,7 +119,13 @@ func (m *mockPRRepo) GetByID(ctx context.Context, prID string) (*domain.PullRequ
 	if !ok {
 		return nil, domain.ErrPRNotFound
 	}
-	return pr, nil
+	cp := *pr
+	cp.AssignedReviewers = append([]string(nil), pr.AssignedReviewers...)
+	return &cp, nil
+}
+
+func (m *mockPRRepo) GetByIDForUpdate(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return m.GetByID(ctx, prID)
 }
 
 func (m *mockPRRepo) Exists(ctx context.Context, prID string) (bool, error) {
@@ -98,6 +143,15 @@ func (m *mockPRRepo) UpdateStatus(ctx context.Context, prID string, status domai
 	return nil
 }
 
+func (m *mockPRRepo) BumpVersion(ctx context.Context, prID string) (int64, error) {
+	pr, ok := m.prs[prID]
+	if !ok {
+		return 0, domain.ErrPRNotFound
+	}
+	pr.Version++
+	return pr.Version, nil
+}
+
 func (m *mockPRRepo) AssignReviewers(ctx context.Context, prID string, userIDs []string) error {
 	pr, ok := m.prs[prID]
 	if !ok {
@@ -129,13 +183,209 @@ func (m *mockPRRepo) ReplaceReviewer(ctx context.Context, prID, oldUserID, newUs
 	return nil
 }
 
+func (m *mockPRRepo) AddReviewer(ctx context.Context, prID, userID string) error {
+	pr, ok := m.prs[prID]
+	if !ok {
+		return domain.ErrPRNotFound
+	}
+	if pr.HasReviewer(userID) {
+		return domain.ErrAlreadyAssigned
+	}
+	pr.AssignedReviewers = append(pr.AssignedReviewers, userID)
+	return nil
+}
+
+func (m *mockPRRepo) RemoveReviewer(ctx context.Context, prID, userID string) error {
+	pr, ok := m.prs[prID]
+	if !ok {
+		return domain.ErrPRNotFound
+	}
+
+	for i, id := range pr.AssignedReviewers {
+		if id == userID {
+			pr.AssignedReviewers = append(pr.AssignedReviewers[:i], pr.AssignedReviewers[i+1:]...)
+			return nil
+		}
+	}
+
+	return domain.ErrNotAssigned
+}
+
 func (m *mockPRRepo) ListByReviewer(ctx context.Context, userID string) ([]*domain.PullRequest, error) {
+	var result []*domain.PullRequest
+	for _, pr := range m.prs {
+		if pr.HasReviewer(userID) {
+			prCopy := *pr
+			result = append(result, &prCopy)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockPRRepo) GetReviewerStats(ctx context.Context, filter domain.StatsFilter) (map[string]int, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockPRRepo) GetPRStats(ctx context.Context, filter domain.StatsFilter) (map[string]int, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockPRRepo) GetOpenPRsByReviewers(ctx context.Context, userIDs []string) ([]*domain.PullRequest, error) {
+	var result []*domain.PullRequest
+	for _, pr := range m.prs {
+		if pr.IsMerged() {
+			continue
+		}
+		for _, id := range userIDs {
+			if pr.HasReviewer(id) {
+				result = append(result, pr)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+type mockConstraintRepo struct {
+	pairs       []*domain.ExclusionPair
+	neverAssign []*domain.NeverAssign
+}
+
+func (m *mockConstraintRepo) AddExclusion(ctx context.Context, pair *domain.ExclusionPair) error {
+	m.pairs = append(m.pairs, pair)
+	return nil
+}
+
+func (m *mockConstraintRepo) RemoveExclusion(ctx context.Context, pair *domain.ExclusionPair) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockConstraintRepo) ListExclusionsByTeam(ctx context.Context, teamName string) ([]*domain.ExclusionPair, error) {
+	return m.pairs, nil
+}
+
+func (m *mockConstraintRepo) AddNeverAssign(ctx context.Context, entry *domain.NeverAssign) error {
+	m.neverAssign = append(m.neverAssign, entry)
+	return nil
+}
+
+func (m *mockConstraintRepo) RemoveNeverAssign(ctx context.Context, authorID, reviewerID string) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockConstraintRepo) ListNeverAssignByAuthor(ctx context.Context, authorID string) ([]*domain.NeverAssign, error) {
+	return m.neverAssign, nil
+}
+
+func (m *mockConstraintRepo) GetForTeam(ctx context.Context, teamName string) (*domain.ReviewConstraints, error) {
+	return &domain.ReviewConstraints{Pairs: m.pairs, NeverAssign: m.neverAssign}, nil
+}
+
+func (m *mockConstraintRepo) GetForAuthor(ctx context.Context, authorID string) (*domain.ReviewConstraints, error) {
+	return &domain.ReviewConstraints{Pairs: m.pairs, NeverAssign: m.neverAssign}, nil
+}
+
+type mockEventRepo struct {
+	events []*domain.PREvent
+}
+
+func (m *mockEventRepo) Record(ctx context.Context, event *domain.PREvent) error {
+	event.EventID = int64(len(m.events) + 1)
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockEventRepo) ListByPR(ctx context.Context, prID string) ([]*domain.PREvent, error) {
+	var result []*domain.PREvent
+	for _, e := range m.events {
+		if e.PRID == prID {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+// ListOpenByTeam ignores the team and PR status: tests only register events of open
+// pull requests of a single team.
+func (m *mockEventRepo) ListOpenByTeam(ctx context.Context, teamName string) ([]*domain.PREvent, error) {
+	result := append([]*domain.PREvent(nil), m.events...)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].PRID < result[j].PRID
+	})
+	return result, nil
+}
+
+func (m *mockEventRepo) ListAfter(ctx context.Context, afterID int64, limit int) ([]*domain.PREvent, error) {
+	var result []*domain.PREvent
+	for _, e := range m.events {
+		if e.EventID > afterID && len(result) < limit {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockEventRepo) ListByIDs(ctx context.Context, ids []int64) ([]*domain.PREvent, error) {
+	var result []*domain.PREvent
+	for _, e := range m.events {
+		if slices.Contains(ids, e.EventID) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockEventRepo) MissingIDs(ctx context.Context, afterID, beforeID int64, limit int) ([]int64, error) {
+	var result []int64
+	for id := max(afterID+1, beforeID-int64(limit)); id < beforeID; id++ {
+		if !slices.ContainsFunc(m.events, func(e *domain.PREvent) bool { return e.EventID == id }) {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockEventRepo) ExistingIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	var result []int64
+	for _, e := range m.events {
+		if slices.Contains(ids, e.EventID) {
+			result = append(result, e.EventID)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockEventRepo) LatestID(ctx context.Context) (int64, error) {
+	return int64(len(m.events)), nil
+}
+
+func (m *mockEventRepo) ListFeed(ctx context.Context, afterID int64, limit int) ([]*domain.FeedEvent, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockEventRepo) ListFeedByIDs(ctx context.Context, ids []int64) ([]*domain.FeedEvent, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockEventRepo) CountByUser(ctx context.Context, userID string, eventType domain.PREventType, since time.Time) (int, error) {
+	count := 0
+	for _, e := range m.events {
+		if e.UserID == userID && e.Type == eventType && !e.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockEventRepo) GetDeclineStats(ctx context.Context, filter domain.StatsFilter) ([]*domain.DeclineStat, error) {
 	return nil, errors.New("not implemented")
 }
 
 type mockRepos struct {
-	userRepo *mockUserRepo
-	prRepo   *mockPRRepo
+	userRepo       *mockUserRepo
+	prRepo         *mockPRRepo
+	constraintRepo *mockConstraintRepo
+	eventRepo      *mockEventRepo
 }
 
 func (m *mockRepos) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
@@ -144,8 +394,10 @@ func (m *mockRepos) WithTx(ctx context.Context, fn func(ctx context.Context) err
 
 func newMockRepos() *mockRepos {
 	return &mockRepos{
-		userRepo: newMockUserRepo(),
-		prRepo:   newMockPRRepo(),
+		userRepo:       newMockUserRepo(),
+		prRepo:         newMockPRRepo(),
+		constraintRepo: &mockConstraintRepo{},
+		eventRepo:      &mockEventRepo{},
 	}
 }
 
@@ -172,13 +424,16 @@ func TestPRService_CreatePR_AutoAssign(t *testing.T) {
 	}
 
 	repos := &repository.Repositories{}
+	repos.Constraint = mockRepos.constraintRepo
+	repos.Event = mockRepos.eventRepo
+	repos.Tx = mockRepos
 	repos.User = mockRepos.userRepo
 	repos.PR = mockRepos.prRepo
 
 	service := NewPRService(repos)
 
 	ctx := context.Background()
-	pr, err := service.CreatePR(ctx, "pr-1", "Test PR", "u1")
+	pr, err := service.CreatePR(ctx, "pr-1", "Test PR", "u1", "")
 
 	if err != nil {
 		t.Fatalf("CreatePR failed: %v", err)
@@ -214,13 +469,16 @@ func TestPRService_CreatePR_NoActiveCandidates(t *testing.T) {
 	}
 
 	repos := &repository.Repositories{}
+	repos.Constraint = mockRepos.constraintRepo
+	repos.Event = mockRepos.eventRepo
+	repos.Tx = mockRepos
 	repos.User = mockRepos.userRepo
 	repos.PR = mockRepos.prRepo
 
 	service := NewPRService(repos)
 
 	ctx := context.Background()
-	pr, err := service.CreatePR(ctx, "pr-1", "Test PR", "u1")
+	pr, err := service.CreatePR(ctx, "pr-1", "Test PR", "u1", "")
 
 	if err != nil {
 		t.Fatalf("CreatePR failed: %v", err)
@@ -244,11 +502,14 @@ func TestPRService_MergePR_Idempotency(t *testing.T) {
 	}
 
 	repos := &repository.Repositories{}
+	repos.Constraint = mockRepos.constraintRepo
+	repos.Event = mockRepos.eventRepo
+	repos.Tx = mockRepos
 	repos.PR = mockRepos.prRepo
 
 	service := NewPRService(repos)
 
-	ctx := context.Background()
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "jwt:u1", UserID: "u1"})
 
 	pr1, err := service.MergePR(ctx, "pr-1")
 	if err != nil {
@@ -280,6 +541,11 @@ func TestPRService_MergePR_Idempotency(t *testing.T) {
 	if !pr2.MergedAt.Equal(firstMergedAt) {
 		t.Errorf("merged_at changed: %v != %v", *pr2.MergedAt, firstMergedAt)
 	}
+
+	events, _ := mockRepos.eventRepo.ListByPR(ctx, "pr-1")
+	if len(events) != 1 || events[0].Type != domain.PREventMerged || events[0].ActorID != "u1" {
+		t.Errorf("expected one MERGED event by u1, got %+v", events)
+	}
 }
 
 func TestPRService_ReassignReviewer_MergedPR(t *testing.T) {
@@ -303,6 +569,9 @@ func TestPRService_ReassignReviewer_MergedPR(t *testing.T) {
 	}
 
 	repos := &repository.Repositories{}
+	repos.Constraint = mockRepos.constraintRepo
+	repos.Event = mockRepos.eventRepo
+	repos.Tx = mockRepos
 	repos.User = mockRepos.userRepo
 	repos.PR = mockRepos.prRepo
 
@@ -333,6 +602,9 @@ func TestPRService_ReassignReviewer_NotAssigned(t *testing.T) {
 	}
 
 	repos := &repository.Repositories{}
+	repos.Constraint = mockRepos.constraintRepo
+	repos.Event = mockRepos.eventRepo
+	repos.Tx = mockRepos
 	repos.PR = mockRepos.prRepo
 
 	service := NewPRService(repos)
@@ -376,6 +648,9 @@ func TestPRService_ReassignReviewer_NoCandidates(t *testing.T) {
 	}
 
 	repos := &repository.Repositories{}
+	repos.Constraint = mockRepos.constraintRepo
+	repos.Event = mockRepos.eventRepo
+	repos.Tx = mockRepos
 	repos.User = mockRepos.userRepo
 	repos.PR = mockRepos.prRepo
 
@@ -393,3 +668,100 @@ func TestPRService_ReassignReviewer_NoCandidates(t *testing.T) {
 		t.Errorf("expected ErrNoCandidate, got %v", err)
 	}
 }
+
+type inTxKey struct{}
+
+// trackingTx marks the context of a transaction so that repositories can tell.
+type trackingTx struct{}
+
+func (trackingTx) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, inTxKey{}, true))
+}
+
+// lockTrackingPRRepo records reads of pull requests that would not hold a row lock.
+type lockTrackingPRRepo struct {
+	*mockPRRepo
+	unlocked []string
+}
+
+func (m *lockTrackingPRRepo) GetByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	m.unlocked = append(m.unlocked, "GetByID")
+	return m.mockPRRepo.GetByID(ctx, prID)
+}
+
+func (m *lockTrackingPRRepo) GetByIDForUpdate(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	if ctx.Value(inTxKey{}) == nil {
+		m.unlocked = append(m.unlocked, "GetByIDForUpdate outside a transaction")
+	}
+	return m.mockPRRepo.GetByID(ctx, prID)
+}
+
+func TestPRService_ChangesLockPRInsideTransaction(t *testing.T) {
+	mockRepos := newMockRepos()
+	for _, id := range []string{"u1", "u2", "u3", "u4", "u5"} {
+		mockRepos.userRepo.users[id] = &domain.User{UserID: id, Username: id, TeamName: "backend", IsActive: true}
+	}
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Test",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+		CreatedAt:         time.Now(),
+	}
+	prRepo := &lockTrackingPRRepo{mockPRRepo: mockRepos.prRepo}
+
+	repos := &repository.Repositories{}
+	repos.Constraint = mockRepos.constraintRepo
+	repos.Event = mockRepos.eventRepo
+	repos.Tx = trackingTx{}
+	repos.User = mockRepos.userRepo
+	repos.PR = prRepo
+
+	service := NewPRService(repos)
+	ctx := context.Background()
+
+	changes := []struct {
+		name string
+		run  func() error
+	}{
+		{"SubmitReview", func() error {
+			_, err := service.SubmitReview(ctx, "pr-1", "u2", domain.ReviewCommented, "")
+			return err
+		}},
+		{"AddReviewer", func() error {
+			_, err := service.AddReviewer(ctx, "pr-1", "u3", "u1")
+			return err
+		}},
+		{"RemoveReviewer", func() error {
+			_, err := service.RemoveReviewer(ctx, "pr-1", "u3", "u1")
+			return err
+		}},
+		{"SetReviewers", func() error {
+			_, err := service.SetReviewers(ctx, "pr-1", []string{"u2", "u3"}, "u1")
+			return err
+		}},
+		{"ReassignReviewer", func() error {
+			_, _, err := service.ReassignReviewer(ctx, "pr-1", "u3")
+			return err
+		}},
+		{"DeclineReview", func() error {
+			_, _, err := service.DeclineReview(ctx, "pr-1", "u2", domain.DeclineReasonBusy, "")
+			return err
+		}},
+		{"MergePR", func() error {
+			_, err := service.MergePR(ctx, "pr-1")
+			return err
+		}},
+	}
+
+	for _, change := range changes {
+		prRepo.unlocked = nil
+		if err := change.run(); err != nil {
+			t.Fatalf("%s failed: %v", change.name, err)
+		}
+		if len(prRepo.unlocked) > 0 {
+			t.Errorf("%s read the pull request without locking it: %v", change.name, prRepo.unlocked)
+		}
+	}
+}
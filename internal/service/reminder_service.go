@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/notify"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+)
+
+// reviewRemindersLock keeps replicas from sending the same digests twice.
+const reviewRemindersLock = "review_reminders"
+
+type ReminderService interface {
+	GetPreferences(ctx context.Context, userID string) (*domain.ReminderPreferences, error)
+	UpdatePreferences(ctx context.Context, prefs *domain.ReminderPreferences) (*domain.ReminderPreferences, error)
+	SendReminders(ctx context.Context) (int, error)
+}
+
+type reminderService struct {
+	repos      *repository.Repositories
+	dispatcher *notify.Dispatcher
+	now        func() time.Time
+}
+
+func NewReminderService(repos *repository.Repositories, dispatcher *notify.Dispatcher) ReminderService {
+	return &reminderService{repos: repos, dispatcher: dispatcher, now: time.Now}
+}
+
+func (s *reminderService) GetPreferences(ctx context.Context, userID string) (*domain.ReminderPreferences, error) {
+	if _, err := s.repos.User.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repos.Reminder.GetPreferences(ctx, userID)
+}
+
+func (s *reminderService) UpdatePreferences(ctx context.Context, prefs *domain.ReminderPreferences) (*domain.ReminderPreferences, error) {
+	if err := prefs.Validate(); err != nil {
+		return nil, err
+	}
+	if !s.dispatcher.Has(prefs.Channel) {
+		return nil, domain.NewDomainError(domain.ErrCodeInvalidSettings, fmt.Sprintf("unknown notification channel %q", prefs.Channel))
+	}
+	if _, err := s.repos.User.GetByID(ctx, prefs.UserID); err != nil {
+		return nil, err
+	}
+	if err := s.repos.Reminder.UpsertPreferences(ctx, prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// SendReminders sends every reviewer a digest of the open pull requests waiting for them,
+// honouring their preferences: at most one digest per reminder frequency, and a pull
+// request is not repeated within it. The digests are built and recorded in one
+// transaction and sent after it commits, so that slow channels hold neither the lock nor
+// a connection. A failed delivery is forgotten again, so that it is retried on the next
+// run, and does not stop other users from being reminded. It returns how many digests
+// were sent.
+func (s *reminderService) SendReminders(ctx context.Context) (int, error) {
+	var pending []reminderDigest
+	now := s.now()
+	err := s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		locked, err := s.repos.Lock.TryLock(txCtx, reviewRemindersLock)
+		if err != nil || !locked {
+			return err
+		}
+
+		recipients, err := s.repos.Reminder.ListRecipients(txCtx)
+		if err != nil {
+			return err
+		}
+
+		settingsByTeam := make(map[string]*domain.TeamSettings)
+		for _, user := range recipients {
+			settings, ok := settingsByTeam[user.TeamName]
+			if !ok {
+				settings, err = s.repos.Settings.Get(txCtx, user.TeamName)
+				if err != nil {
+					return err
+				}
+				settingsByTeam[user.TeamName] = settings
+			}
+
+			channel, n, err := s.digest(txCtx, user, settings.Location(), now)
+			if err != nil {
+				return err
+			}
+			if n == nil {
+				continue
+			}
+
+			prIDs := make([]string, len(n.PullRequests))
+			for i, pr := range n.PullRequests {
+				prIDs[i] = pr.PullRequestID
+			}
+			if err := s.repos.Reminder.RecordReminders(txCtx, user.UserID, prIDs, now); err != nil {
+				return err
+			}
+			pending = append(pending, reminderDigest{channel: channel, notification: n, prIDs: prIDs})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var failures []error
+	for _, d := range pending {
+		userID := d.notification.Recipient.UserID
+		if err := s.dispatcher.Send(ctx, d.channel, d.notification); err != nil {
+			failures = append(failures, fmt.Errorf("remind %s: %w", userID, err))
+			if err := s.repos.Reminder.ForgetReminders(ctx, userID, d.prIDs, now); err != nil {
+				failures = append(failures, fmt.Errorf("forget failed reminder of %s: %w", userID, err))
+			}
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(failures...)
+}
+
+// reminderDigest is a digest recorded in reminder_log and waiting to be sent.
+type reminderDigest struct {
+	channel      domain.NotificationChannel
+	notification *notify.Notification
+	prIDs        []string
+}
+
+// digest builds the reminder for user, or returns a nil notification when the user
+// should not be reminded now.
+func (s *reminderService) digest(ctx context.Context, user *domain.User, loc *time.Location, now time.Time) (domain.NotificationChannel, *notify.Notification, error) {
+	prefs, err := s.repos.Reminder.GetPreferences(ctx, user.UserID)
+	if err != nil {
+		return "", nil, err
+	}
+	if !prefs.Enabled || prefs.IsQuiet(now, loc) {
+		return "", nil, nil
+	}
+
+	prs, err := s.repos.PR.ListByReviewer(ctx, user.UserID)
+	if err != nil {
+		return "", nil, err
+	}
+	last, err := s.repos.Reminder.LastReminded(ctx, user.UserID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !prefs.DigestDue(last, now) {
+		return "", nil, nil
+	}
+
+	pending := prefs.PendingForReminder(prs, last, now)
+	if len(pending) == 0 {
+		return "", nil, nil
+	}
+
+	return prefs.Channel, &notify.Notification{
+		Kind:         domain.NotificationReviewReminder,
+		Recipient:    user,
+		PullRequests: pending,
+	}, nil
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/notify"
+)
+
+type mockReminderRepo struct {
+	users map[string]*domain.User
+	prs   *mockPRRepo
+	prefs map[string]*domain.ReminderPreferences
+	log   map[string]map[string]time.Time
+}
+
+func (m *mockReminderRepo) GetPreferences(ctx context.Context, userID string) (*domain.ReminderPreferences, error) {
+	if p, ok := m.prefs[userID]; ok {
+		return p, nil
+	}
+	return domain.DefaultReminderPreferences(userID), nil
+}
+
+func (m *mockReminderRepo) UpsertPreferences(ctx context.Context, prefs *domain.ReminderPreferences) error {
+	m.prefs[prefs.UserID] = prefs
+	return nil
+}
+
+func (m *mockReminderRepo) ListRecipients(ctx context.Context) ([]*domain.User, error) {
+	var result []*domain.User
+	for _, user := range m.users {
+		if !user.IsActive {
+			continue
+		}
+		for _, pr := range m.prs.prs {
+			if !pr.IsMerged() && pr.HasReviewer(user.UserID) {
+				result = append(result, user)
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].UserID < result[j].UserID })
+	return result, nil
+}
+
+func (m *mockReminderRepo) LastReminded(ctx context.Context, userID string) (map[string]time.Time, error) {
+	result := make(map[string]time.Time)
+	for prID, at := range m.log[userID] {
+		result[prID] = at
+	}
+	return result, nil
+}
+
+func (m *mockReminderRepo) RecordReminders(ctx context.Context, userID string, prIDs []string, sentAt time.Time) error {
+	if m.log[userID] == nil {
+		m.log[userID] = make(map[string]time.Time)
+	}
+	for _, id := range prIDs {
+		m.log[userID][id] = sentAt
+	}
+	return nil
+}
+
+func (m *mockReminderRepo) ForgetReminders(ctx context.Context, userID string, prIDs []string, sentAt time.Time) error {
+	for _, id := range prIDs {
+		if m.log[userID][id].Equal(sentAt) {
+			delete(m.log[userID], id)
+		}
+	}
+	return nil
+}
+
+type recordingNotifier struct {
+	sent []*notify.Notification
+	fail map[string]bool
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, msg *notify.Notification) error {
+	if n.fail[msg.Recipient.UserID] {
+		return errors.New("delivery failed")
+	}
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+func TestReminderService_SendReminders(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	reminderRepo := &mockReminderRepo{
+		users: mockRepos.userRepo.users,
+		prs:   mockRepos.prRepo,
+		prefs: map[string]*domain.ReminderPreferences{},
+		log:   map[string]map[string]time.Time{},
+	}
+	repos.Reminder = reminderRepo
+	repos.Settings = &mockSettingsRepo{settings: map[string]*domain.TeamSettings{}}
+	repos.Lock = &mockLocker{}
+
+	for id, reviewers := range map[string][]string{"pr-1": {"u2", "u3"}, "pr-2": {"u2"}} {
+		mockRepos.prRepo.prs[id] = &domain.PullRequest{
+			PullRequestID:     id,
+			AuthorID:          "u1",
+			Status:            domain.PRStatusOpen,
+			AssignedReviewers: reviewers,
+		}
+	}
+	mockRepos.prRepo.prs["pr-3"] = &domain.PullRequest{
+		PullRequestID:     "pr-3",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusMerged,
+		AssignedReviewers: []string{"u3"},
+	}
+
+	quiet := domain.DefaultReminderPreferences("u3")
+	quiet.QuietStart, quiet.QuietEnd = 22*60, 8*60
+	reminderRepo.prefs["u3"] = quiet
+
+	notifier := &recordingNotifier{}
+	dispatcher := notify.NewDispatcher()
+	dispatcher.Register(domain.ChannelLog, notifier)
+
+	now := time.Date(2025, 3, 3, 23, 0, 0, 0, time.UTC)
+	service := NewReminderService(repos, dispatcher).(*reminderService)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	count, err := service.SendReminders(ctx)
+	if err != nil {
+		t.Fatalf("SendReminders failed: %v", err)
+	}
+	if count != 1 || len(notifier.sent) != 1 {
+		t.Fatalf("expected one digest for u2 during u3's quiet hours, got %d", count)
+	}
+	if got := notifier.sent[0]; got.Recipient.UserID != "u2" || len(got.PullRequests) != 2 {
+		t.Errorf("expected u2 to be reminded of two pull requests, got %s with %d", got.Recipient.UserID, len(got.PullRequests))
+	}
+
+	// Next morning u2 has already had a digest today, even though pr-4 is new, and u3
+	// gets the open pull request only.
+	mockRepos.prRepo.prs["pr-4"] = &domain.PullRequest{
+		PullRequestID:     "pr-4",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+	now = now.Add(10 * time.Hour)
+	notifier.sent = nil
+	if count, err = service.SendReminders(ctx); err != nil || count != 1 {
+		t.Fatalf("expected one digest, got %d (err %v)", count, err)
+	}
+	if got := notifier.sent[0]; got.Recipient.UserID != "u3" || len(got.PullRequests) != 1 || got.PullRequests[0].PullRequestID != "pr-1" {
+		t.Errorf("expected u3 to be reminded of pr-1 only, got %+v", got)
+	}
+
+	// A day later both are due again; a failed delivery is not recorded.
+	now = now.Add(24 * time.Hour)
+	notifier.sent = nil
+	notifier.fail = map[string]bool{"u2": true}
+	count, err = service.SendReminders(ctx)
+	if err == nil {
+		t.Fatal("expected delivery error to be reported")
+	}
+	if count != 1 {
+		t.Errorf("expected u3 to be reminded despite u2's failure, got %d", count)
+	}
+	if at := reminderRepo.log["u2"]["pr-1"]; !at.Before(now) {
+		t.Errorf("failed reminder should not be recorded")
+	}
+}
+
+func TestReminderService_UpdatePreferences_UnknownChannel(t *testing.T) {
+	_, repos := newConstraintTestRepos()
+	repos.Reminder = &mockReminderRepo{prefs: map[string]*domain.ReminderPreferences{}}
+
+	service := NewReminderService(repos, notify.NewDispatcher())
+
+	prefs := domain.DefaultReminderPreferences("u1")
+	prefs.Channel = "pigeon"
+	_, err := service.UpdatePreferences(context.Background(), prefs)
+
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrCodeInvalidSettings {
+		t.Errorf("expected INVALID_SETTINGS, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mivihan/Pull_Request_service/internal/auth"
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+func TestPRService_SubmitReview_RecordsEvent(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Test",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+	service := NewPRService(repos)
+
+	event, err := service.SubmitReview(context.Background(), "pr-1", "u2", domain.ReviewApproved, "lgtm")
+	if err != nil {
+		t.Fatalf("SubmitReview failed: %v", err)
+	}
+
+	if event.Type != domain.PREventReviewSubmitted || event.UserID != "u2" || event.Reason != string(domain.ReviewApproved) {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if len(mockRepos.eventRepo.events) != 1 {
+		t.Errorf("expected 1 recorded event, got %d", len(mockRepos.eventRepo.events))
+	}
+}
+
+func TestPRService_SubmitReview_Rejections(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   domain.PRStatus
+		reviewer string
+		expected error
+	}{
+		{name: "not assigned", status: domain.PRStatusOpen, reviewer: "u3", expected: domain.ErrNotAssigned},
+		{name: "merged", status: domain.PRStatusMerged, reviewer: "u2", expected: domain.ErrPRMerged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepos, repos := newConstraintTestRepos()
+			mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+				PullRequestID:     "pr-1",
+				PullRequestName:   "Test",
+				AuthorID:          "u1",
+				Status:            tt.status,
+				AssignedReviewers: []string{"u2"},
+			}
+			service := NewPRService(repos)
+
+			_, err := service.SubmitReview(context.Background(), "pr-1", tt.reviewer, domain.ReviewCommented, "")
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+			if len(mockRepos.eventRepo.events) != 0 {
+				t.Error("no event should be recorded")
+			}
+		})
+	}
+}
+
+func TestPRService_SubmitReview_SignedInCallerIsReviewer(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Test",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+	service := NewPRService(repos)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "jwt:u3", UserID: "u3"})
+
+	_, err := service.SubmitReview(ctx, "pr-1", "u2", domain.ReviewApproved, "")
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrCodeForbidden {
+		t.Fatalf("expected FORBIDDEN when reviewing for another user, got %v", err)
+	}
+
+	event, err := service.SubmitReview(ctx, "pr-1", "", domain.ReviewApproved, "")
+	if err != nil {
+		t.Fatalf("SubmitReview failed: %v", err)
+	}
+	if event.UserID != "u3" || event.ActorID != "u3" {
+		t.Errorf("expected the caller to be recorded as reviewer, got %+v", event)
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mivihan/Pull_Request_service/internal/auth"
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+func newOverrideTestPR(mockRepos *mockRepos, reviewers ...string) {
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Test",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: reviewers,
+	}
+}
+
+func TestPRService_AddReviewer_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		reviewers []string
+		userID    string
+		setup     func(m *mockRepos)
+		wantErr   error
+	}{
+		{
+			name:    "author",
+			userID:  "u1",
+			wantErr: domain.ErrReviewerIsAuthor,
+		},
+		{
+			name:      "already assigned",
+			reviewers: []string{"u2"},
+			userID:    "u2",
+			wantErr:   domain.ErrAlreadyAssigned,
+		},
+		{
+			name:      "too many reviewers",
+			reviewers: []string{"u2", "u3"},
+			userID:    "u4",
+			wantErr:   domain.ErrTooManyReviewers,
+		},
+		{
+			name:   "inactive",
+			userID: "u2",
+			setup: func(m *mockRepos) {
+				m.userRepo.users["u2"].IsActive = false
+			},
+			wantErr: domain.ErrReviewerInactive,
+		},
+		{
+			name:   "other team",
+			userID: "u9",
+			setup: func(m *mockRepos) {
+				m.userRepo.users["u9"] = &domain.User{UserID: "u9", Username: "u9", TeamName: "frontend", IsActive: true}
+			},
+			wantErr: domain.ErrReviewerOtherTeam,
+		},
+		{
+			name:   "excluded pair",
+			userID: "u2",
+			setup: func(m *mockRepos) {
+				m.constraintRepo.pairs = []*domain.ExclusionPair{domain.NewExclusionPair("backend", "u1", "u2")}
+			},
+			wantErr: domain.ErrReviewerNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepos, repos := newConstraintTestRepos()
+			newOverrideTestPR(mockRepos, tt.reviewers...)
+			if tt.setup != nil {
+				tt.setup(mockRepos)
+			}
+			service := NewPRService(repos)
+
+			_, err := service.AddReviewer(context.Background(), "pr-1", tt.userID, "lead")
+			if err != tt.wantErr {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPRService_SetReviewers_RecordsActor(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	newOverrideTestPR(mockRepos, "u2")
+	service := NewPRService(repos)
+
+	pr, err := service.SetReviewers(context.Background(), "pr-1", []string{"u3"}, "lead")
+	if err != nil {
+		t.Fatalf("SetReviewers failed: %v", err)
+	}
+
+	if len(pr.AssignedReviewers) != 1 || pr.AssignedReviewers[0] != "u3" {
+		t.Errorf("expected reviewers [u3], got %v", pr.AssignedReviewers)
+	}
+
+	stored := mockRepos.prRepo.prs["pr-1"].AssignedReviewers
+	if len(stored) != 1 || stored[0] != "u3" {
+		t.Errorf("expected stored reviewers [u3], got %v", stored)
+	}
+
+	events := mockRepos.eventRepo.events
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Type != domain.PREventReviewerRemoved || events[0].UserID != "u2" {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	if events[1].Type != domain.PREventReviewerAssigned || events[1].UserID != "u3" {
+		t.Errorf("unexpected second event: %+v", events[1])
+	}
+	for _, e := range events {
+		if e.ActorID != "lead" || e.Reason != domain.ManualReason {
+			t.Errorf("event should record actor and manual reason: %+v", e)
+		}
+	}
+}
+
+func TestPRService_SetReviewers_MergedPR(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	newOverrideTestPR(mockRepos, "u2")
+	mockRepos.prRepo.prs["pr-1"].Status = domain.PRStatusMerged
+	service := NewPRService(repos)
+
+	_, err := service.SetReviewers(context.Background(), "pr-1", []string{"u3"}, "lead")
+	if err != domain.ErrPRMerged {
+		t.Errorf("expected ErrPRMerged, got %v", err)
+	}
+}
+
+func TestPRService_AddReviewer_SignedInCallerIsActor(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	newOverrideTestPR(mockRepos)
+	service := NewPRService(repos)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "jwt:lead", UserID: "lead"})
+
+	_, err := service.AddReviewer(ctx, "pr-1", "u2", "someone-else")
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrCodeForbidden {
+		t.Fatalf("expected FORBIDDEN when acting for another user, got %v", err)
+	}
+
+	if _, err := service.AddReviewer(ctx, "pr-1", "u2", ""); err != nil {
+		t.Fatalf("AddReviewer failed: %v", err)
+	}
+	events := mockRepos.eventRepo.events
+	if len(events) != 1 || events[0].ActorID != "lead" {
+		t.Errorf("expected the caller to be recorded as actor, got %+v", events)
+	}
+}
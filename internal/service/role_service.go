@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+)
+
+type RoleService interface {
+	ListRoles(ctx context.Context, teamName string) ([]*domain.RoleAssignment, error)
+	GetUserRoles(ctx context.Context, userID string) ([]*domain.RoleAssignment, error)
+	SetRole(ctx context.Context, assignment *domain.RoleAssignment) (*domain.RoleAssignment, error)
+	RemoveRole(ctx context.Context, userID, teamName string) error
+}
+
+type roleService struct {
+	repos *repository.Repositories
+}
+
+func NewRoleService(repos *repository.Repositories) RoleService {
+	return &roleService{repos: repos}
+}
+
+func (s *roleService) ListRoles(ctx context.Context, teamName string) ([]*domain.RoleAssignment, error) {
+	return s.repos.Role.ListByTeam(ctx, teamName)
+}
+
+func (s *roleService) GetUserRoles(ctx context.Context, userID string) ([]*domain.RoleAssignment, error) {
+	return s.repos.Role.ListByUser(ctx, userID)
+}
+
+// SetRole grants a role, replacing the user's previous role in the same team.
+func (s *roleService) SetRole(ctx context.Context, assignment *domain.RoleAssignment) (*domain.RoleAssignment, error) {
+	if err := assignment.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repos.User.GetByID(ctx, assignment.UserID); err != nil {
+		return nil, err
+	}
+	if assignment.TeamName != "" {
+		exists, err := s.repos.Team.Exists(ctx, assignment.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, domain.ErrTeamNotFound
+		}
+	}
+
+	if err := s.repos.Role.Set(ctx, assignment); err != nil {
+		return nil, err
+	}
+
+	return assignment, nil
+}
+
+func (s *roleService) RemoveRole(ctx context.Context, userID, teamName string) error {
+	return s.repos.Role.Delete(ctx, userID, teamName)
+}
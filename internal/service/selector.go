@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+)
+
+const (
+	SelectionModeRandom        = "random"
+	SelectionModeDeterministic = "deterministic"
+	SelectionModeRoundRobin    = "round_robin"
+)
+
+// SelectionKey describes what reviewers are being selected for.
+type SelectionKey struct {
+	PRID     string
+	TeamName string
+}
+
+// ReviewerSelector picks up to count reviewers from candidates. Implementations must be
+// safe for concurrent use because a single selector is shared by all handler goroutines.
+type ReviewerSelector interface {
+	Select(ctx context.Context, key SelectionKey, candidates []*domain.User, count int) ([]*domain.User, error)
+}
+
+// NewSelector builds the selector for a configured mode.
+func NewSelector(mode string, src rand.Source, repos *repository.Repositories) (ReviewerSelector, error) {
+	switch mode {
+	case "", SelectionModeRandom:
+		return NewRandomSelector(src), nil
+	case SelectionModeDeterministic:
+		return NewDeterministicSelector(), nil
+	case SelectionModeRoundRobin:
+		return NewRoundRobinSelector(repos), nil
+	default:
+		return nil, fmt.Errorf("unknown selection mode: %s", mode)
+	}
+}
+
+type randomSelector struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewRandomSelector shuffles candidates using src. Passing a seeded source makes the
+// sequence of selections reproducible.
+func NewRandomSelector(src rand.Source) ReviewerSelector {
+	return &randomSelector{rand: rand.New(src)}
+}
+
+func (s *randomSelector) Select(ctx context.Context, key SelectionKey, candidates []*domain.User, count int) ([]*domain.User, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	shuffled := make([]*domain.User, len(candidates))
+	copy(shuffled, candidates)
+
+	s.mu.Lock()
+	s.rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	s.mu.Unlock()
+
+	return shuffled[:min(count, len(shuffled))], nil
+}
+
+type deterministicSelector struct{}
+
+// NewDeterministicSelector ranks candidates by a hash of the PR ID, the candidate set
+// and the candidate itself, so the same input always yields the same reviewers
+// regardless of the order candidates were loaded in.
+func NewDeterministicSelector() ReviewerSelector {
+	return deterministicSelector{}
+}
+
+func (deterministicSelector) Select(ctx context.Context, key SelectionKey, candidates []*domain.User, count int) ([]*domain.User, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	ids := extractUserIDs(candidates)
+	sort.Strings(ids)
+	setDigest := sha256.Sum256([]byte(strings.Join(ids, "\x00")))
+
+	type ranked struct {
+		user *domain.User
+		rank uint64
+	}
+	ranking := make([]ranked, len(candidates))
+	for i, user := range candidates {
+		h := sha256.New()
+		h.Write([]byte(key.PRID))
+		h.Write([]byte{0})
+		h.Write(setDigest[:])
+		h.Write([]byte(user.UserID))
+		ranking[i] = ranked{user: user, rank: binary.BigEndian.Uint64(h.Sum(nil)[:8])}
+	}
+
+	sort.Slice(ranking, func(i, j int) bool {
+		if ranking[i].rank != ranking[j].rank {
+			return ranking[i].rank < ranking[j].rank
+		}
+		return ranking[i].user.UserID < ranking[j].user.UserID
+	})
+
+	selected := make([]*domain.User, min(count, len(ranking)))
+	for i := range selected {
+		selected[i] = ranking[i].user
+	}
+	return selected, nil
+}
+
+type roundRobinSelector struct {
+	repos *repository.Repositories
+}
+
+// NewRoundRobinSelector walks each team's members in user_id order, continuing from a
+// cursor persisted per team. The cursor row is locked for the rest of the caller's
+// transaction, so concurrent selections for the same team on any replica are serialized.
+func NewRoundRobinSelector(repos *repository.Repositories) ReviewerSelector {
+	return &roundRobinSelector{repos: repos}
+}
+
+func (s *roundRobinSelector) Select(ctx context.Context, key SelectionKey, candidates []*domain.User, count int) ([]*domain.User, error) {
+	if len(candidates) == 0 || count <= 0 {
+		return nil, nil
+	}
+
+	var selected []*domain.User
+	err := s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		cursor, err := s.repos.Rotation.LockCursor(txCtx, key.TeamName)
+		if err != nil {
+			return err
+		}
+
+		selected = nextInRotation(candidates, cursor, count)
+
+		return s.repos.Rotation.SetCursor(txCtx, key.TeamName, selected[len(selected)-1].UserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return selected, nil
+}
+
+// nextInRotation returns up to count candidates that follow cursor in user_id order,
+// wrapping around. Users missing from candidates (inactive, excluded, already
+// reviewing) are skipped without losing their place in the rotation.
+func nextInRotation(candidates []*domain.User, cursor string, count int) []*domain.User {
+	sorted := make([]*domain.User, len(candidates))
+	copy(sorted, candidates)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].UserID < sorted[j].UserID
+	})
+
+	start := sort.Search(len(sorted), func(i int) bool {
+		return sorted[i].UserID > cursor
+	})
+
+	n := min(count, len(sorted))
+	selected := make([]*domain.User, n)
+	for i := 0; i < n; i++ {
+		selected[i] = sorted[(start+i)%len(sorted)]
+	}
+	return selected
+}
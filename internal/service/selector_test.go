@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+func makeCandidates(ids ...string) []*domain.User {
+	users := make([]*domain.User, len(ids))
+	for i, id := range ids {
+		users[i] = &domain.User{UserID: id, TeamName: "backend", IsActive: true}
+	}
+	return users
+}
+
+func TestDeterministicSelector_Reproducible(t *testing.T) {
+	selector := NewDeterministicSelector()
+	ctx := context.Background()
+	key := SelectionKey{PRID: "pr-42", TeamName: "backend"}
+
+	first, err := selector.Select(ctx, key, makeCandidates("u1", "u2", "u3", "u4", "u5"), 2)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+
+	second, err := selector.Select(ctx, key, makeCandidates("u5", "u3", "u1", "u4", "u2"), 2)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+
+	if len(first) != 2 || len(second) != 2 {
+		t.Fatalf("expected 2 reviewers, got %d and %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i].UserID != second[i].UserID {
+			t.Errorf("selection depends on candidate order: %v vs %v", extractUserIDs(first), extractUserIDs(second))
+		}
+	}
+}
+
+func TestDeterministicSelector_DependsOnPR(t *testing.T) {
+	selector := NewDeterministicSelector()
+	ctx := context.Background()
+	candidates := makeCandidates("u1", "u2", "u3", "u4", "u5", "u6", "u7", "u8")
+
+	seen := make(map[string]bool)
+	for _, prID := range []string{"pr-1", "pr-2", "pr-3", "pr-4", "pr-5", "pr-6"} {
+		selected, err := selector.Select(ctx, SelectionKey{PRID: prID}, candidates, 1)
+		if err != nil {
+			t.Fatalf("Select failed: %v", err)
+		}
+		seen[selected[0].UserID] = true
+	}
+
+	if len(seen) < 2 {
+		t.Errorf("expected different PRs to spread across reviewers, got %v", seen)
+	}
+}
+
+func TestRandomSelector_SeededIsReproducible(t *testing.T) {
+	ctx := context.Background()
+	candidates := makeCandidates("u1", "u2", "u3", "u4", "u5")
+
+	a := NewRandomSelector(rand.NewSource(7))
+	b := NewRandomSelector(rand.NewSource(7))
+
+	for i := 0; i < 5; i++ {
+		ra, _ := a.Select(ctx, SelectionKey{}, candidates, 2)
+		rb, _ := b.Select(ctx, SelectionKey{}, candidates, 2)
+		for j := range ra {
+			if ra[j].UserID != rb[j].UserID {
+				t.Fatalf("round %d: seeded selectors diverged: %v vs %v", i, extractUserIDs(ra), extractUserIDs(rb))
+			}
+		}
+	}
+}
+
+func TestRandomSelector_ConcurrentUse(t *testing.T) {
+	selector := NewRandomSelector(rand.NewSource(1))
+	candidates := makeCandidates("u1", "u2", "u3")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			selected, err := selector.Select(context.Background(), SelectionKey{}, candidates, 2)
+			if err != nil || len(selected) != 2 {
+				t.Errorf("unexpected selection: %v, %v", selected, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPRService_CreatePR_DeterministicMode(t *testing.T) {
+	ctx := context.Background()
+
+	var results [][]string
+	for i := 0; i < 2; i++ {
+		_, repos := newConstraintTestRepos()
+		service := NewPRService(repos, WithSelector(NewDeterministicSelector()))
+
+		pr, err := service.CreatePR(ctx, "pr-1", "Test PR", "u1", "")
+		if err != nil {
+			t.Fatalf("CreatePR failed: %v", err)
+		}
+		results = append(results, pr.AssignedReviewers)
+	}
+
+	if len(results[0]) != len(results[1]) {
+		t.Fatalf("reviewer counts differ: %v vs %v", results[0], results[1])
+	}
+	for i := range results[0] {
+		if results[0][i] != results[1][i] {
+			t.Errorf("replay produced different reviewers: %v vs %v", results[0], results[1])
+		}
+	}
+}
+
+type mockRotationRepo struct {
+	cursors map[string]string
+}
+
+func (m *mockRotationRepo) LockCursor(ctx context.Context, teamName string) (string, error) {
+	return m.cursors[teamName], nil
+}
+
+func (m *mockRotationRepo) SetCursor(ctx context.Context, teamName, userID string) error {
+	m.cursors[teamName] = userID
+	return nil
+}
+
+func TestNextInRotation(t *testing.T) {
+	candidates := makeCandidates("u3", "u1", "u4", "u2")
+
+	tests := []struct {
+		name     string
+		cursor   string
+		count    int
+		expected []string
+	}{
+		{name: "empty cursor starts at beginning", cursor: "", count: 2, expected: []string{"u1", "u2"}},
+		{name: "continues after cursor", cursor: "u2", count: 2, expected: []string{"u3", "u4"}},
+		{name: "wraps around", cursor: "u4", count: 2, expected: []string{"u1", "u2"}},
+		{name: "skips missing cursor user", cursor: "u2a", count: 1, expected: []string{"u3"}},
+		{name: "count larger than candidates", cursor: "u3", count: 10, expected: []string{"u4", "u1", "u2", "u3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractUserIDs(nextInRotation(candidates, tt.cursor, tt.count))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("nextInRotation() = %v, want %v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("nextInRotation() = %v, want %v", got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundRobinSelector_IsFair(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	repos.Rotation = &mockRotationRepo{cursors: make(map[string]string)}
+	for _, id := range []string{"u4", "u5"} {
+		mockRepos.userRepo.users[id] = &domain.User{UserID: id, Username: id, TeamName: "backend", IsActive: true}
+	}
+
+	service := NewPRService(repos, WithSelector(NewRoundRobinSelector(repos)))
+	ctx := context.Background()
+
+	counts := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		pr, err := service.CreatePR(ctx, "pr-"+string(rune('a'+i)), "Test", "u1", "")
+		if err != nil {
+			t.Fatalf("CreatePR failed: %v", err)
+		}
+		for _, id := range pr.AssignedReviewers {
+			counts[id]++
+		}
+	}
+
+	for _, id := range []string{"u2", "u3", "u4", "u5"} {
+		if counts[id] != 4 {
+			t.Errorf("expected 4 assignments for %s, got %d (%v)", id, counts[id], counts)
+		}
+	}
+}
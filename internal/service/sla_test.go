@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+type mockSettingsRepo struct {
+	settings map[string]*domain.TeamSettings
+}
+
+func (m *mockSettingsRepo) Get(ctx context.Context, teamName string) (*domain.TeamSettings, error) {
+	if s, ok := m.settings[teamName]; ok {
+		return s, nil
+	}
+	return domain.DefaultTeamSettings(teamName), nil
+}
+
+func (m *mockSettingsRepo) Upsert(ctx context.Context, settings *domain.TeamSettings) error {
+	m.settings[settings.TeamName] = settings
+	return nil
+}
+
+func (m *mockSettingsRepo) ListWithStaleTimeout(ctx context.Context) ([]*domain.TeamSettings, error) {
+	var result []*domain.TeamSettings
+	for _, s := range m.settings {
+		if s.HasStaleTimeout() {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockSettingsRepo) ListWithSLA(ctx context.Context) ([]*domain.TeamSettings, error) {
+	var result []*domain.TeamSettings
+	for _, s := range m.settings {
+		if s.HasSLA() {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func TestUserService_GetReviews_MarksOverdue(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	settingsRepo := &mockSettingsRepo{settings: map[string]*domain.TeamSettings{}}
+	repos.Settings = settingsRepo
+	ctx := context.Background()
+
+	for _, id := range []string{"pr-old", "pr-new"} {
+		mockRepos.prRepo.prs[id] = &domain.PullRequest{
+			PullRequestID:     id,
+			PullRequestName:   id,
+			AuthorID:          "u1",
+			Status:            domain.PRStatusOpen,
+			AssignedReviewers: []string{"u2"},
+		}
+	}
+
+	old := domain.NewPREvent("pr-old", domain.PREventReviewerAssigned)
+	old.UserID = "u2"
+	old.CreatedAt = time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	fresh := domain.NewPREvent("pr-new", domain.PREventReviewerAssigned)
+	fresh.UserID = "u2"
+	mockRepos.eventRepo.events = []*domain.PREvent{old, fresh}
+
+	service := NewUserService(repos)
+
+	reviews, err := service.GetReviews(ctx, "u2")
+	if err != nil {
+		t.Fatalf("GetReviews failed: %v", err)
+	}
+	for _, r := range reviews {
+		if r.Overdue || r.Deadline != nil {
+			t.Errorf("without SLA nothing should be tracked: %+v", r)
+		}
+	}
+
+	withSLA := domain.DefaultTeamSettings("backend")
+	withSLA.ReviewSLA = 2 * time.Hour
+	settingsRepo.settings["backend"] = withSLA
+
+	reviews, err = service.GetReviews(ctx, "u2")
+	if err != nil {
+		t.Fatalf("GetReviews failed: %v", err)
+	}
+	if len(reviews) != 2 {
+		t.Fatalf("expected 2 reviews, got %d", len(reviews))
+	}
+	for _, r := range reviews {
+		if r.Deadline == nil {
+			t.Errorf("%s should have a deadline", r.PullRequestID)
+		}
+		expected := r.PullRequestID == "pr-old"
+		if r.Overdue != expected {
+			t.Errorf("%s: expected overdue=%v, got %v", r.PullRequestID, expected, r.Overdue)
+		}
+	}
+}
+
+func TestTeamService_UpdateSettings(t *testing.T) {
+	_, repos := newConstraintTestRepos()
+	repos.Settings = &mockSettingsRepo{settings: map[string]*domain.TeamSettings{}}
+	service := NewTeamService(repos)
+	ctx := context.Background()
+
+	settings := domain.DefaultTeamSettings("backend")
+	settings.ReviewSLA = 8 * time.Hour
+	if _, err := service.UpdateSettings(ctx, settings); err != nil {
+		t.Fatalf("UpdateSettings failed: %v", err)
+	}
+
+	got, err := service.GetSettings(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+	if got.ReviewSLA != 8*time.Hour {
+		t.Errorf("expected 8h SLA, got %v", got.ReviewSLA)
+	}
+
+	invalid := domain.DefaultTeamSettings("backend")
+	invalid.Timezone = "Nowhere/Invalid"
+	_, err = service.UpdateSettings(ctx, invalid)
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrCodeInvalidSettings {
+		t.Errorf("expected INVALID_SETTINGS, got %v", err)
+	}
+
+	if _, err := service.UpdateSettings(ctx, domain.DefaultTeamSettings("missing")); !errors.Is(err, domain.ErrTeamNotFound) {
+		t.Errorf("expected team not found, got %v", err)
+	}
+}
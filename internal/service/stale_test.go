@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+type mockLocker struct {
+	held bool
+}
+
+func (m *mockLocker) TryLock(ctx context.Context, name string) (bool, error) {
+	return !m.held, nil
+}
+
+func TestPRService_ReassignStaleReviews(t *testing.T) {
+	mockRepos, repos := newConstraintTestRepos()
+	mockRepos.userRepo.users["u4"] = &domain.User{UserID: "u4", Username: "u4", TeamName: "backend", IsActive: true}
+
+	settings := domain.DefaultTeamSettings("backend")
+	settings.StaleTimeout = time.Hour
+	repos.Settings = &mockSettingsRepo{settings: map[string]*domain.TeamSettings{"backend": settings}}
+	locker := &mockLocker{}
+	repos.Lock = locker
+
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Test",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+
+	// Monday morning, long past any one-hour working deadline.
+	assignedAt := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	for _, id := range []string{"u2", "u3"} {
+		e := domain.NewPREvent("pr-1", domain.PREventReviewerAssigned)
+		e.UserID = id
+		e.CreatedAt = assignedAt
+		mockRepos.eventRepo.Record(context.Background(), e)
+	}
+	review := domain.NewPREvent("pr-1", domain.PREventReviewSubmitted)
+	review.UserID = "u3"
+	review.CreatedAt = assignedAt.Add(30 * time.Minute)
+	mockRepos.eventRepo.Record(context.Background(), review)
+
+	service := NewPRService(repos, WithStaleReassignmentCap(1))
+
+	locker.held = true
+	count, err := service.ReassignStaleReviews(context.Background())
+	if err != nil {
+		t.Fatalf("ReassignStaleReviews failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("another replica holds the lock, expected no reassignment, got %d", count)
+	}
+
+	locker.held = false
+	count, err = service.ReassignStaleReviews(context.Background())
+	if err != nil {
+		t.Fatalf("ReassignStaleReviews failed: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 reassignment, got %d", count)
+	}
+
+	pr := mockRepos.prRepo.prs["pr-1"]
+	if pr.HasReviewer("u2") || !pr.HasReviewer("u4") || !pr.HasReviewer("u3") {
+		t.Errorf("expected u2 to be replaced by u4, got %v", pr.AssignedReviewers)
+	}
+
+	last := mockRepos.eventRepo.events[len(mockRepos.eventRepo.events)-1]
+	if last.Type != domain.PREventReviewerReassigned || last.Reason != domain.StaleReason || last.UserID != "u2" {
+		t.Errorf("unexpected event: %+v", last)
+	}
+
+	// u4 was just assigned, and the cap of one automatic reassignment is used up anyway.
+	count, err = service.ReassignStaleReviews(context.Background())
+	if err != nil {
+		t.Fatalf("ReassignStaleReviews failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("cap reached, expected no reassignment, got %d", count)
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+)
+
+type StatsService interface {
+	GetFairness(ctx context.Context, teamName string, from, to time.Time) (*domain.FairnessReport, error)
+	GetReviewerSeries(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerStatPoint, error)
+	GetPRSeries(ctx context.Context, filter domain.StatsFilter) ([]domain.PRStatPoint, error)
+	GetCycleTime(ctx context.Context, filter domain.StatsFilter, repository string, by domain.CycleTimeDimension) (*domain.CycleTimeReport, error)
+	GetPRCycleTime(ctx context.Context, prID string) (*domain.PRCycleTime, error)
+	GetSLAReport(ctx context.Context, filter domain.StatsFilter) (*domain.SLAReport, error)
+}
+
+type statsService struct {
+	repos *repository.Repositories
+}
+
+func NewStatsService(repos *repository.Repositories) StatsService {
+	return &statsService{repos: repos}
+}
+
+func (s *statsService) GetFairness(ctx context.Context, teamName string, from, to time.Time) (*domain.FairnessReport, error) {
+	if _, err := s.repos.Team.GetByName(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repos.User.ListByTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.repos.Stats.ListActivityChanges(ctx, teamName, to)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.repos.Stats.CountAssignmentsByTeam(ctx, teamName, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changesByUser := make(map[string][]domain.ActivityChange)
+	for _, c := range changes {
+		changesByUser[c.UserID] = append(changesByUser[c.UserID], c)
+	}
+
+	loads := make([]domain.MemberLoad, len(members))
+	for i, m := range members {
+		active := domain.ActiveDuration(changesByUser[m.UserID], from, to)
+		loads[i] = domain.MemberLoad{
+			UserID:      m.UserID,
+			ActiveDays:  active.Hours() / 24,
+			Assignments: assignments[m.UserID],
+		}
+	}
+
+	return domain.ComputeFairness(loads), nil
+}
+
+func (s *statsService) GetReviewerSeries(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerStatPoint, error) {
+	if err := checkStatsTeam(ctx, s.repos, filter); err != nil {
+		return nil, err
+	}
+	return s.repos.Stats.GetReviewerSeries(ctx, filter)
+}
+
+func (s *statsService) GetPRSeries(ctx context.Context, filter domain.StatsFilter) ([]domain.PRStatPoint, error) {
+	if err := checkStatsTeam(ctx, s.repos, filter); err != nil {
+		return nil, err
+	}
+	return s.repos.Stats.GetPRSeries(ctx, filter)
+}
+
+func (s *statsService) GetCycleTime(
+	ctx context.Context,
+	filter domain.StatsFilter,
+	repository string,
+	by domain.CycleTimeDimension,
+) (*domain.CycleTimeReport, error) {
+	if err := checkStatsTeam(ctx, s.repos, filter); err != nil {
+		return nil, err
+	}
+
+	cycles, err := s.repos.Stats.ListPRCycleTimes(ctx, filter, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.SummarizeCycleTimes(cycles, by), nil
+}
+
+func (s *statsService) GetPRCycleTime(ctx context.Context, prID string) (*domain.PRCycleTime, error) {
+	pr, err := s.repos.PR.GetByID(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := s.repos.User.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.repos.Event.ListByPR(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewPRCycleTime(pr, author.TeamName, events), nil
+}
+
+// GetSLAReport evaluates assignments made within the filter window against the SLA of
+// the reviewer's team. Teams without an SLA are left out.
+func (s *statsService) GetSLAReport(ctx context.Context, filter domain.StatsFilter) (*domain.SLAReport, error) {
+	if err := checkStatsTeam(ctx, s.repos, filter); err != nil {
+		return nil, err
+	}
+
+	teams, err := s.repos.Settings.ListWithSLA(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	settingsByUser := make(map[string]*domain.TeamSettings)
+	teamOf := make(map[string]string)
+	for _, settings := range teams {
+		if filter.TeamName != "" && settings.TeamName != filter.TeamName {
+			continue
+		}
+		members, err := s.repos.User.ListByTeam(ctx, settings.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			settingsByUser[m.UserID] = settings
+			teamOf[m.UserID] = settings.TeamName
+		}
+	}
+
+	events, err := s.repos.Stats.ListAssignmentEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := func(reviewerID string, assignedAt time.Time) (time.Time, bool) {
+		settings, ok := settingsByUser[reviewerID]
+		if !ok {
+			return time.Time{}, false
+		}
+		return settings.ReviewDeadline(assignedAt)
+	}
+	now := time.Now()
+
+	var assignments []*domain.AssignmentSLA
+	for start := 0; start < len(events); {
+		end := start
+		for end < len(events) && events[end].PRID == events[start].PRID {
+			end++
+		}
+		for _, a := range domain.EvaluateAssignmentSLA(events[start:end], deadline, now) {
+			if a.AssignedAt.Before(filter.From) || !a.AssignedAt.Before(filter.To) {
+				continue
+			}
+			assignments = append(assignments, a)
+		}
+		start = end
+	}
+
+	return domain.BuildSLAReport(assignments, teamOf), nil
+}
+
+// checkStatsTeam makes filtering by an unknown team a NOT_FOUND rather than empty stats.
+func checkStatsTeam(ctx context.Context, repos *repository.Repositories, filter domain.StatsFilter) error {
+	if filter.TeamName == "" {
+		return nil
+	}
+	_, err := repos.Team.GetByName(ctx, filter.TeamName)
+	return err
+}
@@ -17,6 +17,7 @@ type TeamMemberInput struct {
 type TeamWithMembers struct {
 	TeamName string
 	Members  []*domain.User
+	Version  int64
 }
 
 type DeactivationResult struct {
@@ -25,33 +26,57 @@ type DeactivationResult struct {
 	AffectedPRCount  int
 }
 
+// deactivationReason marks timeline events caused by bulk deactivation.
+const deactivationReason = "DEACTIVATED"
+
 type TeamService interface {
 	CreateTeam(ctx context.Context, teamName string, members []TeamMemberInput) (*TeamWithMembers, error)
 	GetTeam(ctx context.Context, teamName string) (*TeamWithMembers, error)
 	DeactivateTeamUsers(ctx context.Context, teamName string, userIDs []string) (*DeactivationResult, error)
+	GetSettings(ctx context.Context, teamName string) (*domain.TeamSettings, error)
+	UpdateSettings(ctx context.Context, settings *domain.TeamSettings) (*domain.TeamSettings, error)
 }
 
-type teamService struct {
-	repos *repository.Repositories
+type TeamServiceOption func(*teamService)
+
+// WithTeamSelector makes deactivation pick replacement reviewers with the same strategy
+// as PRService. Without it the first candidate in user_id order is used.
+func WithTeamSelector(selector ReviewerSelector) TeamServiceOption {
+	return func(s *teamService) {
+		s.selector = selector
+	}
 }
 
-func NewTeamService(repos *repository.Repositories) TeamService {
-	return &teamService{repos: repos}
+type teamService struct {
+	repos    *repository.Repositories
+	selector ReviewerSelector
 }
 
-func (s *teamService) CreateTeam(ctx context.Context, teamName string, members []TeamMemberInput) (*TeamWithMembers, error) {
-	exists, err := s.repos.Team.Exists(ctx, teamName)
-	if err != nil {
-		return nil, err
-	}
-	if exists {
-		return nil, domain.ErrTeamExists
+func NewTeamService(repos *repository.Repositories, opts ...TeamServiceOption) TeamService {
+	s := &teamService{repos: repos}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
+}
 
+// CreateTeam creates the team with its members. Of concurrent creations of the same
+// team the insert lets one through and fails the others with ErrTeamExists, before any
+// member is touched.
+func (s *teamService) CreateTeam(ctx context.Context, teamName string, members []TeamMemberInput) (*TeamWithMembers, error) {
 	var resultMembers []*domain.User
+	var team *domain.Team
+
+	err := s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		exists, err := s.repos.Team.Exists(txCtx, teamName)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return domain.ErrTeamExists
+		}
 
-	err = s.repos.WithTx(ctx, func(txCtx context.Context) error {
-		team := &domain.Team{
+		team = &domain.Team{
 			TeamName:  teamName,
 			CreatedAt: time.Now(),
 		}
@@ -82,6 +107,7 @@ func (s *teamService) CreateTeam(ctx context.Context, teamName string, members [
 	return &TeamWithMembers{
 		TeamName: teamName,
 		Members:  resultMembers,
+		Version:  team.Version,
 	}, nil
 }
 
@@ -98,6 +124,7 @@ func (s *teamService) GetTeam(ctx context.Context, teamName string) (*TeamWithMe
 	return &TeamWithMembers{
 		TeamName: team.TeamName,
 		Members:  members,
+		Version:  team.Version,
 	}, nil
 }
 
@@ -109,14 +136,17 @@ func (s *teamService) DeactivateTeamUsers(ctx context.Context, teamName string,
 			AffectedPRCount:  0,
 		}, nil
 	}
-	_, err := s.repos.Team.GetByName(ctx, teamName)
-	if err != nil {
-		return nil, err
-	}
-
 	var deactivatedCount int
 	var affectedPRCount int
-	err = s.repos.WithTx(ctx, func(txCtx context.Context) error {
+	err := s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		team, err := s.repos.Team.GetByNameForUpdate(txCtx, teamName)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(txCtx, team.Version); err != nil {
+			return err
+		}
+
 		count, err := s.repos.User.DeactivateUsers(txCtx, teamName, userIDs)
 		if err != nil {
 			return err
@@ -126,6 +156,9 @@ func (s *teamService) DeactivateTeamUsers(ctx context.Context, teamName string,
 		if deactivatedCount == 0 {
 			return nil
 		}
+		if _, err := s.repos.Team.BumpVersion(txCtx, teamName); err != nil {
+			return err
+		}
 		affectedPRs, err := s.repos.PR.GetOpenPRsByReviewers(txCtx, userIDs)
 		if err != nil {
 			return err
@@ -143,16 +176,36 @@ func (s *teamService) DeactivateTeamUsers(ctx context.Context, teamName string,
 			for _, reviewerID := range pr.AssignedReviewers {
 				if deactivatedSet[reviewerID] {
 					excludeIDs := append([]string{pr.AuthorID}, pr.AssignedReviewers...)
-					
+
 					candidates, err := s.repos.User.ListActiveByTeamExcluding(txCtx, teamName, excludeIDs)
 					if err != nil {
 						return err
 					}
 
+					constraints, err := s.repos.Constraint.GetForAuthor(txCtx, pr.AuthorID)
+					if err != nil {
+						return err
+					}
+					candidates = filterAllowed(candidates, constraints, pr.AuthorID)
+
+					event := domain.NewPREvent(pr.PullRequestID, domain.PREventReviewerRemoved)
+					event.UserID = reviewerID
+					event.Reason = deactivationReason
+					if s.selector != nil {
+						candidates, err = s.selector.Select(txCtx, SelectionKey{PRID: pr.PullRequestID, TeamName: teamName}, candidates, 1)
+						if err != nil {
+							return err
+						}
+					}
 					if len(candidates) > 0 {
 						replacement := candidates[0].UserID
 						newReviewers = append(newReviewers, replacement)
 						pr.AssignedReviewers = append(pr.AssignedReviewers, replacement)
+						event.Type = domain.PREventReviewerReassigned
+						event.ReplacedBy = replacement
+					}
+					if err := s.repos.Event.Record(txCtx, event); err != nil {
+						return err
 					}
 				} else {
 					newReviewers = append(newReviewers, reviewerID)
@@ -162,6 +215,9 @@ func (s *teamService) DeactivateTeamUsers(ctx context.Context, teamName string,
 			if err := s.repos.PR.AssignReviewers(txCtx, pr.PullRequestID, newReviewers); err != nil {
 				return err
 			}
+			if _, err := s.repos.PR.BumpVersion(txCtx, pr.PullRequestID); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -176,4 +232,43 @@ func (s *teamService) DeactivateTeamUsers(ctx context.Context, teamName string,
 		DeactivatedCount: deactivatedCount,
 		AffectedPRCount:  affectedPRCount,
 	}, nil
-}
\ No newline at end of file
+}
+
+func (s *teamService) GetSettings(ctx context.Context, teamName string) (*domain.TeamSettings, error) {
+	team, err := s.repos.Team.GetByName(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.repos.Settings.Get(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	settings.Version = team.Version
+	return settings, nil
+}
+
+// UpdateSettings replaces the settings of the team. Changes to one team are applied one
+// at a time, each against the version the previous one left.
+func (s *teamService) UpdateSettings(ctx context.Context, settings *domain.TeamSettings) (*domain.TeamSettings, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	err := s.repos.WithTx(ctx, func(txCtx context.Context) error {
+		team, err := s.repos.Team.GetByNameForUpdate(txCtx, settings.TeamName)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(txCtx, team.Version); err != nil {
+			return err
+		}
+		if err := s.repos.Settings.Upsert(txCtx, settings); err != nil {
+			return err
+		}
+		settings.Version, err = s.repos.Team.BumpVersion(txCtx, settings.TeamName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
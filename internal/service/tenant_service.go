@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+	"github.com/mivihan/Pull_Request_service/internal/tenant"
+)
+
+// TenantService manages the organizations served by the deployment. It acts across
+// tenants and is meant for the operators of the deployment, not for tenant admins.
+type TenantService interface {
+	CreateTenant(ctx context.Context, t *domain.Tenant) (*domain.Tenant, error)
+	GetTenant(ctx context.Context, tenantID string) (*domain.Tenant, error)
+	ListTenants(ctx context.Context) ([]*domain.Tenant, error)
+	ForEach(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type tenantService struct {
+	repos *repository.Repositories
+	now   func() time.Time
+}
+
+func NewTenantService(repos *repository.Repositories) TenantService {
+	return &tenantService{
+		repos: repos,
+		now:   time.Now,
+	}
+}
+
+func (s *tenantService) CreateTenant(ctx context.Context, t *domain.Tenant) (*domain.Tenant, error) {
+	t.CreatedAt = s.now().UTC().Truncate(time.Microsecond)
+	if err := s.repos.Tenant.Create(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *tenantService) GetTenant(ctx context.Context, tenantID string) (*domain.Tenant, error) {
+	return s.repos.Tenant.GetByID(ctx, tenantID)
+}
+
+func (s *tenantService) ListTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	return s.repos.Tenant.List(ctx)
+}
+
+// ForEach runs fn once per tenant with the tenant set in its context, as background
+// jobs do. A failure in one tenant does not stop the others; all failures are returned.
+func (s *tenantService) ForEach(ctx context.Context, fn func(ctx context.Context) error) error {
+	tenants, err := s.repos.Tenant.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, t := range tenants {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := fn(tenant.WithID(ctx, t.TenantID)); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", t.TenantID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
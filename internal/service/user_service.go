@@ -2,14 +2,19 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/mivihan/Pull_Request_service/internal/domain"
 	"github.com/mivihan/Pull_Request_service/internal/repository"
 )
 
 type UserService interface {
+	GetUser(ctx context.Context, userID string) (*domain.User, error)
 	SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error)
-	GetReviews(ctx context.Context, userID string) ([]*domain.PullRequest, error)
+	SetSlackMemberID(ctx context.Context, userID, memberID string) (*domain.User, error)
+	SetEmail(ctx context.Context, userID, email string) (*domain.User, error)
+	GetReviews(ctx context.Context, userID string) ([]*domain.ReviewAssignment, error)
+	GetReview(ctx context.Context, userID, prID string) (*domain.ReviewAssignment, error)
 }
 
 type userService struct {
@@ -20,15 +25,94 @@ func NewUserService(repos *repository.Repositories) UserService {
 	return &userService{repos: repos}
 }
 
+func (s *userService) GetUser(ctx context.Context, userID string) (*domain.User, error) {
+	return s.repos.User.GetByID(ctx, userID)
+}
+
 func (s *userService) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
 	return s.repos.User.SetIsActive(ctx, userID, isActive)
 }
 
-func (s *userService) GetReviews(ctx context.Context, userID string) ([]*domain.PullRequest, error) {
-	_, err := s.repos.User.GetByID(ctx, userID)
+func (s *userService) SetSlackMemberID(ctx context.Context, userID, memberID string) (*domain.User, error) {
+	return s.repos.User.SetSlackMemberID(ctx, userID, memberID)
+}
+
+func (s *userService) SetEmail(ctx context.Context, userID, email string) (*domain.User, error) {
+	return s.repos.User.SetEmail(ctx, userID, email)
+}
+
+// GetReviews lists the user's pull requests and, when the user's team has a review SLA,
+// flags the open ones the user has not responded to in time.
+func (s *userService) GetReviews(ctx context.Context, userID string) ([]*domain.ReviewAssignment, error) {
+	user, err := s.repos.User.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prs, err := s.repos.PR.ListByReviewer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.repos.Settings.Get(ctx, user.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	result := make([]*domain.ReviewAssignment, len(prs))
+	for i, pr := range prs {
+		if result[i], err = s.reviewAssignment(ctx, settings, userID, pr, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// GetReview returns a single pull request of the user's queue in the same form as
+// GetReviews.
+func (s *userService) GetReview(ctx context.Context, userID, prID string) (*domain.ReviewAssignment, error) {
+	user, err := s.repos.User.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, err := s.repos.PR.GetByID(ctx, prID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.repos.PR.ListByReviewer(ctx, userID)
+	settings, err := s.repos.Settings.Get(ctx, user.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reviewAssignment(ctx, settings, userID, pr, time.Now())
+}
+
+func (s *userService) reviewAssignment(ctx context.Context, settings *domain.TeamSettings, userID string, pr *domain.PullRequest, now time.Time) (*domain.ReviewAssignment, error) {
+	assignment := &domain.ReviewAssignment{PullRequest: pr}
+	if !settings.HasSLA() || pr.IsMerged() {
+		return assignment, nil
+	}
+
+	events, err := s.repos.Event.ListByPR(ctx, pr.PullRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := func(_ string, assignedAt time.Time) (time.Time, bool) {
+		return settings.ReviewDeadline(assignedAt)
+	}
+	for _, a := range domain.EvaluateAssignmentSLA(events, deadline, now) {
+		if a.ReviewerID == userID && a.IsOpen() {
+			due := a.Deadline
+			assignment.Deadline = &due
+			assignment.Overdue = a.Breached
+		}
+	}
+
+	return assignment, nil
 }
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+type expectedVersionsKey struct{}
+
+// WithExpectedVersions makes the changes made with ctx conditional: a change to a pull
+// request or team fails with domain.ErrVersionMismatch unless the resource is at one of
+// versions when it is locked. An empty list matches no version.
+func WithExpectedVersions(ctx context.Context, versions []int64) context.Context {
+	if versions == nil {
+		versions = []int64{}
+	}
+	return context.WithValue(ctx, expectedVersionsKey{}, versions)
+}
+
+// checkVersion compares the current version of a locked resource with the versions
+// expected by ctx. Calls without expected versions always pass.
+func checkVersion(ctx context.Context, current int64) error {
+	versions, ok := ctx.Value(expectedVersionsKey{}).([]int64)
+	if !ok || slices.Contains(versions, current) {
+		return nil
+	}
+	return domain.ErrVersionMismatch
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+	"github.com/mivihan/Pull_Request_service/internal/repository"
+)
+
+func TestPRService_MergePR_ChecksVersion(t *testing.T) {
+	mockRepos := newMockRepos()
+	mockRepos.prRepo.prs["pr-1"] = &domain.PullRequest{
+		PullRequestID:     "pr-1",
+		PullRequestName:   "Test",
+		AuthorID:          "u1",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+		CreatedAt:         time.Now(),
+		Version:           3,
+	}
+
+	repos := &repository.Repositories{}
+	repos.Event = mockRepos.eventRepo
+	repos.Tx = mockRepos
+	repos.PR = mockRepos.prRepo
+
+	service := NewPRService(repos)
+
+	stale := WithExpectedVersions(context.Background(), []int64{1, 2})
+	if _, err := service.MergePR(stale, "pr-1"); !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Fatalf("expected version mismatch, got %v", err)
+	}
+	if mockRepos.prRepo.prs["pr-1"].IsMerged() {
+		t.Fatal("expected a stale merge not to change the pull request")
+	}
+
+	none := WithExpectedVersions(context.Background(), nil)
+	if _, err := service.MergePR(none, "pr-1"); !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Fatalf("expected an empty version list to match nothing, got %v", err)
+	}
+
+	pr, err := service.MergePR(WithExpectedVersions(context.Background(), []int64{2, 3}), "pr-1")
+	if err != nil {
+		t.Fatalf("MergePR failed: %v", err)
+	}
+	if pr.Version != 4 {
+		t.Errorf("expected the merge to bump the version to 4, got %d", pr.Version)
+	}
+
+	// Merging again changes nothing, so the version stays.
+	pr, err = service.MergePR(context.Background(), "pr-1")
+	if err != nil {
+		t.Fatalf("second MergePR failed: %v", err)
+	}
+	if pr.Version != 4 {
+		t.Errorf("expected the version to stay at 4, got %d", pr.Version)
+	}
+}
+
+func TestTeamService_UpdateSettings_ChecksVersion(t *testing.T) {
+	_, repos := newConstraintTestRepos()
+	repos.Settings = &mockSettingsRepo{settings: map[string]*domain.TeamSettings{}}
+	service := NewTeamService(repos)
+	ctx := context.Background()
+
+	current, err := service.GetSettings(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+
+	updated, err := service.UpdateSettings(WithExpectedVersions(ctx, []int64{current.Version}), domain.DefaultTeamSettings("backend"))
+	if err != nil {
+		t.Fatalf("UpdateSettings failed: %v", err)
+	}
+	if updated.Version != current.Version+1 {
+		t.Errorf("expected version %d, got %d", current.Version+1, updated.Version)
+	}
+
+	settings := domain.DefaultTeamSettings("backend")
+	settings.ReviewSLA = time.Hour
+	_, err = service.UpdateSettings(WithExpectedVersions(ctx, []int64{current.Version}), settings)
+	if !errors.Is(err, domain.ErrVersionMismatch) {
+		t.Fatalf("expected version mismatch, got %v", err)
+	}
+
+	got, err := service.GetSettings(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetSettings failed: %v", err)
+	}
+	if got.ReviewSLA == time.Hour || got.Version != updated.Version {
+		t.Errorf("expected a stale update not to apply, got SLA %v at version %d", got.ReviewSLA, got.Version)
+	}
+}
@@ -0,0 +1,290 @@
+// Package stream pushes pull request timeline events to live subscribers.
+package stream
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+const (
+	defaultBufferSize = 64
+	pageSize          = 500
+
+	// defaultGapTimeout is how long the broker keeps looking for an event ID it moved
+	// past, which a transaction took before later ones but has not committed yet. IDs of
+	// rolled back transactions never show up and are given up on after it.
+	defaultGapTimeout = 10 * time.Minute
+	// maxGaps bounds how many such IDs are looked for; the lowest are given up on first.
+	maxGaps = 1000
+)
+
+var ErrClosed = errors.New("event stream is closed")
+
+// FeedSource reads the event log; repository.EventRepository implements it.
+type FeedSource interface {
+	LatestID(ctx context.Context) (int64, error)
+	ListFeed(ctx context.Context, afterID int64, limit int) ([]*domain.FeedEvent, error)
+	ListFeedByIDs(ctx context.Context, ids []int64) ([]*domain.FeedEvent, error)
+}
+
+// Broker polls the event log once for the whole process and fans new events out to
+// subscribers. A subscriber whose buffer is full is dropped instead of slowing down
+// the others; it can reconnect and replay what it missed from the log. Events that
+// commit after events with higher IDs are delivered when they show up.
+type Broker struct {
+	source     FeedSource
+	bufferSize int
+	gapTimeout time.Duration
+	now        func() time.Time
+
+	mu     sync.Mutex
+	cursor int64
+	// gaps are the IDs below the cursor not seen yet, with when they were skipped.
+	gaps    map[int64]time.Time
+	started bool
+	closed  bool
+	subs    map[*Subscription]struct{}
+}
+
+type BrokerOption func(*Broker)
+
+// WithBufferSize sets how many undelivered events a subscriber may accumulate.
+func WithBufferSize(n int) BrokerOption {
+	return func(b *Broker) {
+		b.bufferSize = n
+	}
+}
+
+// WithGapTimeout sets how long a skipped event ID is looked for.
+func WithGapTimeout(d time.Duration) BrokerOption {
+	return func(b *Broker) {
+		b.gapTimeout = d
+	}
+}
+
+func NewBroker(source FeedSource, opts ...BrokerOption) *Broker {
+	b := &Broker{
+		source:     source,
+		bufferSize: defaultBufferSize,
+		gapTimeout: defaultGapTimeout,
+		now:        time.Now,
+		gaps:       make(map[int64]time.Time),
+		subs:       make(map[*Subscription]struct{}),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Subscription receives matching events with IDs above Start, and those below it that
+// commit late. Events is closed when the
+// subscriber is dropped for being too slow or the broker shuts down; Lagged tells
+// the two apart.
+type Subscription struct {
+	filter domain.EventFilter
+	start  int64
+	events chan *domain.FeedEvent
+	lagged bool
+	broker *Broker
+}
+
+func (s *Subscription) Events() <-chan *domain.FeedEvent {
+	return s.events
+}
+
+func (s *Subscription) Start() int64 {
+	return s.start
+}
+
+func (s *Subscription) Lagged() bool {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	return s.lagged
+}
+
+func (s *Subscription) Close() {
+	s.broker.remove(s)
+}
+
+// Subscribe registers a subscriber that receives events recorded after the call.
+func (b *Broker) Subscribe(ctx context.Context, filter domain.EventFilter) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if err := b.startLocked(ctx); err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		filter: filter,
+		start:  b.cursor,
+		events: make(chan *domain.FeedEvent, b.bufferSize),
+		broker: b,
+	}
+	b.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Replay returns the matching events after afterID up to the subscription start, so that
+// a reconnecting client catches up before live events.
+func (b *Broker) Replay(ctx context.Context, sub *Subscription, afterID int64) ([]*domain.FeedEvent, error) {
+	var result []*domain.FeedEvent
+	for afterID < sub.start {
+		events, err := b.source.ListFeed(ctx, afterID, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(events) == 0 {
+			break
+		}
+		for _, e := range events {
+			if e.EventID > sub.start {
+				return result, nil
+			}
+			if sub.filter.Matches(e) {
+				result = append(result, e)
+			}
+		}
+		afterID = events[len(events)-1].EventID
+	}
+	return result, nil
+}
+
+// Poll reads the events recorded since the previous poll, and those skipped by earlier
+// polls that have committed since, and delivers them. It is meant to be run periodically
+// by the scheduler.
+func (b *Broker) Poll(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	err := b.startLocked(ctx)
+	cursor := b.cursor
+	gaps := slices.Sorted(maps.Keys(b.gaps))
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if len(gaps) > 0 {
+		late, err := b.source.ListFeedByIDs(ctx, gaps)
+		if err != nil {
+			return err
+		}
+		b.publish(late)
+	}
+
+	for {
+		events, err := b.source.ListFeed(ctx, cursor, pageSize)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		b.publish(events)
+		cursor = events[len(events)-1].EventID
+		if len(events) < pageSize {
+			return nil
+		}
+	}
+}
+
+// startLocked positions the broker at the end of the log on first use.
+func (b *Broker) startLocked(ctx context.Context) error {
+	if b.started {
+		return nil
+	}
+	latest, err := b.source.LatestID(ctx)
+	if err != nil {
+		return err
+	}
+	b.cursor, b.started = latest, true
+	return nil
+}
+
+// publish delivers the events above the cursor, noting the IDs it skips, and those
+// filling earlier gaps.
+func (b *Broker) publish(events []*domain.FeedEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	for _, e := range events {
+		switch _, late := b.gaps[e.EventID]; {
+		case e.EventID > b.cursor:
+			for id := max(b.cursor+1, e.EventID-maxGaps); id < e.EventID; id++ {
+				b.gaps[id] = now
+			}
+			b.cursor = e.EventID
+		case late:
+			delete(b.gaps, e.EventID)
+		default:
+			continue
+		}
+
+		for sub := range b.subs {
+			if !sub.filter.Matches(e) {
+				continue
+			}
+			select {
+			case sub.events <- e:
+			default:
+				sub.lagged = true
+				delete(b.subs, sub)
+				close(sub.events)
+			}
+		}
+	}
+	b.expireGapsLocked(now)
+}
+
+func (b *Broker) expireGapsLocked(now time.Time) {
+	for id, skipped := range b.gaps {
+		if now.Sub(skipped) >= b.gapTimeout {
+			delete(b.gaps, id)
+		}
+	}
+	if extra := len(b.gaps) - maxGaps; extra > 0 {
+		for _, id := range slices.Sorted(maps.Keys(b.gaps))[:extra] {
+			delete(b.gaps, id)
+		}
+	}
+}
+
+func (b *Broker) remove(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	close(sub.events)
+}
+
+// Close ends every subscription and refuses new ones. Register it with
+// http.Server.RegisterOnShutdown so that open streams do not hold up shutdown.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
+
+// Subscribers reports how many subscriptions are open.
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
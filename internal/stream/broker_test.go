@@ -0,0 +1,273 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mivihan/Pull_Request_service/internal/domain"
+)
+
+type fakeFeed struct {
+	mu     sync.Mutex
+	events []*domain.FeedEvent
+	// uncommitted events took an ID but are not visible yet.
+	uncommitted map[int64]bool
+}
+
+func (f *fakeFeed) add(prID, team string, eventType domain.PREventType, userID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e := &domain.FeedEvent{
+		PREvent:  domain.NewPREvent(prID, eventType),
+		AuthorID: "author",
+		TeamName: team,
+	}
+	e.EventID = int64(len(f.events) + 1)
+	e.UserID = userID
+	f.events = append(f.events, e)
+}
+
+func (f *fakeFeed) LatestID(ctx context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return int64(len(f.events)), nil
+}
+
+func (f *fakeFeed) ListFeed(ctx context.Context, afterID int64, limit int) ([]*domain.FeedEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []*domain.FeedEvent
+	for _, e := range f.events {
+		if e.EventID > afterID && !f.uncommitted[e.EventID] && len(result) < limit {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeFeed) ListFeedByIDs(ctx context.Context, ids []int64) ([]*domain.FeedEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []*domain.FeedEvent
+	for _, e := range f.events {
+		if slices.Contains(ids, e.EventID) && !f.uncommitted[e.EventID] {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func drain(sub *Subscription) []int64 {
+	var ids []int64
+	for {
+		select {
+		case e, ok := <-sub.Events():
+			if !ok {
+				return ids
+			}
+			ids = append(ids, e.EventID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestBroker_FanOutWithFilters(t *testing.T) {
+	feed := &fakeFeed{}
+	feed.add("pr-0", "backend", domain.PREventCreated, "")
+	broker := NewBroker(feed)
+	ctx := context.Background()
+
+	all, _ := broker.Subscribe(ctx, domain.EventFilter{})
+	team, _ := broker.Subscribe(ctx, domain.EventFilter{TeamName: "backend"})
+	user, _ := broker.Subscribe(ctx, domain.EventFilter{UserID: "u2"})
+	pr, _ := broker.Subscribe(ctx, domain.EventFilter{PRID: "pr-2"})
+
+	feed.add("pr-1", "backend", domain.PREventReviewerAssigned, "u2")
+	feed.add("pr-2", "frontend", domain.PREventReviewerAssigned, "u3")
+	feed.add("pr-1", "backend", domain.PREventMerged, "")
+
+	if err := broker.Poll(ctx); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		sub      *Subscription
+		expected string
+	}{
+		{name: "everything after subscribing", sub: all, expected: "[2 3 4]"},
+		{name: "team", sub: team, expected: "[2 4]"},
+		{name: "user", sub: user, expected: "[2]"},
+		{name: "pull request", sub: pr, expected: "[3]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprint(drain(tt.sub)); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	// A second poll must not deliver the same events again.
+	if err := broker.Poll(ctx); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if got := drain(all); len(got) != 0 {
+		t.Errorf("expected no duplicates, got %v", got)
+	}
+}
+
+func TestBroker_IsolatesTenants(t *testing.T) {
+	feed := &fakeFeed{}
+	broker := NewBroker(feed)
+	ctx := context.Background()
+
+	acme, _ := broker.Subscribe(ctx, domain.EventFilter{TenantID: "acme"})
+	globex, _ := broker.Subscribe(ctx, domain.EventFilter{TenantID: "globex", TeamName: "backend"})
+
+	feed.add("pr-1", "backend", domain.PREventCreated, "")
+	feed.add("pr-1", "backend", domain.PREventCreated, "")
+	feed.events[0].TenantID = "acme"
+	feed.events[1].TenantID = "globex"
+
+	if err := broker.Poll(ctx); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+
+	if got := fmt.Sprint(drain(acme)); got != "[1]" {
+		t.Errorf("expected acme to see [1], got %s", got)
+	}
+	if got := fmt.Sprint(drain(globex)); got != "[2]" {
+		t.Errorf("expected globex to see [2], got %s", got)
+	}
+}
+
+func TestBroker_DeliversEventsCommittedLate(t *testing.T) {
+	feed := &fakeFeed{uncommitted: map[int64]bool{}}
+	broker := NewBroker(feed)
+	now := time.Now()
+	broker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	sub, _ := broker.Subscribe(ctx, domain.EventFilter{})
+
+	// Events 1 and 3 took their IDs before event 2 but commit after it.
+	for i := 0; i < 3; i++ {
+		feed.add(fmt.Sprintf("pr-%d", i), "backend", domain.PREventCreated, "")
+	}
+	feed.uncommitted[1], feed.uncommitted[3] = true, true
+
+	if err := broker.Poll(ctx); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if got := fmt.Sprint(drain(sub)); got != "[2]" {
+		t.Fatalf("expected the committed event, got %s", got)
+	}
+
+	// Event 1 commits long after the others; event 3 never does.
+	delete(feed.uncommitted, 1)
+	now = now.Add(defaultGapTimeout / 2)
+	if err := broker.Poll(ctx); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if got := fmt.Sprint(drain(sub)); got != "[1]" {
+		t.Errorf("expected the late event not to be skipped, got %s", got)
+	}
+
+	if err := broker.Poll(ctx); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if got := drain(sub); len(got) != 0 {
+		t.Errorf("expected no duplicates, got %v", got)
+	}
+
+	// Event 3 is given up on once the timeout passes, as for a rolled back transaction.
+	feed.add("pr-3", "backend", domain.PREventCreated, "")
+	if err := broker.Poll(ctx); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	now = now.Add(defaultGapTimeout)
+	if err := broker.Poll(ctx); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	delete(feed.uncommitted, 3)
+	if err := broker.Poll(ctx); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+	if got := fmt.Sprint(drain(sub)); got != "[4]" {
+		t.Errorf("expected the gap to be given up on after the timeout, got %s", got)
+	}
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	feed := &fakeFeed{}
+	broker := NewBroker(feed, WithBufferSize(2))
+	ctx := context.Background()
+
+	slow, _ := broker.Subscribe(ctx, domain.EventFilter{})
+	fast, _ := broker.Subscribe(ctx, domain.EventFilter{PRID: "pr-1"})
+
+	for i := 0; i < 3; i++ {
+		feed.add(fmt.Sprintf("pr-%d", i), "backend", domain.PREventCreated, "")
+	}
+	if err := broker.Poll(ctx); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+
+	if !slow.Lagged() || broker.Subscribers() != 1 {
+		t.Fatalf("expected the slow subscriber to be dropped, %d left", broker.Subscribers())
+	}
+	if got := fmt.Sprint(drain(slow)); got != "[1 2]" {
+		t.Errorf("expected buffered events before the drop, got %s", got)
+	}
+	if got := fmt.Sprint(drain(fast)); got != "[2]" {
+		t.Errorf("other subscribers must keep receiving, got %s", got)
+	}
+}
+
+func TestBroker_ReplayAndClose(t *testing.T) {
+	feed := &fakeFeed{}
+	for i := 0; i < 4; i++ {
+		feed.add("pr-1", "backend", domain.PREventReviewerAssigned, fmt.Sprintf("u%d", i%2))
+	}
+	broker := NewBroker(feed)
+	ctx := context.Background()
+
+	sub, err := broker.Subscribe(ctx, domain.EventFilter{UserID: "u1"})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	feed.add("pr-1", "backend", domain.PREventReviewerAssigned, "u1")
+
+	missed, err := broker.Replay(ctx, sub, 1)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	ids := make([]int64, len(missed))
+	for i, e := range missed {
+		ids[i] = e.EventID
+	}
+	if got := fmt.Sprint(ids); got != "[2 4]" {
+		t.Errorf("expected replay up to the subscription start, got %s", got)
+	}
+
+	broker.Poll(ctx)
+	broker.Close()
+	if got := fmt.Sprint(drain(sub)); got != "[5]" {
+		t.Errorf("expected the live event, got %s", got)
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Error("expected the subscription to be closed")
+	}
+	if sub.Lagged() {
+		t.Error("closing the broker is not lagging")
+	}
+	if _, err := broker.Subscribe(ctx, domain.EventFilter{}); err != ErrClosed {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+// Package tenant carries the organization a call acts for. Every repository query is
+// scoped to the tenant of its context.
+package tenant
+
+import "context"
+
+// Default is the tenant of data created before tenants existed and of calls that do not
+// name one.
+const Default = "default"
+
+type idKey struct{}
+
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// ID returns the tenant of ctx, Default when none was set.
+func ID(ctx context.Context) string {
+	if id, ok := ctx.Value(idKey{}).(string); ok && id != "" {
+		return id
+	}
+	return Default
+}
@@ -0,0 +1,244 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455, section 1.3.
+	if got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("unexpected accept key %s", got)
+	}
+}
+
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// dial performs the opening handshake against an echo server whose ReadMessage errors
+// are sent to errs.
+func dial(t *testing.T, errs chan<- error) *testClient {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		c.MaxMessageSize = 1024
+		for {
+			op, msg, err := c.ReadMessage()
+			if err != nil {
+				errs <- err
+				c.Close(CloseNormal, "")
+				return
+			}
+			if op == OpText {
+				c.WriteText(msg, time.Now().Add(time.Second))
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	request := "GET / HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: " + key + "\r\nSec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(request)); err != nil {
+		t.Fatalf("write handshake: %v", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("read handshake: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != AcceptKey(key) {
+		t.Fatalf("unexpected accept key %s", got)
+	}
+
+	return &testClient{t: t, conn: conn, br: br}
+}
+
+func (c *testClient) send(fin bool, opcode int, payload []byte) {
+	c.t.Helper()
+
+	b0 := byte(opcode)
+	if fin {
+		b0 |= 0x80
+	}
+	frame := []byte{b0}
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, 0x80|byte(n))
+	default:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		c.t.Fatalf("write frame: %v", err)
+	}
+}
+
+func (c *testClient) receive() (int, []byte) {
+	c.t.Helper()
+
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		c.t.Fatalf("read frame: %v", err)
+	}
+	if header[1]&0x80 != 0 {
+		c.t.Fatal("server frames must not be masked")
+	}
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		io.ReadFull(c.br, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		c.t.Fatalf("read payload: %v", err)
+	}
+	return int(header[0] & 0x0F), payload
+}
+
+func (c *testClient) expectClose(code int) {
+	c.t.Helper()
+
+	op, payload := c.receive()
+	if op != OpClose || len(payload) < 2 {
+		c.t.Fatalf("expected close frame, got opcode %d", op)
+	}
+	if got := int(binary.BigEndian.Uint16(payload)); got != code {
+		c.t.Errorf("expected close code %d, got %d", code, got)
+	}
+}
+
+func TestConn_Echo(t *testing.T) {
+	errs := make(chan error, 1)
+	c := dial(t, errs)
+
+	c.send(true, OpText, []byte("hello"))
+	if op, msg := c.receive(); op != OpText || string(msg) != "hello" {
+		t.Errorf("expected echo, got %d %q", op, msg)
+	}
+
+	long := strings.Repeat("x", 300)
+	c.send(true, OpText, []byte(long))
+	if _, msg := c.receive(); string(msg) != long {
+		t.Errorf("expected %d byte echo, got %d", len(long), len(msg))
+	}
+}
+
+func TestConn_FragmentsAndPing(t *testing.T) {
+	errs := make(chan error, 1)
+	c := dial(t, errs)
+
+	c.send(false, OpText, []byte("hel"))
+	c.send(true, OpPing, []byte("p"))
+	if op, msg := c.receive(); op != OpPong || string(msg) != "p" {
+		t.Fatalf("expected pong, got %d %q", op, msg)
+	}
+	c.send(true, OpContinuation, []byte("lo"))
+	if _, msg := c.receive(); string(msg) != "hello" {
+		t.Errorf("expected reassembled message, got %q", msg)
+	}
+}
+
+func TestConn_CloseHandshake(t *testing.T) {
+	errs := make(chan error, 1)
+	c := dial(t, errs)
+
+	c.send(true, OpClose, binary.BigEndian.AppendUint16(nil, CloseGoingAway))
+	c.expectClose(CloseGoingAway)
+
+	var closeErr *CloseError
+	if err := <-errs; !errors.As(err, &closeErr) || closeErr.Code != CloseGoingAway {
+		t.Errorf("expected close error with code %d, got %v", CloseGoingAway, err)
+	}
+}
+
+func TestConn_ProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		send func(c *testClient)
+		code int
+	}{
+		{
+			name: "message too big",
+			send: func(c *testClient) {
+				c.send(false, OpText, make([]byte, 600))
+				c.send(true, OpContinuation, make([]byte, 600))
+			},
+			code: CloseMessageTooBig,
+		},
+		{
+			name: "invalid UTF-8",
+			send: func(c *testClient) { c.send(true, OpText, []byte{0xff, 0xfe}) },
+			code: CloseInvalidPayload,
+		},
+		{
+			name: "continuation without message",
+			send: func(c *testClient) { c.send(true, OpContinuation, []byte("x")) },
+			code: CloseProtocolError,
+		},
+		{
+			name: "unmasked frame",
+			send: func(c *testClient) { c.conn.Write([]byte{0x81, 0x01, 'x'}) },
+			code: CloseProtocolError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := make(chan error, 1)
+			c := dial(t, errs)
+
+			tt.send(c)
+			c.expectClose(tt.code)
+			<-errs
+		})
+	}
+}
+
+func TestUpgrade_RejectsPlainRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Upgrade(w, r)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		t.Errorf("expected 426, got %d", resp.StatusCode)
+	}
+}
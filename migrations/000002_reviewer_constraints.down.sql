@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS author_never_assign;
+DROP TABLE IF EXISTS team_reviewer_exclusions;
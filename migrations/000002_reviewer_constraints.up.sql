@@ -0,0 +1,19 @@
+CREATE TABLE team_reviewer_exclusions (
+    team_name VARCHAR(255) NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    user_a VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    user_b VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (team_name, user_a, user_b),
+    CHECK (user_a < user_b)
+);
+
+CREATE INDEX idx_exclusions_user_a ON team_reviewer_exclusions(user_a);
+CREATE INDEX idx_exclusions_user_b ON team_reviewer_exclusions(user_b);
+
+CREATE TABLE author_never_assign (
+    author_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    reviewer_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (author_id, reviewer_id),
+    CHECK (author_id <> reviewer_id)
+);
@@ -0,0 +1,28 @@
+CREATE TABLE pr_events (
+    event_id BIGSERIAL PRIMARY KEY,
+    pr_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event_type VARCHAR(32) NOT NULL,
+    actor_id VARCHAR(255) NULL,
+    user_id VARCHAR(255) NULL,
+    replaced_by VARCHAR(255) NULL,
+    reason VARCHAR(32) NULL,
+    comment TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_pr_events_pr ON pr_events(pr_id, event_id);
+CREATE INDEX idx_pr_events_user_type ON pr_events(user_id, event_type, created_at);
+
+-- Backfill the timeline for pull requests created before events were recorded.
+INSERT INTO pr_events (pr_id, event_type, actor_id, created_at)
+SELECT pull_request_id, 'CREATED', author_id, created_at
+FROM pull_requests;
+
+INSERT INTO pr_events (pr_id, event_type, user_id, created_at)
+SELECT pr_id, 'REVIEWER_ASSIGNED', user_id, assigned_at
+FROM pr_reviewers;
+
+INSERT INTO pr_events (pr_id, event_type, created_at)
+SELECT pull_request_id, 'MERGED', merged_at
+FROM pull_requests
+WHERE merged_at IS NOT NULL;
@@ -0,0 +1,5 @@
+CREATE TABLE team_rotation (
+    team_name VARCHAR(255) PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    last_user_id VARCHAR(255) NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,4 @@
+DROP VIEW IF EXISTS reviewer_assignments;
+DROP TRIGGER IF EXISTS trg_user_activity ON users;
+DROP FUNCTION IF EXISTS log_user_activity();
+DROP TABLE IF EXISTS user_activity_log;
@@ -0,0 +1,41 @@
+CREATE TABLE user_activity_log (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    is_active BOOLEAN NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_activity_user ON user_activity_log(user_id, changed_at);
+
+CREATE FUNCTION log_user_activity() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO user_activity_log (user_id, is_active, changed_at)
+        VALUES (NEW.user_id, NEW.is_active, NEW.created_at);
+    ELSIF NEW.is_active IS DISTINCT FROM OLD.is_active THEN
+        INSERT INTO user_activity_log (user_id, is_active, changed_at)
+        VALUES (NEW.user_id, NEW.is_active, NOW());
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_user_activity
+AFTER INSERT OR UPDATE OF is_active ON users
+FOR EACH ROW EXECUTE FUNCTION log_user_activity();
+
+INSERT INTO user_activity_log (user_id, is_active, changed_at)
+SELECT user_id, is_active, created_at
+FROM users;
+
+-- Every moment a user became a reviewer of a pull request, whether on creation,
+-- by manual override or by taking over from another reviewer.
+CREATE VIEW reviewer_assignments AS
+SELECT event_id, pr_id, user_id AS reviewer_id, created_at AS assigned_at
+FROM pr_events
+WHERE event_type = 'REVIEWER_ASSIGNED'
+UNION ALL
+SELECT event_id, pr_id, replaced_by AS reviewer_id, created_at AS assigned_at
+FROM pr_events
+WHERE event_type IN ('REVIEWER_REASSIGNED', 'REVIEWER_DECLINED')
+  AND replaced_by IS NOT NULL;
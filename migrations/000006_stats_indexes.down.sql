@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_pr_events_type_created;
+DROP INDEX IF EXISTS idx_pr_reviewers_assigned_at;
+DROP INDEX IF EXISTS idx_pr_merged_at;
+DROP INDEX IF EXISTS idx_pr_created_at;
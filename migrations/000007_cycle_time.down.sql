@@ -0,0 +1,4 @@
+DELETE FROM pr_events WHERE event_type = 'REVIEW_SUBMITTED';
+
+DROP INDEX IF EXISTS idx_pr_repository;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS repository;
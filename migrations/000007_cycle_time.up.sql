@@ -0,0 +1,3 @@
+ALTER TABLE pull_requests ADD COLUMN repository VARCHAR(255) NULL;
+
+CREATE INDEX idx_pr_repository ON pull_requests(repository) WHERE repository IS NOT NULL;
@@ -0,0 +1,9 @@
+CREATE TABLE team_settings (
+    team_name VARCHAR(255) PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    review_sla_minutes INT NOT NULL DEFAULT 0 CHECK (review_sla_minutes >= 0),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    work_day_start_minute INT NOT NULL DEFAULT 540,
+    work_day_end_minute INT NOT NULL DEFAULT 1080,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (work_day_start_minute >= 0 AND work_day_start_minute < work_day_end_minute AND work_day_end_minute <= 1440)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE team_settings
+    ADD COLUMN stale_timeout_minutes INT NOT NULL DEFAULT 0 CHECK (stale_timeout_minutes >= 0);
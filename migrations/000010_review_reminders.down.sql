@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reminder_log;
+DROP TABLE IF EXISTS reminder_preferences;
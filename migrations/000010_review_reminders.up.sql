@@ -0,0 +1,16 @@
+CREATE TABLE reminder_preferences (
+    user_id VARCHAR(255) PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    frequency_minutes INT NOT NULL DEFAULT 1440 CHECK (frequency_minutes > 0),
+    quiet_start_minute INT NOT NULL DEFAULT 0 CHECK (quiet_start_minute >= 0 AND quiet_start_minute < 1440),
+    quiet_end_minute INT NOT NULL DEFAULT 0 CHECK (quiet_end_minute >= 0 AND quiet_end_minute < 1440),
+    channel VARCHAR(50) NOT NULL DEFAULT 'log',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE reminder_log (
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    pr_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, pr_id)
+);
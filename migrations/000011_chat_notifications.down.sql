@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS notification_cursors;
+DROP TABLE IF EXISTS team_message_templates;
+ALTER TABLE users DROP COLUMN IF EXISTS slack_member_id;
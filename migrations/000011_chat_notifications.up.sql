@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN slack_member_id VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE TABLE team_message_templates (
+    team_name VARCHAR(255) NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    body TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (team_name, kind)
+);
+
+CREATE TABLE notification_cursors (
+    name VARCHAR(64) PRIMARY KEY,
+    last_event_id BIGINT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+CREATE TABLE user_roles (
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    team_name VARCHAR(255) NOT NULL DEFAULT '',
+    role VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, team_name),
+    CHECK ((role = 'admin' AND team_name = '') OR (role = 'team_admin' AND team_name <> ''))
+);
+
+CREATE INDEX idx_user_roles_team ON user_roles(team_name);
@@ -0,0 +1,27 @@
+CREATE TABLE audit_log (
+    entry_id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(255) NULL,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id VARCHAR(255) NOT NULL,
+    before_state JSONB NULL,
+    after_state JSONB NULL,
+    request_id VARCHAR(128) NULL,
+    source_ip VARCHAR(64) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_actor ON audit_log(actor, entry_id);
+CREATE INDEX idx_audit_log_target ON audit_log(target_type, target_id, entry_id);
+CREATE INDEX idx_audit_log_created ON audit_log(created_at);
+
+-- The log is append-only: entries can be inserted but never changed or removed.
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,97 @@
+-- Identifiers of other tenants would collide once the tenant is gone, so only the
+-- default tenant survives the downgrade.
+ALTER TABLE audit_log DISABLE TRIGGER audit_log_append_only;
+DELETE FROM audit_log WHERE tenant_id <> 'default';
+ALTER TABLE audit_log ENABLE TRIGGER audit_log_append_only;
+DELETE FROM api_keys WHERE tenant_id <> 'default';
+DELETE FROM notification_cursors WHERE tenant_id <> 'default';
+DELETE FROM pull_requests WHERE tenant_id <> 'default';
+DELETE FROM teams WHERE tenant_id <> 'default';
+
+DROP VIEW reviewer_assignments;
+
+-- Dropping the column takes the composite keys, references and indexes with it.
+ALTER TABLE audit_log DROP COLUMN tenant_id CASCADE;
+ALTER TABLE user_roles DROP COLUMN tenant_id CASCADE;
+ALTER TABLE api_keys DROP COLUMN tenant_id CASCADE;
+ALTER TABLE notification_cursors DROP COLUMN tenant_id CASCADE;
+ALTER TABLE team_message_templates DROP COLUMN tenant_id CASCADE;
+ALTER TABLE reminder_log DROP COLUMN tenant_id CASCADE;
+ALTER TABLE reminder_preferences DROP COLUMN tenant_id CASCADE;
+ALTER TABLE team_settings DROP COLUMN tenant_id CASCADE;
+ALTER TABLE user_activity_log DROP COLUMN tenant_id CASCADE;
+ALTER TABLE team_rotation DROP COLUMN tenant_id CASCADE;
+ALTER TABLE pr_events DROP COLUMN tenant_id CASCADE;
+ALTER TABLE author_never_assign DROP COLUMN tenant_id CASCADE;
+ALTER TABLE team_reviewer_exclusions DROP COLUMN tenant_id CASCADE;
+ALTER TABLE pr_reviewers DROP COLUMN tenant_id CASCADE;
+ALTER TABLE pull_requests DROP COLUMN tenant_id CASCADE;
+ALTER TABLE users DROP COLUMN tenant_id CASCADE;
+ALTER TABLE teams DROP COLUMN tenant_id CASCADE;
+
+ALTER TABLE teams ADD PRIMARY KEY (team_name);
+ALTER TABLE users ADD PRIMARY KEY (user_id);
+ALTER TABLE pull_requests ADD PRIMARY KEY (pull_request_id);
+ALTER TABLE pr_reviewers ADD PRIMARY KEY (pr_id, user_id);
+ALTER TABLE team_reviewer_exclusions ADD PRIMARY KEY (team_name, user_a, user_b);
+ALTER TABLE author_never_assign ADD PRIMARY KEY (author_id, reviewer_id);
+ALTER TABLE team_rotation ADD PRIMARY KEY (team_name);
+ALTER TABLE team_settings ADD PRIMARY KEY (team_name);
+ALTER TABLE reminder_preferences ADD PRIMARY KEY (user_id);
+ALTER TABLE reminder_log ADD PRIMARY KEY (user_id, pr_id);
+ALTER TABLE team_message_templates ADD PRIMARY KEY (team_name, kind);
+ALTER TABLE notification_cursors ADD PRIMARY KEY (name);
+ALTER TABLE user_roles ADD PRIMARY KEY (user_id, team_name);
+
+ALTER TABLE users ADD FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE;
+ALTER TABLE pull_requests ADD FOREIGN KEY (author_id) REFERENCES users(user_id);
+ALTER TABLE pr_reviewers ADD FOREIGN KEY (pr_id) REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE;
+ALTER TABLE pr_reviewers ADD FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE;
+ALTER TABLE team_reviewer_exclusions ADD FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE;
+ALTER TABLE team_reviewer_exclusions ADD FOREIGN KEY (user_a) REFERENCES users(user_id) ON DELETE CASCADE;
+ALTER TABLE team_reviewer_exclusions ADD FOREIGN KEY (user_b) REFERENCES users(user_id) ON DELETE CASCADE;
+ALTER TABLE author_never_assign ADD FOREIGN KEY (author_id) REFERENCES users(user_id) ON DELETE CASCADE;
+ALTER TABLE author_never_assign ADD FOREIGN KEY (reviewer_id) REFERENCES users(user_id) ON DELETE CASCADE;
+ALTER TABLE pr_events ADD FOREIGN KEY (pr_id) REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE;
+ALTER TABLE team_rotation ADD FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE;
+ALTER TABLE user_activity_log ADD FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE;
+ALTER TABLE team_settings ADD FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE;
+ALTER TABLE reminder_preferences ADD FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE;
+ALTER TABLE reminder_log ADD FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE;
+ALTER TABLE reminder_log ADD FOREIGN KEY (pr_id) REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE;
+ALTER TABLE team_message_templates ADD FOREIGN KEY (team_name) REFERENCES teams(team_name) ON DELETE CASCADE;
+ALTER TABLE user_roles ADD FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE;
+
+CREATE INDEX idx_users_team ON users(team_name);
+CREATE INDEX idx_pr_author ON pull_requests(author_id);
+CREATE INDEX idx_pr_reviewers_user ON pr_reviewers(user_id);
+CREATE INDEX idx_pr_events_pr ON pr_events(pr_id, event_id);
+CREATE INDEX idx_user_activity_user ON user_activity_log(user_id, changed_at);
+CREATE INDEX idx_user_roles_team ON user_roles(team_name);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor, entry_id);
+CREATE INDEX idx_audit_log_target ON audit_log(target_type, target_id, entry_id);
+
+CREATE OR REPLACE FUNCTION log_user_activity() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO user_activity_log (user_id, is_active, changed_at)
+        VALUES (NEW.user_id, NEW.is_active, NEW.created_at);
+    ELSIF NEW.is_active IS DISTINCT FROM OLD.is_active THEN
+        INSERT INTO user_activity_log (user_id, is_active, changed_at)
+        VALUES (NEW.user_id, NEW.is_active, NOW());
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE VIEW reviewer_assignments AS
+SELECT event_id, pr_id, user_id AS reviewer_id, created_at AS assigned_at
+FROM pr_events
+WHERE event_type = 'REVIEWER_ASSIGNED'
+UNION ALL
+SELECT event_id, pr_id, replaced_by AS reviewer_id, created_at AS assigned_at
+FROM pr_events
+WHERE event_type IN ('REVIEWER_REASSIGNED', 'REVIEWER_DECLINED')
+  AND replaced_by IS NOT NULL;
+
+DROP TABLE IF EXISTS tenants;
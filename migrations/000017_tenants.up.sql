@@ -0,0 +1,142 @@
+CREATE TABLE tenants (
+    tenant_id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Everything stored before tenants existed belongs to the default tenant.
+INSERT INTO tenants (tenant_id, name) VALUES ('default', 'Default');
+
+ALTER TABLE teams ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' REFERENCES tenants(tenant_id);
+ALTER TABLE users ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE pull_requests ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE pr_reviewers ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE team_reviewer_exclusions ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE author_never_assign ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE pr_events ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE team_rotation ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE user_activity_log ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE team_settings ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE reminder_preferences ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE reminder_log ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE team_message_templates ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE notification_cursors ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' REFERENCES tenants(tenant_id) ON DELETE CASCADE;
+ALTER TABLE api_keys ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default' REFERENCES tenants(tenant_id) ON DELETE CASCADE;
+ALTER TABLE user_roles ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE audit_log ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- Identifiers are unique within a tenant only, so every key and reference gains the
+-- tenant. Foreign keys go first: they depend on the primary keys being replaced.
+ALTER TABLE users DROP CONSTRAINT users_team_name_fkey;
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_author_id_fkey;
+ALTER TABLE pr_reviewers DROP CONSTRAINT pr_reviewers_pr_id_fkey;
+ALTER TABLE pr_reviewers DROP CONSTRAINT pr_reviewers_user_id_fkey;
+ALTER TABLE team_reviewer_exclusions DROP CONSTRAINT team_reviewer_exclusions_team_name_fkey;
+ALTER TABLE team_reviewer_exclusions DROP CONSTRAINT team_reviewer_exclusions_user_a_fkey;
+ALTER TABLE team_reviewer_exclusions DROP CONSTRAINT team_reviewer_exclusions_user_b_fkey;
+ALTER TABLE author_never_assign DROP CONSTRAINT author_never_assign_author_id_fkey;
+ALTER TABLE author_never_assign DROP CONSTRAINT author_never_assign_reviewer_id_fkey;
+ALTER TABLE pr_events DROP CONSTRAINT pr_events_pr_id_fkey;
+ALTER TABLE team_rotation DROP CONSTRAINT team_rotation_team_name_fkey;
+ALTER TABLE user_activity_log DROP CONSTRAINT user_activity_log_user_id_fkey;
+ALTER TABLE team_settings DROP CONSTRAINT team_settings_team_name_fkey;
+ALTER TABLE reminder_preferences DROP CONSTRAINT reminder_preferences_user_id_fkey;
+ALTER TABLE reminder_log DROP CONSTRAINT reminder_log_user_id_fkey;
+ALTER TABLE reminder_log DROP CONSTRAINT reminder_log_pr_id_fkey;
+ALTER TABLE team_message_templates DROP CONSTRAINT team_message_templates_team_name_fkey;
+ALTER TABLE user_roles DROP CONSTRAINT user_roles_user_id_fkey;
+
+ALTER TABLE teams DROP CONSTRAINT teams_pkey, ADD PRIMARY KEY (tenant_id, team_name);
+ALTER TABLE users DROP CONSTRAINT users_pkey, ADD PRIMARY KEY (tenant_id, user_id);
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_pkey, ADD PRIMARY KEY (tenant_id, pull_request_id);
+ALTER TABLE pr_reviewers DROP CONSTRAINT pr_reviewers_pkey, ADD PRIMARY KEY (tenant_id, pr_id, user_id);
+ALTER TABLE team_reviewer_exclusions DROP CONSTRAINT team_reviewer_exclusions_pkey, ADD PRIMARY KEY (tenant_id, team_name, user_a, user_b);
+ALTER TABLE author_never_assign DROP CONSTRAINT author_never_assign_pkey, ADD PRIMARY KEY (tenant_id, author_id, reviewer_id);
+ALTER TABLE team_rotation DROP CONSTRAINT team_rotation_pkey, ADD PRIMARY KEY (tenant_id, team_name);
+ALTER TABLE team_settings DROP CONSTRAINT team_settings_pkey, ADD PRIMARY KEY (tenant_id, team_name);
+ALTER TABLE reminder_preferences DROP CONSTRAINT reminder_preferences_pkey, ADD PRIMARY KEY (tenant_id, user_id);
+ALTER TABLE reminder_log DROP CONSTRAINT reminder_log_pkey, ADD PRIMARY KEY (tenant_id, user_id, pr_id);
+ALTER TABLE team_message_templates DROP CONSTRAINT team_message_templates_pkey, ADD PRIMARY KEY (tenant_id, team_name, kind);
+ALTER TABLE notification_cursors DROP CONSTRAINT notification_cursors_pkey, ADD PRIMARY KEY (tenant_id, name);
+ALTER TABLE user_roles DROP CONSTRAINT user_roles_pkey, ADD PRIMARY KEY (tenant_id, user_id, team_name);
+
+ALTER TABLE users ADD FOREIGN KEY (tenant_id, team_name) REFERENCES teams(tenant_id, team_name) ON DELETE CASCADE;
+ALTER TABLE pull_requests ADD FOREIGN KEY (tenant_id, author_id) REFERENCES users(tenant_id, user_id);
+ALTER TABLE pr_reviewers ADD FOREIGN KEY (tenant_id, pr_id) REFERENCES pull_requests(tenant_id, pull_request_id) ON DELETE CASCADE;
+ALTER TABLE pr_reviewers ADD FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, user_id) ON DELETE CASCADE;
+ALTER TABLE team_reviewer_exclusions ADD FOREIGN KEY (tenant_id, team_name) REFERENCES teams(tenant_id, team_name) ON DELETE CASCADE;
+ALTER TABLE team_reviewer_exclusions ADD FOREIGN KEY (tenant_id, user_a) REFERENCES users(tenant_id, user_id) ON DELETE CASCADE;
+ALTER TABLE team_reviewer_exclusions ADD FOREIGN KEY (tenant_id, user_b) REFERENCES users(tenant_id, user_id) ON DELETE CASCADE;
+ALTER TABLE author_never_assign ADD FOREIGN KEY (tenant_id, author_id) REFERENCES users(tenant_id, user_id) ON DELETE CASCADE;
+ALTER TABLE author_never_assign ADD FOREIGN KEY (tenant_id, reviewer_id) REFERENCES users(tenant_id, user_id) ON DELETE CASCADE;
+ALTER TABLE pr_events ADD FOREIGN KEY (tenant_id, pr_id) REFERENCES pull_requests(tenant_id, pull_request_id) ON DELETE CASCADE;
+ALTER TABLE team_rotation ADD FOREIGN KEY (tenant_id, team_name) REFERENCES teams(tenant_id, team_name) ON DELETE CASCADE;
+ALTER TABLE user_activity_log ADD FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, user_id) ON DELETE CASCADE;
+ALTER TABLE team_settings ADD FOREIGN KEY (tenant_id, team_name) REFERENCES teams(tenant_id, team_name) ON DELETE CASCADE;
+ALTER TABLE reminder_preferences ADD FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, user_id) ON DELETE CASCADE;
+ALTER TABLE reminder_log ADD FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, user_id) ON DELETE CASCADE;
+ALTER TABLE reminder_log ADD FOREIGN KEY (tenant_id, pr_id) REFERENCES pull_requests(tenant_id, pull_request_id) ON DELETE CASCADE;
+ALTER TABLE team_message_templates ADD FOREIGN KEY (tenant_id, team_name) REFERENCES teams(tenant_id, team_name) ON DELETE CASCADE;
+ALTER TABLE user_roles ADD FOREIGN KEY (tenant_id, user_id) REFERENCES users(tenant_id, user_id) ON DELETE CASCADE;
+
+DROP INDEX idx_users_team;
+CREATE INDEX idx_users_team ON users(tenant_id, team_name);
+DROP INDEX idx_pr_author;
+CREATE INDEX idx_pr_author ON pull_requests(tenant_id, author_id);
+DROP INDEX idx_pr_reviewers_user;
+CREATE INDEX idx_pr_reviewers_user ON pr_reviewers(tenant_id, user_id);
+DROP INDEX idx_pr_events_pr;
+CREATE INDEX idx_pr_events_pr ON pr_events(tenant_id, pr_id, event_id);
+DROP INDEX idx_user_activity_user;
+CREATE INDEX idx_user_activity_user ON user_activity_log(tenant_id, user_id, changed_at);
+DROP INDEX idx_user_roles_team;
+CREATE INDEX idx_user_roles_team ON user_roles(tenant_id, team_name);
+DROP INDEX idx_audit_log_actor;
+CREATE INDEX idx_audit_log_actor ON audit_log(tenant_id, actor, entry_id);
+DROP INDEX idx_audit_log_target;
+CREATE INDEX idx_audit_log_target ON audit_log(tenant_id, target_type, target_id, entry_id);
+CREATE INDEX idx_api_keys_tenant ON api_keys(tenant_id);
+
+-- New rows must name their tenant; the default only covered existing data.
+ALTER TABLE teams ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE pull_requests ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE pr_reviewers ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE team_reviewer_exclusions ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE author_never_assign ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE pr_events ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE team_rotation ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE user_activity_log ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE team_settings ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE reminder_preferences ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE reminder_log ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE team_message_templates ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE notification_cursors ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE user_roles ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE audit_log ALTER COLUMN tenant_id DROP DEFAULT;
+
+CREATE OR REPLACE FUNCTION log_user_activity() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO user_activity_log (tenant_id, user_id, is_active, changed_at)
+        VALUES (NEW.tenant_id, NEW.user_id, NEW.is_active, NEW.created_at);
+    ELSIF NEW.is_active IS DISTINCT FROM OLD.is_active THEN
+        INSERT INTO user_activity_log (tenant_id, user_id, is_active, changed_at)
+        VALUES (NEW.tenant_id, NEW.user_id, NEW.is_active, NOW());
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP VIEW reviewer_assignments;
+
+CREATE VIEW reviewer_assignments AS
+SELECT event_id, tenant_id, pr_id, user_id AS reviewer_id, created_at AS assigned_at
+FROM pr_events
+WHERE event_type = 'REVIEWER_ASSIGNED'
+UNION ALL
+SELECT event_id, tenant_id, pr_id, replaced_by AS reviewer_id, created_at AS assigned_at
+FROM pr_events
+WHERE event_type IN ('REVIEWER_REASSIGNED', 'REVIEWER_DECLINED')
+  AND replaced_by IS NOT NULL;
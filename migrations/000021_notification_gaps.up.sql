@@ -0,0 +1,7 @@
+CREATE TABLE notification_gaps (
+    tenant_id VARCHAR(64) NOT NULL REFERENCES tenants(tenant_id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    event_id BIGINT NOT NULL,
+    skipped_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, name, event_id)
+);
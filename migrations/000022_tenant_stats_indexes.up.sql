@@ -0,0 +1,4 @@
+DROP INDEX idx_pr_reviewers_assigned_at;
+CREATE INDEX idx_pr_reviewers_assigned_at ON pr_reviewers(tenant_id, assigned_at);
+DROP INDEX idx_pr_events_type_created;
+CREATE INDEX idx_pr_events_type_created ON pr_events(tenant_id, event_type, created_at);
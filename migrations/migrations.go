@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations of the service, so that the binary can
+// apply the schema it was built for.
+package migrations
+
+import "embed"
+
+// FS holds the migrations as <version>_<name>.up.sql and <version>_<name>.down.sql.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -52,6 +52,7 @@ type PRResponse struct {
 		AssignedReviewers []string   `json:"assigned_reviewers"`
 		CreatedAt         time.Time  `json:"createdAt"`
 		MergedAt          *time.Time `json:"mergedAt,omitempty"`
+		Version           int64      `json:"version"`
 	} `json:"pr"`
 }
 
@@ -0,0 +1,401 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// parallelism is how many identical requests each test fires at once.
+const parallelism = 20
+
+type ErrorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type TimelineResponse struct {
+	PullRequestID string `json:"pull_request_id"`
+	Events        []struct {
+		Type       string `json:"type"`
+		UserID     string `json:"user_id"`
+		ReplacedBy string `json:"replaced_by"`
+	} `json:"events"`
+}
+
+type ReassignRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldUserID     string `json:"old_user_id"`
+}
+
+type ManualReviewerRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+	ActorID       string `json:"actor_id"`
+}
+
+type result struct {
+	status int
+	header http.Header
+	body   []byte
+	err    error
+}
+
+func (r result) code() string {
+	var resp ErrorResponse
+	json.Unmarshal(r.body, &resp)
+	return resp.Error.Code
+}
+
+// doRequest is makeRequest for goroutines other than the test's own, which must not
+// call t.Fatal.
+func doRequest(method, path string, body interface{}) result {
+	return doRequestWithHeader(method, path, body, nil)
+}
+
+func doRequestWithHeader(method, path string, body interface{}, header http.Header) result {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return result{err: err}
+	}
+
+	req, err := http.NewRequest(method, baseURL+path, bytes.NewReader(reqBody))
+	if err != nil {
+		return result{err: err}
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return result{err: err}
+	}
+	defer resp.Body.Close()
+
+	respBody := new(bytes.Buffer)
+	if _, err := respBody.ReadFrom(resp.Body); err != nil {
+		return result{err: err}
+	}
+
+	return result{status: resp.StatusCode, header: resp.Header, body: respBody.Bytes()}
+}
+
+// hammer sends n requests built by req at the same time and returns the results by
+// status code.
+func hammer(t *testing.T, n int, req func(i int) result) map[int][]result {
+	t.Helper()
+
+	results := make([]result, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			results[i] = req(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	byStatus := make(map[int][]result)
+	for _, r := range results {
+		if r.err != nil {
+			t.Fatalf("request failed: %v", r.err)
+		}
+		byStatus[r.status] = append(byStatus[r.status], r)
+	}
+	return byStatus
+}
+
+// expectOnly fails unless every result has the given status and, when code is set,
+// error code.
+func expectOnly(t *testing.T, results []result, status int, code string) {
+	t.Helper()
+	for _, r := range results {
+		if r.status != status || (code != "" && r.code() != code) {
+			t.Errorf("expected %d %s, got %d: %s", status, code, r.status, string(r.body))
+		}
+	}
+}
+
+func createTeam(t *testing.T, members []TeamMember) TeamResponse {
+	t.Helper()
+
+	resp, body := makeRequest(t, "POST", "/team/add", CreateTeamRequest{
+		TeamName: generateID("team"),
+		Members:  members,
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("setup failed: %s", string(body))
+	}
+
+	var team TeamResponse
+	if err := json.Unmarshal(body, &team); err != nil {
+		t.Fatalf("failed to parse team response: %v", err)
+	}
+	return team
+}
+
+func newMembers(n int, active bool) []TeamMember {
+	members := make([]TeamMember, n)
+	for i := range members {
+		members[i] = TeamMember{UserID: generateID(fmt.Sprintf("user%d", i)), Username: fmt.Sprintf("User %d", i), IsActive: active}
+	}
+	return members
+}
+
+func createPR(t *testing.T, authorID string) PRResponse {
+	t.Helper()
+
+	resp, body := makeRequest(t, "POST", "/pullRequest/create", CreatePRRequest{
+		PullRequestID:   generateID("pr"),
+		PullRequestName: "Concurrency",
+		AuthorID:        authorID,
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("setup failed: %s", string(body))
+	}
+
+	var pr PRResponse
+	if err := json.Unmarshal(body, &pr); err != nil {
+		t.Fatalf("failed to parse PR response: %v", err)
+	}
+	return pr
+}
+
+func getPR(t *testing.T, prID string) (reviewers []string, events map[string]int) {
+	t.Helper()
+
+	resp, body := makeRequest(t, "GET", "/pullRequest/timeline?pull_request_id="+prID, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("timeline failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var timeline TimelineResponse
+	if err := json.Unmarshal(body, &timeline); err != nil {
+		t.Fatalf("failed to parse timeline: %v", err)
+	}
+
+	events = make(map[string]int)
+	assigned := make(map[string]bool)
+	var order []string
+	for _, e := range timeline.Events {
+		events[e.Type]++
+		switch e.Type {
+		case "REVIEWER_ASSIGNED":
+			assigned[e.UserID] = true
+			order = append(order, e.UserID)
+		case "REVIEWER_REASSIGNED", "REVIEWER_DECLINED":
+			delete(assigned, e.UserID)
+			if e.ReplacedBy != "" {
+				assigned[e.ReplacedBy] = true
+				order = append(order, e.ReplacedBy)
+			}
+		case "REVIEWER_REMOVED":
+			delete(assigned, e.UserID)
+		}
+	}
+	for _, id := range order {
+		if assigned[id] {
+			reviewers = append(reviewers, id)
+			delete(assigned, id)
+		}
+	}
+	return reviewers, events
+}
+
+func TestConcurrentCreateTeam(t *testing.T) {
+	teamName := generateID("team")
+	members := newMembers(3, true)
+
+	results := hammer(t, parallelism, func(int) result {
+		return doRequest("POST", "/team/add", CreateTeamRequest{TeamName: teamName, Members: members})
+	})
+
+	if len(results[http.StatusCreated]) != 1 {
+		t.Errorf("expected exactly one team to be created, got %d", len(results[http.StatusCreated]))
+	}
+	delete(results, http.StatusCreated)
+	for _, rs := range results {
+		expectOnly(t, rs, http.StatusBadRequest, "TEAM_EXISTS")
+	}
+}
+
+func TestConcurrentCreatePR(t *testing.T) {
+	team := createTeam(t, newMembers(4, true))
+	authorID := team.Team.Members[0].UserID
+	prID := generateID("pr")
+
+	results := hammer(t, parallelism, func(int) result {
+		return doRequest("POST", "/pullRequest/create", CreatePRRequest{
+			PullRequestID:   prID,
+			PullRequestName: "Concurrency",
+			AuthorID:        authorID,
+		})
+	})
+
+	if len(results[http.StatusCreated]) != 1 {
+		t.Errorf("expected exactly one PR to be created, got %d", len(results[http.StatusCreated]))
+	}
+	delete(results, http.StatusCreated)
+	for _, rs := range results {
+		expectOnly(t, rs, http.StatusConflict, "PR_EXISTS")
+	}
+
+	reviewers, events := getPR(t, prID)
+	if events["CREATED"] != 1 {
+		t.Errorf("expected one CREATED event, got %d", events["CREATED"])
+	}
+	if len(reviewers) > 2 || events["REVIEWER_ASSIGNED"] != len(reviewers) {
+		t.Errorf("expected at most 2 assignments, got reviewers %v and %d events", reviewers, events["REVIEWER_ASSIGNED"])
+	}
+}
+
+func TestConcurrentMergePR(t *testing.T) {
+	team := createTeam(t, newMembers(3, true))
+	pr := createPR(t, team.Team.Members[0].UserID)
+
+	results := hammer(t, parallelism, func(int) result {
+		return doRequest("POST", "/pullRequest/merge", MergePRRequest{PullRequestID: pr.PR.PullRequestID})
+	})
+
+	if len(results[http.StatusOK]) != parallelism {
+		t.Errorf("expected every merge to succeed, got %v", results)
+	}
+
+	var mergedAt *time.Time
+	for _, r := range results[http.StatusOK] {
+		var resp PRResponse
+		json.Unmarshal(r.body, &resp)
+		if resp.PR.MergedAt == nil {
+			t.Fatal("merged_at should be set")
+		}
+		if mergedAt != nil && !resp.PR.MergedAt.Equal(*mergedAt) {
+			t.Errorf("merged_at differs between responses: %v and %v", *mergedAt, *resp.PR.MergedAt)
+		}
+		mergedAt = resp.PR.MergedAt
+	}
+
+	if _, events := getPR(t, pr.PR.PullRequestID); events["MERGED"] != 1 {
+		t.Errorf("expected one MERGED event, got %d", events["MERGED"])
+	}
+}
+
+func TestConcurrentReassignReviewer(t *testing.T) {
+	team := createTeam(t, newMembers(8, true))
+	pr := createPR(t, team.Team.Members[0].UserID)
+	if len(pr.PR.AssignedReviewers) == 0 {
+		t.Fatal("expected reviewers to be assigned")
+	}
+	oldReviewer := pr.PR.AssignedReviewers[0]
+
+	results := hammer(t, parallelism, func(int) result {
+		return doRequest("POST", "/pullRequest/reassign", ReassignRequest{
+			PullRequestID: pr.PR.PullRequestID,
+			OldUserID:     oldReviewer,
+		})
+	})
+
+	if len(results[http.StatusOK]) != 1 {
+		t.Errorf("expected exactly one reassignment, got %d", len(results[http.StatusOK]))
+	}
+	delete(results, http.StatusOK)
+	for _, rs := range results {
+		expectOnly(t, rs, http.StatusConflict, "NOT_ASSIGNED")
+	}
+
+	reviewers, events := getPR(t, pr.PR.PullRequestID)
+	if events["REVIEWER_REASSIGNED"] != 1 {
+		t.Errorf("expected one REVIEWER_REASSIGNED event, got %d", events["REVIEWER_REASSIGNED"])
+	}
+	if len(reviewers) != len(pr.PR.AssignedReviewers) {
+		t.Errorf("expected %d reviewers, got %v", len(pr.PR.AssignedReviewers), reviewers)
+	}
+	for _, id := range reviewers {
+		if id == oldReviewer || id == pr.PR.AuthorID {
+			t.Errorf("unexpected reviewer %s in %v", id, reviewers)
+		}
+	}
+}
+
+func TestConcurrentAddReviewer(t *testing.T) {
+	// Candidates start inactive so that the PR is created without reviewers.
+	members := append(newMembers(1, true), newMembers(6, false)...)
+	team := createTeam(t, members)
+	pr := createPR(t, team.Team.Members[0].UserID)
+	if len(pr.PR.AssignedReviewers) != 0 {
+		t.Fatalf("expected no reviewers, got %v", pr.PR.AssignedReviewers)
+	}
+
+	candidates := members[1:]
+	for _, m := range candidates {
+		resp, body := makeRequest(t, "POST", "/users/setIsActive", SetIsActiveRequest{UserID: m.UserID, IsActive: true})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("setup failed: %s", string(body))
+		}
+	}
+
+	results := hammer(t, len(candidates), func(i int) result {
+		return doRequest("POST", "/pullRequest/reviewers/add", ManualReviewerRequest{
+			PullRequestID: pr.PR.PullRequestID,
+			UserID:        candidates[i].UserID,
+			ActorID:       pr.PR.AuthorID,
+		})
+	})
+
+	if len(results[http.StatusOK]) != 2 {
+		t.Errorf("expected exactly 2 reviewers to be added, got %d", len(results[http.StatusOK]))
+	}
+	delete(results, http.StatusOK)
+	for _, rs := range results {
+		expectOnly(t, rs, http.StatusConflict, "TOO_MANY_REVIEWERS")
+	}
+
+	if reviewers, _ := getPR(t, pr.PR.PullRequestID); len(reviewers) != 2 {
+		t.Errorf("expected 2 reviewers, got %v", reviewers)
+	}
+}
+
+func TestConcurrentReassignReviewerWithIfMatch(t *testing.T) {
+	team := createTeam(t, newMembers(8, true))
+	pr := createPR(t, team.Team.Members[0].UserID)
+	if len(pr.PR.AssignedReviewers) == 0 {
+		t.Fatal("expected reviewers to be assigned")
+	}
+	etag := fmt.Sprintf("%q", fmt.Sprint(pr.PR.Version))
+
+	// Every caller saw the same version, so only the first change may go through.
+	results := hammer(t, parallelism, func(int) result {
+		return doRequestWithHeader("POST", "/pullRequest/reassign", ReassignRequest{
+			PullRequestID: pr.PR.PullRequestID,
+			OldUserID:     pr.PR.AssignedReviewers[0],
+		}, http.Header{"If-Match": {etag}})
+	})
+
+	if len(results[http.StatusOK]) != 1 {
+		t.Fatalf("expected exactly one reassignment, got %d", len(results[http.StatusOK]))
+	}
+	delete(results, http.StatusOK)
+	for _, rs := range results {
+		expectOnly(t, rs, http.StatusPreconditionFailed, "VERSION_MISMATCH")
+	}
+
+	resp, body := makeRequest(t, "GET", "/pullRequest/timeline?pull_request_id="+pr.PR.PullRequestID, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("timeline failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	if got, want := resp.Header.Get("ETag"), fmt.Sprintf("%q", fmt.Sprint(pr.PR.Version+1)); got != want {
+		t.Errorf("expected ETag %s after one change, got %s", want, got)
+	}
+}